@@ -1,3 +1,8 @@
 module github.com/devaloi/htmxapp
 
 go 1.26
+
+require (
+	golang.org/x/net v0.57.0
+	golang.org/x/text v0.40.0
+)
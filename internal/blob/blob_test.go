@@ -0,0 +1,69 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFS(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFS(filepath.Join(dir, "blobs"))
+	if err != nil {
+		t.Fatalf("NewFS: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "photos/ab12/thumb.jpg", strings.NewReader("first")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Put(ctx, "photos/ab12/thumb.jpg", strings.NewReader("second")); err != nil {
+		t.Fatalf("Put replacing: %v", err)
+	}
+
+	rc, err := s.Open(ctx, "photos/ab12/thumb.jpg")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "second" {
+		t.Errorf("read %q, want %q", data, "second")
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(dir, "blobs", "photos", "ab12"))
+	if len(entries) != 1 {
+		t.Errorf("expected only the object in its directory, got %d entries", len(entries))
+	}
+
+	if err := s.Delete(ctx, "photos/ab12/thumb.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.Delete(ctx, "photos/ab12/thumb.jpg"); err != nil {
+		t.Errorf("deleting a missing object: %v", err)
+	}
+	if _, err := s.Open(ctx, "photos/ab12/thumb.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after Delete: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestFS_InvalidKeys(t *testing.T) {
+	s, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, key := range []string{"", "../escape", "photos/../../x", "/abs", "photos//x", "photos/.hidden", "Upper.jpg", `photos\x`} {
+		if err := s.Put(ctx, key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q): got %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := s.Open(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Open(%q): got %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package chart lays out bar charts for rendering as SVG, so pages can
+// show charts without a client-side charting library.
+package chart
+
+import "math"
+
+// Layout margins, in SVG user units.
+const (
+	// labelSpace is the room under columns for their labels; rows are
+	// given a label width instead.
+	labelSpace = 20
+
+	// valueSpace is the room above columns for their values, and half
+	// the room after rows for theirs.
+	valueSpace = 16
+)
+
+// Point is a labelled value to chart.
+type Point struct {
+	Label string
+	Value int
+}
+
+// Bar is a laid-out Point: the rectangle drawn for it, and where its label
+// and value are written.
+type Bar struct {
+	Point
+	X, Y, Width, Height float64
+	LabelX, LabelY      float64
+	ValueX, ValueY      float64
+}
+
+// Chart is a bar chart laid out in a Width by Height viewBox. Max is the
+// largest value, which gets the longest bar.
+type Chart struct {
+	Width, Height float64
+	Bars          []Bar
+	Max           int
+}
+
+// Columns lays out points as vertical bars side by side, labelled
+// underneath, in a width by height viewBox.
+func Columns(points []Point, width, height float64) *Chart {
+	c := &Chart{Width: width, Height: height, Max: maxValue(points)}
+	if len(points) == 0 {
+		return c
+	}
+	slot := width / float64(len(points))
+	base := height - labelSpace
+	for i, p := range points {
+		h := (base - valueSpace) * float64(p.Value) / float64(max(c.Max, 1))
+		x := slot * float64(i)
+		c.Bars = append(c.Bars, Bar{
+			Point:  p,
+			X:      round(x + slot*0.15),
+			Y:      round(base - h),
+			Width:  round(slot * 0.7),
+			Height: round(h),
+			LabelX: round(x + slot/2),
+			LabelY: height - 6,
+			ValueX: round(x + slot/2),
+			ValueY: round(base - h - 4),
+		})
+	}
+	return c
+}
+
+// Rows lays out points as horizontal bars one under the other, rowHeight
+// apart and labelWidth from the left where their labels end, in a viewBox
+// width wide.
+func Rows(points []Point, width, rowHeight, labelWidth float64) *Chart {
+	c := &Chart{Width: width, Height: rowHeight * float64(len(points)), Max: maxValue(points)}
+	span := width - labelWidth - 2*valueSpace
+	for i, p := range points {
+		y := rowHeight * float64(i)
+		w := span * float64(p.Value) / float64(max(c.Max, 1))
+		c.Bars = append(c.Bars, Bar{
+			Point:  p,
+			X:      labelWidth,
+			Y:      round(y + rowHeight*0.2),
+			Width:  round(w),
+			Height: round(rowHeight * 0.6),
+			LabelX: labelWidth - 6,
+			LabelY: round(y + rowHeight/2),
+			ValueX: round(labelWidth + w + 4),
+			ValueY: round(y + rowHeight/2),
+		})
+	}
+	return c
+}
+
+func maxValue(points []Point) int {
+	m := 0
+	for _, p := range points {
+		m = max(m, p.Value)
+	}
+	return m
+}
+
+// round keeps coordinates to one decimal place, which is plenty for SVG
+// and keeps the markup short.
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,48 @@
+package chart
+
+import "testing"
+
+func TestColumns(t *testing.T) {
+	c := Columns([]Point{{"a", 2}, {"b", 0}, {"c", 4}}, 300, 120)
+	if c.Max != 4 || len(c.Bars) != 3 {
+		t.Fatalf("unexpected chart %+v", c)
+	}
+	base := c.Height - labelSpace
+	tallest := c.Bars[2]
+	if tallest.Y != valueSpace || tallest.Y+tallest.Height != base {
+		t.Errorf("expected the largest value to fill the plot, got %+v", tallest)
+	}
+	if half := c.Bars[0]; half.Height != tallest.Height/2 || half.Y+half.Height != base {
+		t.Errorf("expected half a bar on the baseline, got %+v", half)
+	}
+	if c.Bars[1].Height != 0 {
+		t.Errorf("expected no bar for zero, got %+v", c.Bars[1])
+	}
+	for i, b := range c.Bars {
+		if b.X < float64(i)*100 || b.X+b.Width > float64(i+1)*100 || b.LabelX != float64(i)*100+50 {
+			t.Errorf("bar %d outside its slot: %+v", i, b)
+		}
+	}
+}
+
+func TestRows(t *testing.T) {
+	c := Rows([]Point{{"a", 3}, {"b", 1}}, 400, 30, 120)
+	if c.Height != 60 || len(c.Bars) != 2 {
+		t.Fatalf("unexpected chart %+v", c)
+	}
+	if b := c.Bars[0]; b.X != 120 || b.X+b.Width != 400-2*valueSpace || b.LabelX >= b.X || b.ValueX <= b.X+b.Width {
+		t.Errorf("unexpected longest bar %+v", b)
+	}
+	if b := c.Bars[1]; b.Y < 30 || b.Y+b.Height > 60 || b.Width != 82.7 {
+		t.Errorf("unexpected second bar %+v", b)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	if c := Columns(nil, 100, 100); len(c.Bars) != 0 {
+		t.Error("expected no bars")
+	}
+	if c := Rows([]Point{{"a", 0}}, 100, 20, 40); c.Bars[0].Width != 0 {
+		t.Error("expected an empty bar for zero")
+	}
+}
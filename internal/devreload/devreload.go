@@ -0,0 +1,139 @@
+// Package devreload supports development mode: it watches source
+// directories for changes and tells open browsers to reload over
+// server-sent events.
+package devreload
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Broker fans reload events out to every connected browser.
+type Broker struct {
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+	closed  chan struct{}
+	once    sync.Once
+}
+
+// NewBroker creates a Broker with no connected clients.
+func NewBroker() *Broker {
+	return &Broker{
+		clients: make(map[chan struct{}]struct{}),
+		closed:  make(chan struct{}),
+	}
+}
+
+// Notify sends a reload event to all connected browsers.
+func (b *Broker) Notify() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.clients {
+		select {
+		case ch <- struct{}{}:
+		default: // a reload is already pending for this client
+		}
+	}
+}
+
+// Close ends every open event stream so the server can shut down without
+// waiting for browsers to disconnect.
+func (b *Broker) Close() {
+	b.once.Do(func() { close(b.closed) })
+}
+
+// ServeHTTP streams reload events to a single browser.
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// The stream outlives the server's write timeout by design.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		slog.Error("live reload stream", "error", err)
+		return
+	}
+
+	ch := make(chan struct{}, 1)
+	b.mu.Lock()
+	b.clients[ch] = struct{}{}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.clients, ch)
+		b.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ch:
+			if _, err := fmt.Fprint(w, "event: reload\ndata: {}\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-b.closed:
+			return
+		}
+	}
+}
+
+// Watch polls dirs every interval and calls onChange whenever a file under
+// any of them is added, removed or modified. It returns when ctx is done.
+// Polling keeps the module free of platform-specific file notification
+// dependencies, and is plenty fast for a handful of templates.
+func Watch(ctx context.Context, interval time.Duration, dirs []string, onChange func()) {
+	last := snapshot(dirs)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := snapshot(dirs)
+			if current != last {
+				last = current
+				onChange()
+			}
+		}
+	}
+}
+
+type fingerprint struct {
+	files   int
+	size    int64
+	modTime int64
+}
+
+func snapshot(dirs []string) fingerprint {
+	var fp fingerprint
+	for _, dir := range dirs {
+		_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			fp.files++
+			fp.size += info.Size()
+			fp.modTime = max(fp.modTime, info.ModTime().UnixNano())
+			return nil
+		})
+	}
+	return fp
+}
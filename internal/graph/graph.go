@@ -0,0 +1,162 @@
+// Package graph builds and lays out a contact's relationship neighborhood
+// for rendering as SVG.
+package graph
+
+import (
+	"context"
+	"math"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// NodeRadius is the radius of the circle drawn for each contact.
+const NodeRadius = 22
+
+// Node is a contact in the graph, Hop relationships away from the center.
+type Node struct {
+	ID    string
+	Label string
+	Hop   int
+	X, Y  float64
+
+	parent int // index of the node it was reached from
+}
+
+// Edge is a relationship drawn from the From node to the To node, trimmed
+// to the node circles.
+type Edge struct {
+	From, To       string
+	Type           string
+	Bidirectional  bool
+	X1, Y1, X2, Y2 float64
+}
+
+// Graph is a laid-out neighborhood. Truncated is set when the node limit
+// cut the neighborhood short.
+type Graph struct {
+	Center    string
+	Hops      int
+	Nodes     []Node
+	Edges     []Edge
+	Size      float64
+	Truncated bool
+}
+
+// Neighbors returns the relationships visible from a contact.
+type Neighbors func(ctx context.Context, contactID string) ([]model.Relationship, error)
+
+// Neighborhood collects the contacts up to hops relationships away from
+// center, breadth first, stopping at maxNodes contacts. Edges are included
+// when both ends are in the graph.
+func Neighborhood(ctx context.Context, center string, hops, maxNodes int, neighbors Neighbors) (*Graph, error) {
+	g := &Graph{Center: center, Hops: hops, Nodes: []Node{{ID: center}}}
+	index := map[string]int{center: 0}
+	seen := make(map[string]bool)
+	var rels []model.Relationship
+
+	frontier := []string{center}
+	for hop := 1; hop <= hops && len(frontier) > 0; hop++ {
+		var next []string
+		for _, id := range frontier {
+			visible, err := neighbors(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range visible {
+				if !seen[r.ID] {
+					seen[r.ID] = true
+					rels = append(rels, r)
+				}
+				other, _, _ := r.From(id)
+				if _, ok := index[other]; ok {
+					continue
+				}
+				if len(g.Nodes) >= maxNodes {
+					g.Truncated = true
+					continue
+				}
+				index[other] = len(g.Nodes)
+				g.Nodes = append(g.Nodes, Node{ID: other, Hop: hop, parent: index[id]})
+				next = append(next, other)
+			}
+		}
+		frontier = next
+	}
+
+	for _, r := range rels {
+		if _, ok := index[r.FromID]; !ok {
+			continue
+		}
+		if _, ok := index[r.ToID]; !ok {
+			continue
+		}
+		g.Edges = append(g.Edges, Edge{From: r.FromID, To: r.ToID, Type: r.Type, Bidirectional: r.Bidirectional})
+	}
+	return g, nil
+}
+
+// Layout places the center in the middle of a size×size square and each
+// hop on its own ring around it, then positions the edges. Every node gets
+// a slice of the angle its parent was given, shared equally with its
+// siblings, so contacts stay next to the contact they were reached from.
+func (g *Graph) Layout(size float64) {
+	g.Size = size
+	mid := size / 2
+	rings := 0
+	children := make(map[int][]int)
+	for i, n := range g.Nodes {
+		if i > 0 {
+			children[n.parent] = append(children[n.parent], i)
+		}
+		rings = max(rings, n.Hop)
+	}
+
+	step := 0.0
+	if rings > 0 {
+		step = (mid - 2*NodeRadius - 12) / float64(rings)
+	}
+	var place func(i int, from, to float64)
+	place = func(i int, from, to float64) {
+		n := &g.Nodes[i]
+		angle := (from + to) / 2
+		radius := step * float64(n.Hop)
+		n.X = round(mid + radius*math.Cos(angle))
+		n.Y = round(mid + radius*math.Sin(angle))
+		kids := children[i]
+		for k, c := range kids {
+			width := (to - from) / float64(len(kids))
+			place(c, from+width*float64(k), from+width*float64(k+1))
+		}
+	}
+	// Start at the top; the center's own angle is irrelevant.
+	place(0, -math.Pi/2-math.Pi, -math.Pi/2+math.Pi)
+
+	pos := make(map[string]Node, len(g.Nodes))
+	for _, n := range g.Nodes {
+		pos[n.ID] = n
+	}
+	for i, e := range g.Edges {
+		a, b := pos[e.From], pos[e.To]
+		dx, dy := b.X-a.X, b.Y-a.Y
+		d := math.Hypot(dx, dy)
+		if d == 0 {
+			continue
+		}
+		ux, uy := dx/d*NodeRadius, dy/d*NodeRadius
+		g.Edges[i].X1, g.Edges[i].Y1 = round(a.X+ux), round(a.Y+uy)
+		g.Edges[i].X2, g.Edges[i].Y2 = round(b.X-ux), round(b.Y-uy)
+	}
+}
+
+func round(f float64) float64 {
+	return math.Round(f*10) / 10
+}
+
+// LabelX and LabelY place the relationship's label halfway along the edge.
+func (e Edge) LabelX() float64 { return round((e.X1 + e.X2) / 2) }
+
+// LabelY is the vertical counterpart of LabelX.
+func (e Edge) LabelY() float64 { return round((e.Y1 + e.Y2) / 2) }
+
+// LabelY is where the contact's name is drawn, below its circle.
+func (n Node) LabelY() float64 { return n.Y + NodeRadius + 14 }
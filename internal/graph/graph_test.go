@@ -0,0 +1,96 @@
+package graph
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// chain links a-b-c-d bidirectionally and has e record a one-way
+// relationship to a.
+var chain = []model.Relationship{
+	{ID: "1", FromID: "a", ToID: "b", Type: "friend", Bidirectional: true},
+	{ID: "2", FromID: "b", ToID: "c", Type: "friend", Bidirectional: true},
+	{ID: "3", FromID: "c", ToID: "d", Type: "friend", Bidirectional: true},
+	{ID: "4", FromID: "e", ToID: "a", Type: "manager"},
+}
+
+func neighbors(_ context.Context, id string) ([]model.Relationship, error) {
+	var out []model.Relationship
+	for _, r := range chain {
+		if _, _, ok := r.From(id); ok {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func TestNeighborhood(t *testing.T) {
+	tests := []struct {
+		center    string
+		hops, max int
+		nodes     []string
+		edges     int
+		truncated bool
+	}{
+		{"a", 1, 10, []string{"a", "b"}, 1, false},
+		{"a", 2, 10, []string{"a", "b", "c"}, 2, false},
+		{"b", 2, 10, []string{"b", "a", "c", "d"}, 3, false},
+		{"e", 3, 10, []string{"e", "a", "b", "c"}, 3, false},
+		{"a", 3, 2, []string{"a", "b"}, 1, true},
+	}
+	for _, tt := range tests {
+		g, err := Neighborhood(context.Background(), tt.center, tt.hops, tt.max, neighbors)
+		if err != nil {
+			t.Fatalf("Neighborhood: %v", err)
+		}
+		var ids []string
+		for _, n := range g.Nodes {
+			ids = append(ids, n.ID)
+		}
+		if len(ids) != len(tt.nodes) {
+			t.Errorf("%s/%d: nodes = %v, want %v", tt.center, tt.hops, ids, tt.nodes)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tt.nodes[i] {
+				t.Errorf("%s/%d: nodes = %v, want %v", tt.center, tt.hops, ids, tt.nodes)
+				break
+			}
+		}
+		if len(g.Edges) != tt.edges || g.Truncated != tt.truncated {
+			t.Errorf("%s/%d: %d edges truncated=%v, want %d, %v", tt.center, tt.hops, len(g.Edges), g.Truncated, tt.edges, tt.truncated)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	g, _ := Neighborhood(context.Background(), "b", 2, 10, neighbors)
+	g.Layout(400)
+
+	center := g.Nodes[0]
+	if center.X != 200 || center.Y != 200 {
+		t.Errorf("center at (%v, %v), want (200, 200)", center.X, center.Y)
+	}
+	dist := func(n Node) float64 { return math.Hypot(n.X-200, n.Y-200) }
+	for _, n := range g.Nodes[1:] {
+		if n.Hop == 1 && math.Abs(dist(n)-dist(g.Nodes[1])) > 0.2 {
+			t.Errorf("hop 1 nodes should share a ring")
+		}
+		if n.X < NodeRadius || n.X > 400-NodeRadius || n.Y < NodeRadius || n.Y > 400-NodeRadius {
+			t.Errorf("node %s at (%v, %v) outside the canvas", n.ID, n.X, n.Y)
+		}
+	}
+	// d is reached through c, so it sits on c's side, further out.
+	c, d := g.Nodes[2], g.Nodes[3]
+	if dist(d) <= dist(c) || math.Abs(math.Atan2(d.Y-200, d.X-200)-math.Atan2(c.Y-200, c.X-200)) > 0.01 {
+		t.Errorf("expected d beyond c: c=(%v, %v) d=(%v, %v)", c.X, c.Y, d.X, d.Y)
+	}
+	for _, e := range g.Edges {
+		if l := math.Hypot(e.X2-e.X1, e.Y2-e.Y1); l <= 0 {
+			t.Errorf("edge %s-%s has no length", e.From, e.To)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/devaloi/htmxapp/internal/blob"
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// Default attachment limits, per file and per contact.
+const (
+	DefaultAttachmentMaxBytes = 25 << 20
+	DefaultAttachmentQuota    = 100 << 20
+)
+
+// attachmentsData is the contact page's attachments section.
+type attachmentsData struct {
+	Contact     model.Contact
+	TZ          string
+	Attachments []model.Attachment
+	Errors      map[string]string
+
+	// Used is the storage the contact's attachments take, out of Quota;
+	// MaxBytes is the largest file accepted.
+	Used     int64
+	Quota    int64
+	MaxBytes int64
+}
+
+func attachmentKey(checksum string) string {
+	return "attachments/" + checksum
+}
+
+// loadAttachments fills in the contact page's attachments section. It is
+// left empty when there is no blob store to keep files in.
+func (h *Handler) loadAttachments(ctx context.Context, data *contactData) error {
+	if h.blobs == nil {
+		return nil
+	}
+	list, err := h.store.ListAttachments(ctx, data.Contact.ID)
+	if err != nil {
+		return err
+	}
+	data.Attachments = &attachmentsData{
+		Contact:     data.Contact,
+		TZ:          data.TZ,
+		Attachments: list,
+		Errors:      make(map[string]string),
+		Used:        attachmentsSize(list),
+		Quota:       h.attachmentQuota,
+		MaxBytes:    h.attachmentMax,
+	}
+	return nil
+}
+
+func attachmentsSize(list []model.Attachment) int64 {
+	var n int64
+	for _, a := range list {
+		n += a.Size
+	}
+	return n
+}
+
+// UploadAttachment streams a file attached to the contact into the blob
+// store and returns the updated attachments section. The upload may take
+// longer than the server's read timeout.
+func (h *Handler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	if h.blobs == nil {
+		http.NotFound(w, r)
+		return
+	}
+	extendDeadlines(w)
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+	list, err := h.store.ListAttachments(r.Context(), c.ID)
+	if err != nil {
+		h.serverError(w, r, "list attachments", err)
+		return
+	}
+
+	a, code, err := h.receiveAttachment(w, r, c.ID, attachmentsSize(list))
+	if err != nil {
+		h.serverError(w, r, "store attachment", err)
+		return
+	}
+	errs := make(map[string]string)
+	status := http.StatusOK
+	if code != "" {
+		errs["File"] = code
+		status = http.StatusUnprocessableEntity
+	} else {
+		slog.Info("attachment added", "id", a.ID, "contact", c.ID, "size", a.Size, "checksum", a.Checksum)
+		if !isHTMX(r) {
+			http.Redirect(w, r, "/contacts/"+c.ID, http.StatusSeeOther)
+			return
+		}
+	}
+	h.renderAttachments(w, r, status, c, errs)
+}
+
+// receiveAttachment reads the upload's "file" part without buffering it
+// in memory. It returns a validation code for uploads that are missing,
+// too large or over the contact's quota given the used bytes.
+func (h *Handler) receiveAttachment(w http.ResponseWriter, r *http.Request, contactID string, used int64) (model.Attachment, string, error) {
+	limit := min(h.attachmentMax, h.attachmentQuota-used)
+	if limit <= 0 {
+		return model.Attachment{}, model.CodeQuotaExceeded, nil
+	}
+	overLimit := model.CodeTooLarge
+	if limit < h.attachmentMax {
+		overLimit = model.CodeQuotaExceeded
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, limit+1<<20)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return model.Attachment{}, model.CodeRequired, nil
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return model.Attachment{}, model.CodeRequired, nil
+		}
+		if err != nil {
+			return model.Attachment{}, uploadErrorCode(err, overLimit), nil
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		a, code, err := h.storeAttachment(r.Context(), contactID, part.FileName(), part, limit, overLimit)
+		part.Close()
+		return a, code, err
+	}
+}
+
+// uploadErrorCode maps an error reading the request body to a validation
+// code: overLimit if the body exceeded its limit.
+func uploadErrorCode(err error, overLimit string) string {
+	if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+		return overLimit
+	}
+	return model.CodeRequired
+}
+
+// storeAttachment copies src to a temporary file while hashing it, then
+// stores it under its checksum unless an identical file is already
+// stored, and records the attachment.
+func (h *Handler) storeAttachment(ctx context.Context, contactID, name string, src io.Reader, limit int64, overLimit string) (model.Attachment, string, error) {
+	tmp, err := os.CreateTemp("", "htmxapp-upload-*")
+	if err != nil {
+		return model.Attachment{}, "", err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	sum := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, sum), io.LimitReader(src, limit+1))
+	switch {
+	case err != nil:
+		return model.Attachment{}, uploadErrorCode(err, overLimit), nil
+	case n > limit:
+		return model.Attachment{}, overLimit, nil
+	case n == 0:
+		return model.Attachment{}, model.CodeRequired, nil
+	}
+
+	head := make([]byte, 512)
+	k, _ := tmp.ReadAt(head, 0)
+	a := model.Attachment{
+		ContactID:   contactID,
+		Name:        model.CleanFileName(name),
+		ContentType: detectContentType(name, head[:k]),
+		Size:        n,
+		Checksum:    hex.EncodeToString(sum.Sum(nil)),
+	}
+
+	// Puts are content-addressed and atomic, so the blob is written outside
+	// the lock and concurrent uploads of the same file are harmless. If a
+	// delete removes it before the attachment is recorded, it's written
+	// again.
+	key := attachmentKey(a.Checksum)
+	for {
+		added, found, err := h.recordAttachment(ctx, key, a)
+		switch {
+		case errors.Is(err, model.ErrQuotaExceeded):
+			return model.Attachment{}, model.CodeQuotaExceeded, nil
+		case err != nil:
+			return model.Attachment{}, "", err
+		case found:
+			return added, "", nil
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return model.Attachment{}, "", err
+		}
+		if err := h.blobs.Put(ctx, key, tmp); err != nil {
+			return model.Attachment{}, "", err
+		}
+	}
+}
+
+// recordAttachment records a if its blob is stored, reporting whether it
+// was. Both happen under blobMu, so a concurrent delete can't remove the
+// blob in between. The quota is checked again as the attachment is
+// recorded, since another upload for the contact may have finished
+// meanwhile.
+func (h *Handler) recordAttachment(ctx context.Context, key string, a model.Attachment) (model.Attachment, bool, error) {
+	h.blobMu.Lock()
+	defer h.blobMu.Unlock()
+	rc, err := h.blobs.Open(ctx, key)
+	switch {
+	case errors.Is(err, blob.ErrNotFound):
+		return model.Attachment{}, false, nil
+	case err != nil:
+		return model.Attachment{}, false, err
+	}
+	rc.Close()
+
+	added, err := h.store.AddAttachment(ctx, a, h.attachmentQuota)
+	if err != nil {
+		h.releaseBlob(ctx, a.Checksum)
+		return model.Attachment{}, true, err
+	}
+	return added, true, nil
+}
+
+// detectContentType sniffs the content, falling back to the name's
+// extension when sniffing only finds a generic type, as it does for
+// office documents.
+func detectContentType(name string, head []byte) string {
+	sniffed := http.DetectContentType(head)
+	switch sniffed {
+	case "application/octet-stream", "application/zip", "text/plain; charset=utf-8":
+		if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+			return t
+		}
+	}
+	return sniffed
+}
+
+// releaseBlob deletes an attachment blob no attachment uses any more. The
+// caller holds blobMu. Failures only leave an unused blob behind, so they
+// are logged.
+func (h *Handler) releaseBlob(ctx context.Context, checksum string) {
+	inUse, err := h.store.AttachmentBlobInUse(ctx, checksum)
+	if err != nil || inUse {
+		if err != nil {
+			slog.Warn("checking attachment blob", "checksum", checksum, "error", err)
+		}
+		return
+	}
+	if err := h.blobs.Delete(ctx, attachmentKey(checksum)); err != nil {
+		slog.Warn("deleting attachment blob", "checksum", checksum, "error", err)
+	}
+}
+
+// releaseAttachments deletes the blobs of a deleted contact's attachments
+// that no other contact's attachments share.
+func (h *Handler) releaseAttachments(ctx context.Context, list []model.Attachment) {
+	if h.blobs == nil || len(list) == 0 {
+		return
+	}
+	h.blobMu.Lock()
+	defer h.blobMu.Unlock()
+	for _, a := range list {
+		h.releaseBlob(ctx, a.Checksum)
+	}
+}
+
+// getAttachment looks up the attachment in the path, which must belong to
+// the contact in the path, writing a 404 otherwise.
+func (h *Handler) getAttachment(w http.ResponseWriter, r *http.Request) (model.Attachment, bool) {
+	if h.blobs == nil {
+		http.NotFound(w, r)
+		return model.Attachment{}, false
+	}
+	a, err := h.store.GetAttachment(r.Context(), r.PathValue("aid"))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return model.Attachment{}, false
+		}
+		h.serverError(w, r, "get attachment", err)
+		return model.Attachment{}, false
+	}
+	if a.ContactID != r.PathValue("id") {
+		http.NotFound(w, r)
+		return model.Attachment{}, false
+	}
+	return a, true
+}
+
+// DownloadAttachment sends an attachment as a download under its original
+// name. It is never rendered inline, and the headers stop browsers from
+// sniffing or running uploaded content as a page of this site. The
+// download may take longer than the server's write timeout.
+func (h *Handler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	a, ok := h.getAttachment(w, r)
+	if !ok {
+		return
+	}
+	extendDeadlines(w)
+	rc, err := h.blobs.Open(r.Context(), attachmentKey(a.Checksum))
+	if err != nil {
+		h.serverError(w, r, "open attachment", err)
+		return
+	}
+	defer rc.Close()
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("ETag", `"`+a.Checksum+`"`)
+
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, "", a.CreatedAt, rs)
+		return
+	}
+	if _, err := io.Copy(w, rc); err != nil {
+		slog.Warn("writing attachment", "id", a.ID, "error", err)
+	}
+}
+
+// DeleteAttachment removes an attachment, and its blob unless an identical
+// file is still attached, and returns the updated attachments section.
+func (h *Handler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	a, ok := h.getAttachment(w, r)
+	if !ok {
+		return
+	}
+
+	h.blobMu.Lock()
+	err := h.store.DeleteAttachment(r.Context(), a.ID)
+	if err == nil {
+		h.releaseBlob(r.Context(), a.Checksum)
+	}
+	h.blobMu.Unlock()
+	if err != nil {
+		h.serverError(w, r, "delete attachment", err)
+		return
+	}
+	slog.Info("attachment deleted", "id", a.ID, "contact", a.ContactID)
+
+	if !isHTMX(r) {
+		http.Redirect(w, r, "/contacts/"+a.ContactID, http.StatusSeeOther)
+		return
+	}
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+	h.renderAttachments(w, r, http.StatusOK, c, nil)
+}
+
+// renderAttachments renders the contact's attachments section.
+func (h *Handler) renderAttachments(w http.ResponseWriter, r *http.Request, status int, c model.Contact, errs map[string]string) {
+	data := contactData{Contact: c, TZ: timezone(r)}
+	if err := h.loadAttachments(r.Context(), &data); err != nil {
+		h.serverError(w, r, "list attachments", err)
+		return
+	}
+	if errs != nil {
+		data.Attachments.Errors = errs
+	}
+	h.renderComponent(w, r, status, "attachments", data.Attachments)
+}
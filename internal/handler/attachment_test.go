@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/blob"
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+// uploadFile posts a file to the contact's attachments as htmx does.
+func uploadFile(mux http.Handler, contactID, name string, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", name)
+	fw.Write(data)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/contacts/"+contactID+"/attachments", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUploadAttachment(t *testing.T) {
+	h, s := setupTestHandler(t)
+	withPhotos(t, h)
+	mux := h.Routes()
+	ctx := context.Background()
+
+	rec := uploadFile(mux, "1", `..\notes/"Q3 plan".pdf`, []byte("%PDF-1.4 plan"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	list, _ := s.ListAttachments(ctx, "1")
+	if len(list) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(list))
+	}
+	a := list[0]
+	if a.Name != `"Q3 plan".pdf` || a.ContentType != "application/pdf" || a.Size != 13 {
+		t.Errorf("unexpected attachment %+v", a)
+	}
+	if !strings.Contains(rec.Body.String(), `id="attachments"`) || !strings.Contains(rec.Body.String(), "13 B") {
+		t.Error("expected the attachments section listing the file")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/1/attachments/"+a.ID, nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "%PDF-1.4 plan" {
+		t.Fatalf("download: got %d %q", rec.Code, rec.Body)
+	}
+	headers := map[string]string{
+		"Content-Type":            "application/pdf",
+		"Content-Disposition":     `attachment; filename="\"Q3 plan\".pdf"`,
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "default-src 'none'; sandbox",
+	}
+	for k, want := range headers {
+		if got := rec.Header().Get(k); got != want {
+			t.Errorf("%s = %q, want %q", k, got, want)
+		}
+	}
+
+	// Attachments are only reachable through the contact they belong to.
+	req = httptest.NewRequest(http.MethodGet, "/contacts/2/attachments/"+a.ID, nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("other contact: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestUploadAttachment_HTMLIsDownloaded(t *testing.T) {
+	h, s := setupTestHandler(t)
+	withPhotos(t, h)
+	mux := h.Routes()
+
+	uploadFile(mux, "1", "page.html", []byte("<html><script>alert(1)</script></html>"))
+	list, _ := s.ListAttachments(context.Background(), "1")
+	if len(list) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(list))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/1/attachments/"+list[0].ID, nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if cd := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment") {
+		t.Errorf("Content-Disposition = %q, want an attachment", cd)
+	}
+	if csp := rec.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "sandbox") {
+		t.Errorf("Content-Security-Policy = %q, want a sandbox", csp)
+	}
+}
+
+func TestUploadAttachment_Deduplicates(t *testing.T) {
+	h, s := setupTestHandler(t)
+	blobs := withPhotos(t, h)
+	mux := h.Routes()
+	ctx := context.Background()
+
+	data := []byte("the same contract")
+	uploadFile(mux, "1", "contract.txt", data)
+	uploadFile(mux, "2", "copy.txt", data)
+	alice, _ := s.ListAttachments(ctx, "1")
+	bob, _ := s.ListAttachments(ctx, "2")
+	if len(alice) != 1 || len(bob) != 1 || alice[0].Checksum != bob[0].Checksum {
+		t.Fatalf("expected one attachment each with the same checksum, got %+v and %+v", alice, bob)
+	}
+
+	// Removing one copy keeps the blob for the other.
+	req := httptest.NewRequest(http.MethodDelete, "/contacts/1/attachments/"+alice[0].ID, nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "No attachments yet.") {
+		t.Fatalf("delete: got %d %s", rec.Code, rec.Body)
+	}
+	if _, err := blobs.Open(ctx, attachmentKey(bob[0].Checksum)); err != nil {
+		t.Fatalf("shared blob removed: %v", err)
+	}
+
+	// Deleting the last contact using it keeps the blob while the contact
+	// is in the trash, and purging it removes the blob.
+	for _, target := range []string{"/contacts/2", "/trash/2"} {
+		req = httptest.NewRequest(http.MethodDelete, target, nil)
+		req.Header.Set("HX-Request", "true")
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+		_, err := blobs.Open(ctx, attachmentKey(bob[0].Checksum))
+		if target == "/contacts/2" && err != nil {
+			t.Fatalf("blob of trashed contact removed: %v", err)
+		}
+		if target == "/trash/2" && !errors.Is(err, blob.ErrNotFound) {
+			t.Errorf("blob of purged contact still stored: %v", err)
+		}
+	}
+}
+
+// TestUploadAttachment_SlowBody checks that an upload may take longer
+// than the server's read timeout.
+func TestUploadAttachment_SlowBody(t *testing.T) {
+	h, s := setupTestHandler(t)
+	withPhotos(t, h)
+	srv := httptest.NewUnstartedServer(h.Routes())
+	srv.Config.ReadTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		fw, _ := mw.CreateFormFile("file", "slow.txt")
+		fw.Write([]byte("first half, "))
+		time.Sleep(300 * time.Millisecond)
+		fw.Write([]byte("second half"))
+		mw.Close()
+		pw.Close()
+	}()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/contacts/1/attachments", pr)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("HX-Request", "true")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if list, _ := s.ListAttachments(context.Background(), "1"); len(list) != 1 || list[0].Size != 23 {
+		t.Errorf("expected the whole file attached, got %+v", list)
+	}
+}
+
+func TestUploadAttachment_Limits(t *testing.T) {
+	h, s := setupTestHandler(t)
+	withPhotos(t, h)
+	WithAttachmentLimits(10, 16)(h)
+	mux := h.Routes()
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, "Choose a file to upload"},
+		{"too large", bytes.Repeat([]byte("x"), 11), "upload one up to 10 B"},
+		{"fits", bytes.Repeat([]byte("a"), 10), ""},
+		{"over quota", bytes.Repeat([]byte("b"), 7), "Not enough space left"},
+		{"fits quota", bytes.Repeat([]byte("c"), 6), ""},
+	}
+	for _, tt := range tests {
+		rec := uploadFile(mux, "1", "f.txt", tt.data)
+		if tt.want == "" {
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s: expected 200, got %d: %s", tt.name, rec.Code, rec.Body)
+			}
+			continue
+		}
+		if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), tt.want) {
+			t.Errorf("%s: expected 422 with %q, got %d: %s", tt.name, tt.want, rec.Code, rec.Body)
+		}
+	}
+	if list, _ := s.ListAttachments(ctx, "1"); len(list) != 2 {
+		t.Errorf("expected 2 attachments, got %d", len(list))
+	}
+}
+
+// racingStore records another upload for the contact just before each
+// attachment, as a concurrent request finishing first would.
+type racingStore struct {
+	*store.Memory
+}
+
+func (s racingStore) AddAttachment(ctx context.Context, a model.Attachment, quota int64) (model.Attachment, error) {
+	s.Memory.AddAttachment(ctx, model.Attachment{ContactID: a.ContactID, Name: "other.txt", Size: a.Size, Checksum: "other"}, quota)
+	return s.Memory.AddAttachment(ctx, a, quota)
+}
+
+func TestUploadAttachment_ConcurrentQuota(t *testing.T) {
+	h, s := setupTestHandler(t)
+	h.store = racingStore{s}
+	blobs := withPhotos(t, h)
+	WithAttachmentLimits(10, 16)(h)
+
+	rec := uploadFile(h.Routes(), "1", "f.txt", bytes.Repeat([]byte("a"), 10))
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "Not enough space left") {
+		t.Fatalf("expected the quota error, got %d: %s", rec.Code, rec.Body)
+	}
+	if list, _ := s.ListAttachments(context.Background(), "1"); len(list) != 1 || list[0].Name != "other.txt" {
+		t.Errorf("expected only the other upload recorded, got %+v", list)
+	}
+	key := attachmentKey("bf2cb58a68f684d95a3b78ef8f661c9a4e5b09e82cc8f9cc88cce90528caeb27")
+	if _, err := blobs.Open(context.Background(), key); !errors.Is(err, blob.ErrNotFound) {
+		t.Errorf("expected the rejected upload's blob deleted, got %v", err)
+	}
+}
+
+func TestAttachments_Disabled(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	if rec := uploadFile(mux, "1", "f.txt", []byte("hi")); rec.Code != http.StatusNotFound {
+		t.Errorf("upload: expected 404, got %d", rec.Code)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/contacts/1", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if strings.Contains(rec.Body.String(), `id="attachments"`) {
+		t.Error("expected no attachments section without blob storage")
+	}
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/i18n"
+	"github.com/devaloi/htmxapp/internal/ical"
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+// calendarProdID identifies htmxapp in the feeds it writes.
+const calendarProdID = "-//htmxapp//Contacts//EN"
+
+// yearlessStart is the year recurring events for dates without a known
+// year start in. It is a leap year so February 29 keeps its day.
+const yearlessStart = 2000
+
+// upcomingOccasion is a contact's occasion falling on a given day.
+type upcomingOccasion struct {
+	Contact  model.Contact
+	Occasion model.Occasion
+	On       time.Time
+	Today    bool
+
+	// Years is the age or number of years being marked, or 0 if the
+	// date's year is unknown.
+	Years int
+}
+
+// calendarPath returns the feed's path, or "" if the feed is disabled.
+func (h *Handler) calendarPath() string {
+	if h.calendarSecret == "" {
+		return ""
+	}
+	return "/calendar/" + h.calendarSecret + ".ics"
+}
+
+// calendarURL returns the absolute URL clients subscribe to, or "" if the
+// feed is disabled.
+func (h *Handler) calendarURL(r *http.Request) string {
+	path := h.calendarPath()
+	if path == "" {
+		return ""
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + path
+}
+
+// upcomingOccasions returns the contacts' occasions from the day of from
+// up to, but not including, the day of until, soonest first.
+func (h *Handler) upcomingOccasions(ctx context.Context, from, until time.Time) ([]upcomingOccasion, error) {
+	contacts, err := h.store.List(ctx, store.Query{})
+	if err != nil {
+		return nil, err
+	}
+	today, end := model.StartOfDay(from), model.StartOfDay(until)
+	var out []upcomingOccasion
+	for _, c := range contacts {
+		for _, o := range c.Occasions() {
+			on := o.Date.Next(from)
+			if !on.Before(end) {
+				continue
+			}
+			out = append(out, upcomingOccasion{
+				Contact:  c,
+				Occasion: o,
+				On:       on,
+				Today:    on.Equal(today),
+				Years:    o.Date.YearsAt(on.Year()),
+			})
+		}
+	}
+	// Contacts are listed by name, so a stable sort keeps same-day
+	// occasions in name order.
+	slices.SortStableFunc(out, func(a, b upcomingOccasion) int {
+		return a.On.Compare(b.On)
+	})
+	return out, nil
+}
+
+// CalendarFeed serves contacts' birthdays, anniversaries and custom dates
+// as recurring all-day events. The feed is only found under the
+// configured secret, so the URL itself is the credential.
+func (h *Handler) CalendarFeed(w http.ResponseWriter, r *http.Request) {
+	want := h.calendarPath()
+	got := "/calendar/" + r.PathValue("file")
+	if want == "" || subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	contacts, err := h.store.List(r.Context(), store.Query{})
+	if err != nil {
+		h.serverError(w, r, "list contacts", err)
+		return
+	}
+
+	p := h.renderer.Bundle().Printer(h.locale(r))
+	cal := ical.Calendar{ProdID: calendarProdID, Name: p.T("calendar.name")}
+	for _, c := range contacts {
+		seen := map[string]int{}
+		for _, o := range c.Occasions() {
+			uid := "contact-" + c.ID + "-" + o.Kind
+			if o.Kind == model.OccasionCustom {
+				uid += "-" + occasionKey(o)
+			}
+			// Identical custom dates would share a UID; number the repeats.
+			if seen[uid]++; seen[uid] > 1 {
+				uid += "-" + strconv.Itoa(seen[uid])
+			}
+			cal.Events = append(cal.Events, h.occasionEvent(p, c, o, uid+"@htmxapp"))
+		}
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := cal.WriteTo(w); err != nil {
+		slog.Warn("writing calendar", "error", err)
+	}
+}
+
+// occasionKey identifies a custom occasion by its label and date, so its
+// UID stays the same when the contact's other dates change.
+func occasionKey(o model.Occasion) string {
+	sum := sha256.Sum256([]byte(o.Label + "\x00" + o.Date.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// occasionEvent describes a contact's occasion as a yearly all-day event,
+// starting on the date itself or in yearlessStart if the year is unknown.
+func (h *Handler) occasionEvent(p *i18n.Printer, c model.Contact, o model.Occasion, uid string) ical.Event {
+	name := c.DisplayName(h.nameOrder)
+	year := cmp.Or(o.Date.Year, yearlessStart)
+	e := ical.Event{
+		UID:   uid,
+		Date:  o.Date.In(year, time.UTC),
+		RRule: "FREQ=YEARLY",
+		Stamp: c.UpdatedAt,
+	}
+	// A plain yearly rule skips February 29 in common years; the last day
+	// of February falls on the 28th instead.
+	if o.Date.Month == time.February && o.Date.Day == 29 {
+		e.RRule = "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1"
+	}
+
+	switch o.Kind {
+	case model.OccasionCustom:
+		e.Summary = p.T("calendar.summary.custom", "name", name, "label", o.Label)
+	default:
+		e.Summary = p.T("calendar.summary."+o.Kind, "name", name)
+	}
+	if o.Date.HasYear() {
+		key := "calendar.since"
+		if o.Kind == model.OccasionBirthday {
+			key = "calendar.born"
+		}
+		e.Description = p.T(key, "year", o.Date.Year)
+	}
+	return e
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/ical"
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	h.calendarSecret = "s3cret"
+
+	req := httptest.NewRequest(http.MethodGet, "/calendar/s3cret.ics", nil)
+	rec := httptest.NewRecorder()
+	h.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ical.ContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := rec.Body.String()
+	bob := "DTSTART;VALUE=DATE:2000" + time.Now().Format("0102")
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:contact-1-birthday@htmxapp\r\n",
+		"DTSTART;VALUE=DATE:19850412\r\n",
+		"SUMMARY:Alice Johnson's birthday\r\n",
+		"DESCRIPTION:Born 1985\r\n",
+		bob,
+		"UID:contact-3-anniversary@htmxapp\r\n",
+		"DESCRIPTION:Since 2012\r\n",
+		"UID:contact-5-custom-30e770386e9323eb@htmxapp\r\n",
+		"SUMMARY:Eve Davis: Joined the team\r\n",
+		"RRULE:FREQ=YEARLY\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in feed:\n%s", want, body)
+		}
+	}
+}
+
+func TestCalendarFeed_CustomUIDsStable(t *testing.T) {
+	h, s := setupTestHandler(t)
+	h.calendarSecret = "s3cret"
+	ctx := context.Background()
+	feed := func() string {
+		rec := httptest.NewRecorder()
+		h.Routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/calendar/s3cret.ics", nil))
+		return rec.Body.String()
+	}
+	uid := "UID:contact-5-custom-30e770386e9323eb@htmxapp\r\n"
+
+	c, _ := s.Get(ctx, "5")
+	c.Dates = append([]model.ContactDate{{Label: "First call", Date: "2018-03-01"}}, c.Dates...)
+	c.Dates = append(c.Dates, c.Dates[1])
+	if _, err := s.Update(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	body := feed()
+	for _, want := range []string{uid, "UID:contact-5-custom-30e770386e9323eb-2@htmxapp\r\n"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in feed:\n%s", want, body)
+		}
+	}
+
+	c.Dates = c.Dates[1:2]
+	if _, err := s.Update(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if body := feed(); !strings.Contains(body, uid) {
+		t.Errorf("expected %q after removing a date:\n%s", uid, body)
+	}
+}
+
+func TestCalendarFeed_LeapDay(t *testing.T) {
+	h, s := setupTestHandler(t)
+	h.calendarSecret = "s3cret"
+	if _, err := s.Create(context.Background(), model.Contact{FirstName: "Leap", Email: "leap@example.com", Birthday: "1996-02-29"}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/calendar/s3cret.ics", nil)
+	req.Header.Set("Accept-Language", "es")
+	rec := httptest.NewRecorder()
+	h.Routes().ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"DTSTART;VALUE=DATE:19960229\r\nRRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1\r\n",
+		"SUMMARY:Cumpleaños de Leap\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in feed:\n%s", want, body)
+		}
+	}
+}
+
+func TestCalendarFeed_NotFound(t *testing.T) {
+	h, _ := setupTestHandler(t)
+
+	for _, tt := range []struct{ secret, path string }{
+		{"", "/calendar/.ics"},
+		{"s3cret", "/calendar/guess.ics"},
+		{"s3cret", "/calendar/s3cret"},
+	} {
+		h.calendarSecret = tt.secret
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		rec := httptest.NewRecorder()
+		h.Routes().ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("secret %q, GET %s: expected 404, got %d", tt.secret, tt.path, rec.Code)
+		}
+	}
+}
+
+func TestHome_Upcoming(t *testing.T) {
+	h, s := setupTestHandler(t)
+	h.calendarSecret = "s3cret"
+	ctx := context.Background()
+	now := time.Now().UTC()
+	s.Create(ctx, model.Contact{FirstName: "Today", LastName: "Person", Email: "today@example.com", Birthday: now.AddDate(-30, 0, 0).Format(model.DateLayout)})
+	s.Create(ctx, model.Contact{FirstName: "Past", LastName: "Person", Email: "past@example.com", Birthday: "--01-01"})
+	if now.Month() == time.January && now.Day() == 1 {
+		t.Skip("the past birthday is today")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "contacts.example.com"
+	rec := httptest.NewRecorder()
+	h.Routes().ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "Today Person") || !strings.Contains(body, "turns 30") {
+		t.Errorf("expected today's birthday in the upcoming panel:\n%s", body)
+	}
+	if strings.Contains(body, "Past Person") {
+		t.Error("expected a birthday earlier in the year to be left out")
+	}
+	if !strings.Contains(body, `value="http://contacts.example.com/calendar/s3cret.ics"`) {
+		t.Error("expected the feed URL to subscribe to")
+	}
+}
+
+func TestCreateContact_Dates(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	form := url.Values{
+		"first_name":  {"Frank"},
+		"email":       {"frank@example.com"},
+		"birthday":    {"04-12"},
+		"anniversary": {""},
+		"date_label":  {"Joined", ""},
+		"date_value":  {"2019-09-01", ""},
+	}
+	if rec := postForm(mux, "/contacts", form, false); rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", rec.Code)
+	}
+	c, err := s.Get(context.Background(), "6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Birthday != "--04-12" || len(c.Dates) != 1 || c.Dates[0] != (model.ContactDate{Label: "Joined", Date: "2019-09-01"}) {
+		t.Errorf("stored %q, %+v", c.Birthday, c.Dates)
+	}
+
+	form = url.Values{
+		"first_name": {"Grace"},
+		"email":      {"grace@example.com"},
+		"birthday":   {"1990-02-30"},
+		"date_label": {"Moved"},
+		"date_value": {""},
+	}
+	rec := postForm(mux, "/contacts", form, false)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{`value="1990-02-30"`, "Enter a date as YYYY-MM-DD", "Enter both a label and a date", `value="Moved"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in form", want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+type companyListData struct {
+	Companies []model.Company
+	Search    string
+}
+
+type companyData struct {
+	Company  model.Company
+	Contacts []model.Contact
+}
+
+type companyFormData struct {
+	Company model.Company
+	Errors  map[string]string
+}
+
+// ListCompanies renders the companies page.
+func (h *Handler) ListCompanies(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	companies, err := h.store.ListCompanies(r.Context(), q)
+	if err != nil {
+		h.serverError(w, r, "list companies", err)
+		return
+	}
+
+	h.renderPage(w, r, http.StatusOK, "companies", companyListData{Companies: companies, Search: q})
+}
+
+// ShowCompany renders a company and the people linked to it.
+func (h *Handler) ShowCompany(w http.ResponseWriter, r *http.Request) {
+	c, err := h.store.GetCompany(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "get company", err)
+		return
+	}
+
+	contacts, err := h.store.List(r.Context(), store.Query{CompanyID: c.ID})
+	if err != nil {
+		h.serverError(w, r, "list company contacts", err)
+		return
+	}
+
+	h.renderPage(w, r, http.StatusOK, "company", companyData{Company: c, Contacts: contacts})
+}
+
+// NewCompany renders the new company form.
+func (h *Handler) NewCompany(w http.ResponseWriter, r *http.Request) {
+	data := companyFormData{Errors: make(map[string]string)}
+	h.renderPage(w, r, http.StatusOK, "company-form", data)
+}
+
+// CreateCompany handles the form submission for creating a company.
+func (h *Handler) CreateCompany(w http.ResponseWriter, r *http.Request) {
+	c := companyFromForm(r)
+
+	if errs := c.Validate(); len(errs) > 0 {
+		data := companyFormData{Company: c, Errors: errs}
+		h.renderPage(w, r, http.StatusUnprocessableEntity, "company-form", data)
+		return
+	}
+
+	created, err := h.store.CreateCompany(r.Context(), c)
+	if err != nil {
+		if errors.Is(err, model.ErrDuplicateDomain) {
+			data := companyFormData{
+				Company: c,
+				Errors:  map[string]string{"Domain": model.CodeDuplicate},
+			}
+			h.renderPage(w, r, http.StatusUnprocessableEntity, "company-form", data)
+			return
+		}
+		h.serverError(w, r, "create company", err)
+		return
+	}
+
+	slog.Info("company created", "id", created.ID, "name", created.Name)
+	http.Redirect(w, r, "/companies/"+created.ID, http.StatusSeeOther)
+}
+
+// EditCompany renders the edit form for a company.
+func (h *Handler) EditCompany(w http.ResponseWriter, r *http.Request) {
+	c, err := h.store.GetCompany(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "get company", err)
+		return
+	}
+
+	data := companyFormData{Company: c, Errors: make(map[string]string)}
+	h.renderPage(w, r, http.StatusOK, "company-form", data)
+}
+
+// UpdateCompany handles the form submission for updating a company.
+func (h *Handler) UpdateCompany(w http.ResponseWriter, r *http.Request) {
+	c := companyFromForm(r)
+	c.ID = r.PathValue("id")
+
+	if errs := c.Validate(); len(errs) > 0 {
+		data := companyFormData{Company: c, Errors: errs}
+		h.renderPage(w, r, http.StatusUnprocessableEntity, "company-form", data)
+		return
+	}
+
+	updated, err := h.store.UpdateCompany(r.Context(), c)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if errors.Is(err, model.ErrDuplicateDomain) {
+			data := companyFormData{
+				Company: c,
+				Errors:  map[string]string{"Domain": model.CodeDuplicate},
+			}
+			h.renderPage(w, r, http.StatusUnprocessableEntity, "company-form", data)
+			return
+		}
+		h.serverError(w, r, "update company", err)
+		return
+	}
+
+	slog.Info("company updated", "id", updated.ID, "name", updated.Name)
+	http.Redirect(w, r, "/companies/"+updated.ID, http.StatusSeeOther)
+}
+
+// DeleteCompany removes a company, unlinking its contacts, and returns
+// empty content for htmx swap.
+func (h *Handler) DeleteCompany(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if err := h.store.DeleteCompany(r.Context(), id); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "delete company", err)
+		return
+	}
+
+	slog.Info("company deleted", "id", id)
+
+	if isHTMX(r) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.Redirect(w, r, "/companies", http.StatusSeeOther)
+}
+
+// SuggestCompany picks the company matching the domain of the email being
+// entered, unless the form already names one, and returns the contact
+// form's company fields for an htmx swap.
+func (h *Handler) SuggestCompany(w http.ResponseWriter, r *http.Request) {
+	c := h.contactFromForm(r)
+	companies, err := h.store.ListCompanies(r.Context(), "")
+	if err != nil {
+		h.serverError(w, r, "list companies", err)
+		return
+	}
+
+	data := contactFormData{Contact: c, Errors: make(map[string]string), Companies: companies}
+	if c.CompanyID == "" {
+		if domain := model.EmailDomain(c.Email); domain != "" {
+			if company, err := h.store.CompanyByDomain(r.Context(), domain); err == nil {
+				data.Contact.CompanyID = company.ID
+				data.Suggested = true
+			}
+		}
+	}
+	h.renderComponent(w, r, http.StatusOK, "company-fields", data)
+}
+
+func companyFromForm(r *http.Request) model.Company {
+	return model.Company{
+		Name:    r.FormValue("name"),
+		Domain:  strings.TrimSpace(r.FormValue("domain")),
+		Website: strings.TrimSpace(r.FormValue("website")),
+	}
+}
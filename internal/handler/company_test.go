@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+func postForm(mux http.Handler, path string, form url.Values, htmx bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if htmx {
+		req.Header.Set("HX-Request", "true")
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestListCompanies(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/companies?q=acme", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Acme Corporation") || strings.Contains(body, "Initech") {
+		t.Errorf("expected only Acme in results:\n%s", body)
+	}
+	if !strings.Contains(body, "1 person") {
+		t.Error("expected people count")
+	}
+}
+
+func TestShowCompany(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/companies/1", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Carol Williams") || !strings.Contains(body, "Head of Sales") || strings.Contains(body, "Alice Johnson") {
+		t.Errorf("expected only Acme's people:\n%s", body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/companies/999", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestCreateCompany(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	rec := postForm(mux, "/companies", url.Values{"name": {"Globex"}, "domain": {"globex.com"}}, false)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/companies/3" {
+		t.Errorf("expected redirect to /companies/3, got %s", loc)
+	}
+	if c, err := s.GetCompany(context.Background(), "3"); err != nil || c.Name != "Globex" {
+		t.Errorf("expected Globex stored, got %+v, %v", c, err)
+	}
+
+	rec = postForm(mux, "/companies", url.Values{"name": {"Acme Again"}, "domain": {"ACME.com"}}, false)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "Another company already uses this domain") {
+		t.Errorf("expected duplicate domain error, got %d", rec.Code)
+	}
+
+	rec = postForm(mux, "/companies", url.Values{"name": {""}}, false)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "Name is required") {
+		t.Errorf("expected required name error, got %d", rec.Code)
+	}
+}
+
+func TestUpdateCompany(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	rec := postForm(mux, "/companies/2", url.Values{"name": {"Initrode"}, "domain": {"initrode.com"}}, false)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", rec.Code)
+	}
+	contacts, _ := s.List(context.Background(), store.Query{Search: "initrode"})
+	if len(contacts) != 1 || contacts[0].Company != "Initrode" {
+		t.Errorf("expected renamed company in contact search, got %+v", contacts)
+	}
+
+	rec = postForm(mux, "/companies/999", url.Values{"name": {"Nobody"}}, false)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestDeleteCompany_HTMX(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodDelete, "/companies/1", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("expected empty 200, got %d %q", rec.Code, rec.Body.String())
+	}
+	if c, _ := s.Get(context.Background(), "3"); c.CompanyID != "" {
+		t.Errorf("expected Carol unlinked, got company %q", c.CompanyID)
+	}
+}
+
+func TestSuggestCompany(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	rec := postForm(mux, "/contacts/suggest-company", url.Values{"email": {"Wile E. <wile@labs.acme.com>"}}, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `<option value="1" selected>Acme Corporation</option>`) || !strings.Contains(body, "Suggested from the email domain") {
+		t.Errorf("expected Acme suggested:\n%s", body)
+	}
+
+	rec = postForm(mux, "/contacts/suggest-company", url.Values{"email": {"wile@acme.com"}, "company_id": {"2"}}, true)
+	body = rec.Body.String()
+	if !strings.Contains(body, `<option value="2" selected>Initech</option>`) || strings.Contains(body, "Suggested") {
+		t.Errorf("expected chosen company kept:\n%s", body)
+	}
+
+	rec = postForm(mux, "/contacts/suggest-company", url.Values{"email": {"wile@gmail.com"}}, true)
+	if strings.Contains(rec.Body.String(), "selected") {
+		t.Errorf("expected no suggestion:\n%s", rec.Body.String())
+	}
+}
+
+func TestCreateContact_UnknownCompany(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	rec := postForm(mux, "/contacts", url.Values{"first_name": {"Frank"}, "email": {"frank@example.com"}, "company_id": {"999"}}, false)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "That company no longer exists") {
+		t.Errorf("expected unknown company error, got %d", rec.Code)
+	}
+}
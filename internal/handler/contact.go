@@ -1,100 +1,283 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"log/slog"
+	"maps"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/devaloi/htmxapp/internal/graph"
 	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/store"
 )
 
 type contactListData struct {
 	Contacts []model.Contact
 	Count    int
 	Search   string
+	Starred  bool
+	Sort     string
+	Query    url.Values
+
+	// Contacted is the selected last-contacted filter, one of
+	// contactedFilters.
+	Contacted        string
+	ContactedFilters []string
+
+	Filter filterData
+
+	// Saved is the sidebar of saved searches.
+	Saved savedSearchesData
 }
 
 type contactFormData struct {
+	Contact   model.Contact
+	Errors    map[string]string
+	TZ        string
+	Companies []model.Company
+
+	// Suggested is set when the company was picked from the email domain.
+	Suggested bool
+
+	// Photos is set when photos can be uploaded.
+	Photos bool
+
+	// Draft is the form's autosaved draft, if any, and Restored is set
+	// when the form shows it.
+	Draft    model.Draft
+	Restored bool
+}
+
+type contactData struct {
 	Contact model.Contact
-	Errors  map[string]string
+	TZ      string
+
+	Relationships []relationView
+	RelationTypes []string
+	Graph         *graph.Graph
+	Errors        map[string]string
+
+	Interactions     []interactionView
+	InteractionTypes []string
+	Log              interactionForm
+	LogErrors        map[string]string
+
+	Reminders       []reminderView
+	ReminderPresets []string
+	ReminderForm    reminderForm
+	ReminderErrors  map[string]string
+
+	// Attachments is nil when attachments are disabled.
+	Attachments *attachmentsData
+
+	// PinnedNote is shown at the top of the page, apart from the other
+	// Notes. NoteForm is the text of the note being added.
+	PinnedNote *noteView
+	Notes      []noteView
+	NoteForm   string
+	NoteErrors map[string]string
 }
 
-// ListContacts renders the full contacts page.
+// contactedFilters are the values of the contacts list's contacted
+// parameter: contacted within a number of days, not contacted for that
+// long ("over-"), or never contacted.
+var contactedFilters = []string{"7d", "30d", "90d", "over-30d", "over-90d", "never"}
+
+// listQuery reads the search, starred, sort, contacted and filter
+// parameters, with stars those of user. The returned sort parameter
+// defaults to name order so column headers can toggle it.
+func listQuery(v url.Values, user string) (store.Query, string) {
+	sort := v.Get("sort")
+	key, desc := store.ParseSort(sort)
+	if key == "" {
+		key, sort = store.SortName, store.SortName
+	}
+	q := store.Query{
+		Search:  v.Get("q"),
+		User:    user,
+		Starred: v.Get("starred") == "true",
+		Sort:    key,
+		Desc:    desc,
+		Filter:  store.ParseFilter(v),
+	}
+	contactedQuery(&q, v.Get("contacted"), time.Now())
+	return q, sort
+}
+
+// contactedQuery applies a contacted filter value to q. Unknown values
+// are ignored.
+func contactedQuery(q *store.Query, filter string, now time.Time) {
+	if filter == "never" {
+		q.NeverContacted = true
+		return
+	}
+	rest, over := strings.CutPrefix(filter, "over-")
+	days, err := strconv.Atoi(strings.TrimSuffix(rest, "d"))
+	if err != nil || days <= 0 || !strings.HasSuffix(rest, "d") {
+		return
+	}
+	since := now.AddDate(0, 0, -days)
+	if over {
+		q.NotContactedSince = since
+	} else {
+		q.ContactedSince = since
+	}
+}
+
+// ListContacts renders the full contacts page. A list parameter opens a
+// saved search.
 func (h *Handler) ListContacts(w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query().Get("q")
-	contacts, err := h.store.List(r.Context(), q)
+	v, err := h.listValues(r.Context(), r.URL.Query())
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "get saved search", err)
 		return
 	}
-
-	data := contactListData{
-		Contacts: contacts,
-		Count:    h.store.Count(r.Context()),
-		Search:   q,
+	data, err := h.contactList(r.Context(), v)
+	if err != nil {
+		h.serverError(w, r, "list contacts", err)
+		return
 	}
+	h.renderPage(w, r, http.StatusOK, "contacts", data)
+}
 
-	if err := h.renderer.RenderPage(w, "contacts", data); err != nil {
-		slog.Error("render contacts page", "error", err)
+// contactList loads the contacts page for the list parameters v.
+func (h *Handler) contactList(ctx context.Context, v url.Values) (contactListData, error) {
+	q, sort := listQuery(v, UserID(ctx))
+	contacts, err := h.store.List(ctx, q)
+	if err != nil {
+		return contactListData{}, err
 	}
+	saved, err := h.loadSavedSearches(ctx, v.Get("list"))
+	if err != nil {
+		return contactListData{}, err
+	}
+	filter := h.filterView(q.Filter)
+	filter.ClearQuery = clearFilterQuery(v)
+
+	return contactListData{
+		Contacts: contacts,
+		Count:    h.store.Count(ctx),
+		Search:   q.Search,
+		Starred:  q.Starred,
+		Sort:     sort,
+		Query:    v,
+
+		Contacted:        v.Get("contacted"),
+		ContactedFilters: contactedFilters,
+
+		Filter: filter,
+		Saved:  saved,
+	}, nil
 }
 
 // SearchContacts returns a partial with matching contact rows (htmx).
 func (h *Handler) SearchContacts(w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query().Get("q")
+	q, _ := listQuery(r.URL.Query(), UserID(r.Context()))
 	contacts, err := h.store.List(r.Context(), q)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		h.serverError(w, r, "search contacts", err)
 		return
 	}
 
-	if err := h.renderer.RenderPartial(w, "contact-rows", contacts); err != nil {
-		slog.Error("render contact rows", "error", err)
-	}
+	h.renderPartial(w, r, http.StatusOK, "contact-rows", contacts)
 }
 
 // NewContact renders the new contact form.
 func (h *Handler) NewContact(w http.ResponseWriter, r *http.Request) {
-	data := contactFormData{Errors: make(map[string]string)}
-	if err := h.renderer.RenderPage(w, "contact-form", data); err != nil {
-		slog.Error("render new contact form", "error", err)
-	}
+	h.renderDraftForm(w, r, model.Contact{})
 }
 
 // CreateContact handles the form submission for creating a contact.
 func (h *Handler) CreateContact(w http.ResponseWriter, r *http.Request) {
-	c := contactFromForm(r)
+	if !parseContactForm(w, r) {
+		return
+	}
+	c := h.contactFromForm(r)
 
-	if errs := c.Validate(); len(errs) > 0 {
-		w.WriteHeader(http.StatusUnprocessableEntity)
-		data := contactFormData{Contact: c, Errors: errs}
-		if err := h.renderer.RenderPage(w, "contact-form", data); err != nil {
-			slog.Error("render form with errors", "error", err)
-		}
+	errs := h.validate(&c)
+	renditions, code := h.readPhoto(r)
+	if code != "" {
+		errs["Photo"] = code
+	}
+	if len(errs) > 0 {
+		h.renderContactForm(w, r, http.StatusUnprocessableEntity, c, errs)
 		return
 	}
 
+	if renditions != nil {
+		id, err := h.storePhoto(r.Context(), renditions)
+		if err != nil {
+			h.serverError(w, r, "store photo", err)
+			return
+		}
+		c.PhotoID = id
+	}
+
 	created, err := h.store.Create(r.Context(), c)
 	if err != nil {
-		if errors.Is(err, model.ErrDuplicateEmail) {
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			data := contactFormData{
-				Contact: c,
-				Errors:  map[string]string{"Email": "A contact with this email already exists"},
-			}
-			if renderErr := h.renderer.RenderPage(w, "contact-form", data); renderErr != nil {
-				slog.Error("render form with duplicate error", "error", renderErr)
-			}
+		h.deletePhoto(r.Context(), c.PhotoID)
+		c.PhotoID = ""
+		if errs, ok := contactStoreErrors(err); ok {
+			h.renderContactForm(w, r, http.StatusUnprocessableEntity, c, errs)
 			return
 		}
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		h.serverError(w, r, "create contact", err)
 		return
 	}
 
-	slog.Info("contact created", "id", created.ID, "name", created.FullName())
+	h.discardDraft(r.Context(), "")
+	slog.Info("contact created", "id", created.ID, "name", created.FullName(), "photo", created.PhotoID != "")
 	http.Redirect(w, r, "/contacts", http.StatusSeeOther)
 }
 
+// ShowContact renders a contact's detail page.
+func (h *Handler) ShowContact(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+
+	data := contactData{Contact: c, TZ: timezone(r)}
+	if err := h.loadContactPage(r.Context(), &data, graphHops(r)); err != nil {
+		h.serverError(w, r, "load contact", err)
+		return
+	}
+	h.recordView(r, c.ID)
+	h.renderPage(w, r, http.StatusOK, "contact", data)
+}
+
+// loadContactPage fills in everything the contact page shows besides the
+// contact itself, and whether the user starred them.
+func (h *Handler) loadContactPage(ctx context.Context, data *contactData, hops int) error {
+	starred, err := h.store.IsStarred(ctx, UserID(ctx), data.Contact.ID)
+	if err != nil {
+		return err
+	}
+	data.Contact.Starred = starred
+	if err := h.loadRelationships(ctx, data, hops); err != nil {
+		return err
+	}
+	if err := h.loadReminders(ctx, data); err != nil {
+		return err
+	}
+	if err := h.loadAttachments(ctx, data); err != nil {
+		return err
+	}
+	if err := h.loadNotes(ctx, data, ""); err != nil {
+		return err
+	}
+	return h.loadInteractions(ctx, data)
+}
+
 // EditContact renders the edit form for a contact.
 func (h *Handler) EditContact(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
@@ -104,72 +287,95 @@ func (h *Handler) EditContact(w http.ResponseWriter, r *http.Request) {
 			http.NotFound(w, r)
 			return
 		}
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		h.serverError(w, r, "get contact", err)
 		return
 	}
 
-	data := contactFormData{Contact: c, Errors: make(map[string]string)}
-	if err := h.renderer.RenderPage(w, "contact-form", data); err != nil {
-		slog.Error("render edit form", "error", err)
-	}
+	h.recordView(r, c.ID)
+	h.renderDraftForm(w, r, c)
 }
 
-// UpdateContact handles the form submission for updating a contact.
+// UpdateContact handles the form submission for updating a contact. The
+// photo is kept unless a new one is uploaded or removal is ticked.
 func (h *Handler) UpdateContact(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
-	c := contactFromForm(r)
-	c.ID = id
+	if !parseContactForm(w, r) {
+		return
+	}
+	existing, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+	c := h.contactFromForm(r)
+	c.ID = existing.ID
+	c.PhotoID = existing.PhotoID
 
-	if errs := c.Validate(); len(errs) > 0 {
-		w.WriteHeader(http.StatusUnprocessableEntity)
-		data := contactFormData{Contact: c, Errors: errs}
-		if err := h.renderer.RenderPage(w, "contact-form", data); err != nil {
-			slog.Error("render edit form with errors", "error", err)
-		}
+	errs := h.validate(&c)
+	renditions, code := h.readPhoto(r)
+	if code != "" {
+		errs["Photo"] = code
+	}
+	if len(errs) > 0 {
+		h.renderContactForm(w, r, http.StatusUnprocessableEntity, c, errs)
 		return
 	}
 
+	switch {
+	case renditions != nil:
+		id, err := h.storePhoto(r.Context(), renditions)
+		if err != nil {
+			h.serverError(w, r, "store photo", err)
+			return
+		}
+		c.PhotoID = id
+	case r.FormValue("remove_photo") != "":
+		c.PhotoID = ""
+	}
+
 	updated, err := h.store.Update(r.Context(), c)
 	if err != nil {
+		if c.PhotoID != existing.PhotoID {
+			h.deletePhoto(r.Context(), c.PhotoID)
+			c.PhotoID = existing.PhotoID
+		}
 		if errors.Is(err, model.ErrNotFound) {
 			http.NotFound(w, r)
 			return
 		}
-		if errors.Is(err, model.ErrDuplicateEmail) {
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			data := contactFormData{
-				Contact: c,
-				Errors:  map[string]string{"Email": "A contact with this email already exists"},
-			}
-			if renderErr := h.renderer.RenderPage(w, "contact-form", data); renderErr != nil {
-				slog.Error("render edit form with duplicate error", "error", renderErr)
-			}
+		if errs, ok := contactStoreErrors(err); ok {
+			h.renderContactForm(w, r, http.StatusUnprocessableEntity, c, errs)
 			return
 		}
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		h.serverError(w, r, "update contact", err)
 		return
 	}
+	if existing.PhotoID != updated.PhotoID {
+		h.deletePhoto(r.Context(), existing.PhotoID)
+	}
 
+	h.discardDraft(r.Context(), updated.ID)
 	slog.Info("contact updated", "id", updated.ID, "name", updated.FullName())
 	http.Redirect(w, r, "/contacts", http.StatusSeeOther)
 }
 
-// DeleteContact removes a contact and returns empty content for htmx swap.
+// DeleteContact moves a contact to the trash and returns empty content
+// for htmx swap.
 func (h *Handler) DeleteContact(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
 
-	if err := h.store.Delete(r.Context(), id); err != nil {
+	if err := h.removeContact(r.Context(), c.ID); err != nil {
 		if errors.Is(err, model.ErrNotFound) {
 			http.NotFound(w, r)
 			return
 		}
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		h.serverError(w, r, "delete contact", err)
 		return
 	}
 
-	slog.Info("contact deleted", "id", id)
-
 	if isHTMX(r) {
+		w.Header().Set("HX-Trigger", "contacts-changed")
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -177,11 +383,183 @@ func (h *Handler) DeleteContact(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/contacts", http.StatusSeeOther)
 }
 
-func contactFromForm(r *http.Request) model.Contact {
+// DeleteContacts moves the selected contacts id to the trash, as
+// DeleteContact does one, and returns the contact rows for the list query sent with them.
+// With no id but a list parameter it deletes every contact the saved
+// search finds. Contacts already gone are skipped.
+func (h *Handler) DeleteContacts(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	v, ids := r.PostForm, r.PostForm["id"]
+	if len(ids) == 0 && !v.Has("list") {
+		http.Error(w, "no contacts to delete", http.StatusBadRequest)
+		return
+	}
+	v, err := h.listValues(r.Context(), v)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "get saved search", err)
+		return
+	}
+	q, _ := listQuery(v, UserID(r.Context()))
+	if len(ids) == 0 {
+		matches, err := h.store.List(r.Context(), q)
+		if err != nil {
+			h.serverError(w, r, "list contacts", err)
+			return
+		}
+		for _, c := range matches {
+			ids = append(ids, c.ID)
+		}
+	}
+
+	for _, id := range ids {
+		if err := h.removeContact(r.Context(), id); err != nil && !errors.Is(err, model.ErrNotFound) {
+			h.serverError(w, r, "delete contact", err)
+			return
+		}
+	}
+
+	if !isHTMX(r) {
+		back := "/contacts"
+		if id := v.Get("list"); id != "" {
+			back += "?" + url.Values{"list": {id}}.Encode()
+		}
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+	contacts, err := h.store.List(r.Context(), q)
+	if err != nil {
+		h.serverError(w, r, "list contacts", err)
+		return
+	}
+	w.Header().Set("HX-Trigger", "contacts-changed")
+	h.renderPartial(w, r, http.StatusOK, "contact-rows", contacts)
+}
+
+// removeContact moves the contact to the trash. Its photo and
+// attachments are kept until it is deleted from there for good.
+func (h *Handler) removeContact(ctx context.Context, id string) error {
+	if err := h.store.Delete(ctx, id); err != nil {
+		return err
+	}
+	slog.Info("contact moved to trash", "id", id)
+	return nil
+}
+
+// ParseName splits the pasted full name into the contact form's name
+// fields and returns them for an htmx swap.
+func (h *Handler) ParseName(w http.ResponseWriter, r *http.Request) {
+	c := h.contactFromForm(r)
+	if full := strings.TrimSpace(r.FormValue("full_name")); full != "" {
+		c.SetName(model.ParseName(full, h.nameOrder))
+	}
+	data := contactFormData{Contact: c, Errors: make(map[string]string)}
+	h.renderComponent(w, r, http.StatusOK, "name-fields", data)
+}
+
+// renderContactForm renders the contact form with the companies the
+// contact can be linked to.
+func (h *Handler) renderContactForm(w http.ResponseWriter, r *http.Request, status int, c model.Contact, errs map[string]string) {
+	h.renderContactFormData(w, r, status, contactFormData{Contact: c, Errors: errs})
+}
+
+// renderContactFormData renders the contact form for data, filling in
+// the rest of what the form needs.
+func (h *Handler) renderContactFormData(w http.ResponseWriter, r *http.Request, status int, data contactFormData) {
+	companies, err := h.store.ListCompanies(r.Context(), "")
+	if err != nil {
+		h.serverError(w, r, "list companies", err)
+		return
+	}
+	if data.Errors == nil {
+		data.Errors = make(map[string]string)
+	}
+
+	data.TZ, data.Companies, data.Photos = timezone(r), companies, h.blobs != nil
+	h.renderPage(w, r, status, "contact-form", data)
+}
+
+// contactStoreErrors maps store errors caused by the submitted form to
+// validation errors.
+func contactStoreErrors(err error) (map[string]string, bool) {
+	switch {
+	case errors.Is(err, model.ErrDuplicateEmail):
+		return map[string]string{"Email": model.CodeDuplicate}, true
+	case errors.Is(err, model.ErrUnknownCompany):
+		return map[string]string{"CompanyID": model.CodeUnknownCompany}, true
+	}
+	return nil, false
+}
+
+// validate checks c, its custom fields and the email policy. Dates and
+// custom values are normalized, and a valid address written with a
+// display name is replaced by the bare address.
+func (h *Handler) validate(c *model.Contact) map[string]string {
+	c.NormalizeDates()
+	errs := c.Validate()
+	custom, fieldErrs := model.ValidateFields(h.fields, c.Custom)
+	c.Custom = custom
+	maps.Copy(errs, fieldErrs)
+	if _, ok := errs["Email"]; ok {
+		return errs
+	}
+	if !h.emails.Allows(c.Email) {
+		errs["Email"] = model.CodeDomain
+		return errs
+	}
+	c.Email, _ = model.ParseEmail(c.Email)
+	return errs
+}
+
+func (h *Handler) contactFromForm(r *http.Request) model.Contact {
+	custom := make(map[string]string, len(h.fields))
+	for _, f := range h.fields {
+		if v := r.FormValue(f.InputName()); v != "" {
+			custom[f.Key] = v
+		}
+	}
 	return model.Contact{
-		FirstName: r.FormValue("first_name"),
-		LastName:  r.FormValue("last_name"),
-		Email:     r.FormValue("email"),
-		Phone:     r.FormValue("phone"),
+		Custom:            custom,
+		Prefix:            r.FormValue("prefix"),
+		FirstName:         r.FormValue("first_name"),
+		MiddleName:        r.FormValue("middle_name"),
+		LastName:          r.FormValue("last_name"),
+		Suffix:            r.FormValue("suffix"),
+		Nickname:          r.FormValue("nickname"),
+		PhoneticFirstName: r.FormValue("phonetic_first_name"),
+		PhoneticLastName:  r.FormValue("phonetic_last_name"),
+		Email:             r.FormValue("email"),
+		Phone:             r.FormValue("phone"),
+		CompanyID:         r.FormValue("company_id"),
+		Title:             r.FormValue("title"),
+		Birthday:          strings.TrimSpace(r.FormValue("birthday")),
+		Anniversary:       strings.TrimSpace(r.FormValue("anniversary")),
+		Dates:             datesFromForm(r),
+	}
+}
+
+// datesFromForm reads the custom date rows, paired by position in the
+// date_label and date_value fields. Rows left blank are dropped.
+func datesFromForm(r *http.Request) []model.ContactDate {
+	labels, values := r.Form["date_label"], r.Form["date_value"]
+	var dates []model.ContactDate
+	for i := range max(len(labels), len(values)) {
+		var d model.ContactDate
+		if i < len(labels) {
+			d.Label = strings.TrimSpace(labels[i])
+		}
+		if i < len(values) {
+			d.Date = strings.TrimSpace(values[i])
+		}
+		if d.Label != "" || d.Date != "" {
+			dates = append(dates, d)
+		}
 	}
+	return dates
 }
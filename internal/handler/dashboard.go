@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/chart"
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/store"
+	"github.com/devaloi/htmxapp/internal/tmpl"
+)
+
+// Dashboard panel sizes. Each panel is loaded by the home page after it
+// renders, so a slow aggregate doesn't hold up the page.
+const (
+	dashboardWeeks   = 12
+	dashboardDomains = 6
+	dashboardChanges = 8
+
+	chartWidth      = 560
+	chartHeight     = 180
+	chartRowHeight  = 28
+	chartLabelWidth = 170
+)
+
+// createdPanel is the chart of contacts created per week.
+type createdPanel struct {
+	Chart *chart.Chart
+	Weeks []model.WeekCount
+	Total int
+	TZ    string
+}
+
+// domainsPanel is the chart of the most common email domains, with links
+// to the contacts at each.
+type domainsPanel struct {
+	Chart   *chart.Chart
+	Domains []domainLink
+}
+
+type domainLink struct {
+	model.DomainCount
+	URL string
+}
+
+type changesPanel struct {
+	Contacts []model.Contact
+	TZ       string
+}
+
+// qualityItem is the number of contacts failing a data-quality check.
+type qualityItem struct {
+	Check string
+	Count int
+}
+
+type qualityPanel struct {
+	Total int
+	Items []qualityItem
+}
+
+// DashboardCreated returns the chart of contacts created per week, in
+// the user's timezone.
+func (h *Handler) DashboardCreated(w http.ResponseWriter, r *http.Request) {
+	tz := timezone(r)
+	now := time.Now().In(tmpl.Location(tz))
+	weeks, err := h.store.CreatedPerWeek(r.Context(), now, dashboardWeeks)
+	if err != nil {
+		h.serverError(w, r, "count contacts created per week", err)
+		return
+	}
+	data := createdPanel{Weeks: weeks, TZ: tz}
+	p := h.renderer.Bundle().Printer(h.locale(r))
+	points := make([]chart.Point, len(weeks))
+	for i, wk := range weeks {
+		points[i] = chart.Point{Label: p.MonthDay(wk.Start), Value: wk.Count}
+		data.Total += wk.Count
+	}
+	data.Chart = chart.Columns(points, chartWidth, chartHeight)
+	h.renderComponent(w, r, http.StatusOK, "dashboard-created", data)
+}
+
+// DashboardDomains returns the chart of the most common email domains.
+func (h *Handler) DashboardDomains(w http.ResponseWriter, r *http.Request) {
+	domains, err := h.store.TopEmailDomains(r.Context(), dashboardDomains)
+	if err != nil {
+		h.serverError(w, r, "count email domains", err)
+		return
+	}
+	var data domainsPanel
+	points := make([]chart.Point, len(domains))
+	for i, d := range domains {
+		points[i] = chart.Point{Label: d.Domain, Value: d.Count}
+		data.Domains = append(data.Domains, domainLink{
+			DomainCount: d,
+			URL:         filterURL(store.Condition{Field: store.FieldEmailDomain, Op: store.OpIs, Value: d.Domain}),
+		})
+	}
+	data.Chart = chart.Rows(points, chartWidth, chartRowHeight, chartLabelWidth)
+	h.renderComponent(w, r, http.StatusOK, "dashboard-domains", data)
+}
+
+// DashboardChanges returns the most recently created or updated contacts.
+func (h *Handler) DashboardChanges(w http.ResponseWriter, r *http.Request) {
+	list, err := h.store.RecentlyChanged(r.Context(), dashboardChanges)
+	if err != nil {
+		h.serverError(w, r, "list recent changes", err)
+		return
+	}
+	h.renderComponent(w, r, http.StatusOK, "dashboard-changes", changesPanel{Contacts: list, TZ: timezone(r)})
+}
+
+// DashboardQuality returns the number of contacts failing each enabled
+// data-quality check, as the report at /quality lists them.
+func (h *Handler) DashboardQuality(w http.ResponseWriter, r *http.Request) {
+	var report qualityData
+	if err := h.loadQuality(r, &report); err != nil {
+		h.serverError(w, r, "analyze contacts", err)
+		return
+	}
+	data := qualityPanel{Total: h.store.Count(r.Context())}
+	for _, g := range report.Groups {
+		data.Items = append(data.Items, qualityItem{Check: g.Check, Count: len(g.Issues)})
+	}
+	h.renderComponent(w, r, http.StatusOK, "dashboard-quality", data)
+}
+
+// filterURL returns the contacts list filtered by the condition.
+func filterURL(c store.Condition) string {
+	f := store.Filter{Groups: []store.Group{{Conditions: []store.Condition{c}}}}
+	return "/contacts?" + f.Values().Encode()
+}
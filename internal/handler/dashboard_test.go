@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/quality"
+)
+
+func getHTMX(mux http.Handler, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHome_Dashboard(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	page := rec.Body.String()
+	if !strings.Contains(page, "5 contacts") {
+		t.Error("expected the contact count on the home page")
+	}
+	for _, panel := range []string{"created", "domains", "changes", "quality"} {
+		if !strings.Contains(page, `hx-get="/dashboard/`+panel+`"`) {
+			t.Errorf("expected the %s panel loaded lazily", panel)
+		}
+	}
+	if strings.Contains(page, "<svg class=\"chart") {
+		t.Error("expected the charts left to their panels")
+	}
+}
+
+func TestDashboardCreated(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	rec := getHTMX(h.Routes(), "/dashboard/created")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `<svg class="chart chart-columns"`) || !strings.Contains(body, `role="img"`) {
+		t.Errorf("expected an accessible SVG chart:\n%s", body)
+	}
+	if strings.Count(body, "<rect ") != dashboardWeeks || strings.Count(body, "<tr><td>") != dashboardWeeks {
+		t.Errorf("expected a bar and a table row per week:\n%s", body)
+	}
+	if !strings.Contains(body, "5 contacts created in the last 12 weeks") {
+		t.Errorf("expected the seeded contacts counted this week:\n%s", body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard/created", nil)
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("Accept-Language", "es")
+	rec = httptest.NewRecorder()
+	h.Routes().ServeHTTP(rec, req)
+	now := time.Now().UTC()
+	monday := time.Date(now.Year(), now.Month(), now.Day()-(int(now.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+	es := h.renderer.Bundle().Printer("es")
+	body = rec.Body.String()
+	if !strings.Contains(body, ">"+es.MonthDay(monday)+"</text>") || !strings.Contains(body, ">"+es.Date(monday)+"</time>") {
+		t.Errorf("expected this week labeled in Spanish:\n%s", body)
+	}
+}
+
+func TestDashboardDomains(t *testing.T) {
+	h, s := setupTestHandler(t)
+	s.Create(context.Background(), model.Contact{FirstName: "Zoe", LastName: "Park", Email: "zoe@initech.test"})
+
+	body := getHTMX(h.Routes(), "/dashboard/domains").Body.String()
+	i, j := strings.Index(body, ">example.com</text>"), strings.Index(body, ">initech.test</text>")
+	if i < 0 || j < 0 || i > j {
+		t.Errorf("expected example.com charted before initech.test:\n%s", body)
+	}
+	if !strings.Contains(body, `href="/contacts?f.0.0.field=email_domain&amp;f.0.0.op=is&amp;f.0.0.value=initech.test"`) {
+		t.Errorf("expected a link to the domain's contacts:\n%s", body)
+	}
+}
+
+func TestDashboardChanges(t *testing.T) {
+	h, s := setupTestHandler(t)
+	ctx := context.Background()
+	c, _ := s.Get(ctx, "2")
+	c.Title = "Buyer"
+	s.Update(ctx, c)
+
+	body := getHTMX(h.Routes(), "/dashboard/changes").Body.String()
+	_, first, _ := strings.Cut(body, "<li>")
+	if !strings.Contains(first[:strings.Index(first, "</li>")], `href="/contacts/2"`) || !strings.Contains(body, "Updated") {
+		t.Errorf("expected Bob first as updated:\n%s", body)
+	}
+}
+
+func TestDashboardQuality(t *testing.T) {
+	h, s := setupTestHandler(t)
+	ctx := context.Background()
+	c, _ := s.Get(ctx, "5")
+	c.Phone = ""
+	s.Update(ctx, c)
+
+	body := getHTMX(h.Routes(), "/dashboard/quality").Body.String()
+	if !strings.Contains(body, `<a href="/quality#quality-missing_phone">Missing phone number</a>`) || !strings.Contains(body, "1 of 5") {
+		t.Errorf("expected a linked count of contacts without a phone:\n%s", body)
+	}
+
+	a, err := quality.New(quality.Options{Checks: []string{quality.NameCase}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.quality = a
+	body = getHTMX(h.Routes(), "/dashboard/quality").Body.String()
+	if strings.Contains(body, "Missing phone number") || !strings.Contains(body, "Names in all capitals or lowercase") {
+		t.Errorf("expected only the enabled checks:\n%s", body)
+	}
+}
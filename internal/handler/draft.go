@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// SaveDraft autosaves the contact form as the user's draft of the contact
+// being edited, or of a new contact, and returns the form's draft status.
+func (h *Handler) SaveDraft(w http.ResponseWriter, r *http.Request) {
+	if !parseContactForm(w, r) {
+		return
+	}
+	d := model.Draft{Owner: UserID(r.Context()), ContactID: r.PathValue("id"), Contact: h.contactFromForm(r)}
+	d, err := h.store.SaveDraft(r.Context(), d)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "save draft", err)
+		return
+	}
+	data := contactFormData{Contact: d.Contact, TZ: timezone(r), Draft: d, Restored: true}
+	h.renderComponent(w, r, http.StatusOK, "draft-status", data)
+}
+
+// DiscardDraft deletes the user's draft of a contact, or of a new contact,
+// and returns empty content for htmx swap.
+func (h *Handler) DiscardDraft(w http.ResponseWriter, r *http.Request) {
+	if err := h.store.DeleteDraft(r.Context(), UserID(r.Context()), r.PathValue("id")); err != nil {
+		h.serverError(w, r, "delete draft", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// renderDraftForm renders the contact form for c, offering to restore the
+// user's draft of it if there is one, or with the draft restored in its place when the
+// draft query parameter is "restore".
+func (h *Handler) renderDraftForm(w http.ResponseWriter, r *http.Request, c model.Contact) {
+	data := contactFormData{Contact: c}
+	d, err := h.store.GetDraft(r.Context(), UserID(r.Context()), c.ID)
+	switch {
+	case errors.Is(err, model.ErrNotFound):
+	case err != nil:
+		h.serverError(w, r, "get draft", err)
+		return
+	case r.URL.Query().Get("draft") == "restore":
+		data.Contact, data.Draft, data.Restored = d.Contact, d, true
+		data.Contact.PhotoID = c.PhotoID
+		data.Contact.CreatedAt, data.Contact.UpdatedAt = c.CreatedAt, c.UpdatedAt
+	default:
+		data.Draft = d
+	}
+	h.renderContactFormData(w, r, http.StatusOK, data)
+}
+
+// discardDraft deletes the user's draft of a contact just saved. Failing
+// to is only logged, as the contact was saved.
+func (h *Handler) discardDraft(ctx context.Context, contactID string) {
+	if err := h.store.DeleteDraft(ctx, UserID(ctx), contactID); err != nil {
+		slog.Warn("deleting draft", "contact", contactID, "error", err)
+	}
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestSaveDraft(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	rec := postForm(mux, "/contacts/2/draft", url.Values{"first_name": {"Robert"}, "last_name": {"Smith"}, "email": {"bob@example.com"}}, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if out := rec.Body.String(); !strings.Contains(out, `id="draft-status"`) || !strings.Contains(out, "Draft saved") {
+		t.Errorf("expected the draft status:\n%s", out)
+	}
+	if d, _ := s.GetDraft(context.Background(), "", "2"); d.Contact.FirstName != "Robert" {
+		t.Errorf("expected Bob's draft saved, got %+v", d)
+	}
+
+	// The edit form offers the draft, and shows it when restored.
+	page := getHTMX(mux, "/contacts/2/edit").Body.String()
+	if !strings.Contains(page, `id="draft-banner"`) || !strings.Contains(page, `href="/contacts/2/edit?draft=restore"`) || !strings.Contains(page, `value="Bob"`) {
+		t.Errorf("expected the saved contact with a restore banner:\n%s", page)
+	}
+	page = getHTMX(mux, "/contacts/2/edit?draft=restore").Body.String()
+	if strings.Contains(page, `id="draft-banner"`) || !strings.Contains(page, `value="Robert"`) {
+		t.Errorf("expected the draft restored:\n%s", page)
+	}
+
+	if rec := postForm(mux, "/contacts/99/draft", url.Values{"first_name": {"X"}}, true); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown contact: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestDraft_ClearedOnSave(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	ctx := context.Background()
+	form := url.Values{"first_name": {"Dana"}, "last_name": {"Scully"}, "email": {"dana@example.com"}}
+
+	postForm(mux, "/contacts/draft", form, true)
+	page := getHTMX(mux, "/contacts/new").Body.String()
+	if !strings.Contains(page, `href="/contacts/new?draft=restore"`) || !strings.Contains(page, `hx-post="/contacts/draft"`) {
+		t.Errorf("expected the new contact's draft offered:\n%s", page)
+	}
+
+	// A form that fails validation keeps the draft.
+	postForm(mux, "/contacts", url.Values{"first_name": {"Dana"}}, false)
+	if _, err := s.GetDraft(ctx, "", ""); err != nil {
+		t.Errorf("expected the draft kept, got %v", err)
+	}
+	if rec := postForm(mux, "/contacts", form, false); rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", rec.Code)
+	}
+	if _, err := s.GetDraft(ctx, "", ""); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected the draft cleared on create, got %v", err)
+	}
+
+	postForm(mux, "/contacts/1/draft", url.Values{"first_name": {"Alicia"}}, true)
+	postForm(mux, "/contacts/1", url.Values{"first_name": {"Alicia"}, "last_name": {"Johnson"}, "email": {"alice@example.com"}}, false)
+	if _, err := s.GetDraft(ctx, "", "1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected the draft cleared on update, got %v", err)
+	}
+}
+
+func TestDiscardDraft(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	postForm(mux, "/contacts/3/draft", url.Values{"first_name": {"Caroline"}}, true)
+	req := httptest.NewRequest(http.MethodDelete, "/contacts/3/draft", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("expected an empty 200, got %d %q", rec.Code, rec.Body)
+	}
+	if _, err := s.GetDraft(context.Background(), "", "3"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected the draft discarded, got %v", err)
+	}
+	if page := getHTMX(mux, "/contacts/3/edit").Body.String(); strings.Contains(page, `id="draft-banner"`) {
+		t.Error("expected no banner after discarding")
+	}
+}
+
+func TestDrafts_PerUser(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := UserMiddleware(h.Routes())
+
+	// newUser returns a request helper with its own user cookie.
+	newUser := func() func(method, path string, form url.Values) string {
+		var cookie *http.Cookie
+		return func(method, path string, form url.Values) string {
+			req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("HX-Request", "true")
+			if cookie != nil {
+				req.AddCookie(cookie)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if cookies := rec.Result().Cookies(); len(cookies) > 0 {
+				cookie = cookies[0]
+			}
+			return rec.Body.String()
+		}
+	}
+	alice, bob := newUser(), newUser()
+
+	alice(http.MethodPost, "/contacts/2/draft", url.Values{"first_name": {"Robert"}})
+	alice(http.MethodPost, "/contacts/draft", url.Values{"first_name": {"Dana"}})
+	if page := alice(http.MethodGet, "/contacts/2/edit", nil); !strings.Contains(page, `id="draft-banner"`) {
+		t.Error("expected alice offered her draft of Bob")
+	}
+	for _, path := range []string{"/contacts/2/edit", "/contacts/new"} {
+		if page := bob(http.MethodGet, path, nil); strings.Contains(page, `id="draft-banner"`) {
+			t.Errorf("%s: expected no banner for bob", path)
+		}
+	}
+
+	// Bob saving the contact leaves alice's draft, now out of date.
+	bob(http.MethodDelete, "/contacts/2/draft", nil)
+	bob(http.MethodPost, "/contacts/2", url.Values{"first_name": {"Bobby"}, "last_name": {"Smith"}, "email": {"bob@example.com"}})
+	page := alice(http.MethodGet, "/contacts/2/edit", nil)
+	if !strings.Contains(page, `id="draft-banner"`) || !strings.Contains(page, "The contact has changed since.") {
+		t.Errorf("expected alice's draft offered as out of date:\n%s", page)
+	}
+}
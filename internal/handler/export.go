@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// exportColumns are the CSV export's columns before the custom fields,
+// which follow under their keys.
+var exportColumns = []string{"first_name", "last_name", "email", "phone", "company", "title"}
+
+// ExportContacts downloads the contacts list as CSV, in the list's order.
+// It takes the list page's parameters, so with a list parameter it
+// exports the contacts a saved search finds.
+func (h *Handler) ExportContacts(w http.ResponseWriter, r *http.Request) {
+	v, err := h.listValues(r.Context(), r.URL.Query())
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "get saved search", err)
+		return
+	}
+	q, _ := listQuery(v, UserID(r.Context()))
+	contacts, err := h.store.List(r.Context(), q)
+	if err != nil {
+		h.serverError(w, r, "list contacts", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="contacts.csv"`)
+
+	cw := csv.NewWriter(w)
+	header := append([]string(nil), exportColumns...)
+	for _, f := range h.fields {
+		header = append(header, f.Key)
+	}
+	_ = cw.Write(header)
+	for _, c := range contacts {
+		row := []string{c.FirstName, c.LastName, c.Email, c.Phone, c.Company, c.Title}
+		for _, f := range h.fields {
+			row = append(row, c.Custom[f.Key])
+		}
+		for i := range row {
+			row[i] = csvCell(row[i])
+		}
+		_ = cw.Write(row)
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		slog.Warn("writing contacts export", "error", err)
+	}
+}
+
+// csvCell keeps spreadsheets from evaluating a value as a formula by
+// prefixing the characters that start one with a quote.
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestExportContacts_SavedSearch(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	ctx := context.Background()
+	saved, _ := s.SaveSearch(ctx, model.SavedSearch{Name: "Ro people", Query: "q=ro&sort=-name"})
+
+	c, _ := s.Get(ctx, "4")
+	c.Title = "=HYPERLINK(\"http://evil\")"
+	s.Update(ctx, c)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contacts/export?list="+saved.ID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="contacts.csv"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "first_name" {
+		t.Fatalf("expected a header and Carol and David, got %v", rows)
+	}
+	if rows[1][0] != "Carol" || rows[2][0] != "David" {
+		t.Errorf("expected the saved sort, got %v", rows[1:])
+	}
+	if title := rows[2][5]; !strings.HasPrefix(title, "'=") {
+		t.Errorf("expected the formula quoted, got %q", title)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contacts/export?list=999", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown saved search: expected 404, got %d", rec.Code)
+	}
+}
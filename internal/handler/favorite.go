@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// recentLimit is the number of recently viewed contacts the home page
+// lists.
+const recentLimit = 8
+
+// StarContact stars the contact, or unstars it when starred is "false",
+// and returns its star button.
+func (h *Handler) StarContact(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+
+	c.Starred = r.FormValue("starred") != "false"
+	if err := h.store.StarContact(r.Context(), UserID(r.Context()), c.ID, c.Starred); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "star contact", err)
+		return
+	}
+	slog.Info("contact starred", "id", c.ID, "starred", c.Starred)
+
+	if !isHTMX(r) {
+		http.Redirect(w, r, "/contacts/"+c.ID, http.StatusSeeOther)
+		return
+	}
+	h.renderComponent(w, r, http.StatusOK, "star-button", c)
+}
+
+// recordView adds the contact to the user's recently viewed list. Failing
+// to is logged rather than failing the page.
+func (h *Handler) recordView(r *http.Request, id string) {
+	if err := h.store.RecordView(r.Context(), UserID(r.Context()), id, time.Now()); err != nil {
+		slog.Warn("record view", "id", id, "error", err, "request_id", RequestID(r.Context()))
+	}
+}
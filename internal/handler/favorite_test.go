@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+func TestStarContact(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	rec := postForm(mux, "/contacts/3/star", url.Values{"starred": {"true"}}, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	out := rec.Body.String()
+	if !strings.Contains(out, `aria-pressed="true"`) || !strings.Contains(out, `name="starred" value="false"`) {
+		t.Errorf("expected a pressed star that unstars next:\n%s", out)
+	}
+
+	list, _ := s.List(context.Background(), store.Query{Starred: true})
+	if len(list) != 1 || list[0].ID != "3" {
+		t.Fatalf("expected only Carol starred, got %+v", list)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts?starred=true", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	page := rec.Body.String()
+	if !strings.Contains(page, `id="contact-3"`) || strings.Contains(page, `id="contact-1"`) {
+		t.Error("expected the list filtered to starred contacts")
+	}
+	if _, box, _ := strings.Cut(page, `name="starred"`); !strings.Contains(strings.SplitN(box, ">", 2)[0], "checked") {
+		t.Error("expected the starred checkbox checked")
+	}
+
+	rec = postForm(mux, "/contacts/3/star", url.Values{"starred": {"false"}}, false)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/contacts/3" {
+		t.Errorf("expected a redirect to the contact, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if list, _ := s.List(context.Background(), store.Query{Starred: true}); len(list) != 0 {
+		t.Error("expected Carol unstarred")
+	}
+
+	if rec := postForm(mux, "/contacts/99/star", nil, true); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown contact: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestHome_Favorites(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	postForm(mux, "/contacts/4/star", url.Values{"starred": {"true"}}, true)
+	for _, id := range []string{"3", "1", "3"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contacts/"+id, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	page := rec.Body.String()
+
+	starred, recent, ok := strings.Cut(page, "Recently Viewed")
+	if !ok {
+		t.Fatalf("expected the recently viewed panel:\n%s", page)
+	}
+	if !strings.Contains(starred, `href="/contacts/4"`) {
+		t.Error("expected David in the starred panel")
+	}
+	carol, alice := strings.Index(recent, `href="/contacts/3"`), strings.Index(recent, `href="/contacts/1"`)
+	if carol < 0 || alice < 0 || carol > alice {
+		t.Errorf("expected Carol, then Alice, recently viewed:\n%s", recent)
+	}
+	if strings.Count(recent, `href="/contacts/3"`) != 1 {
+		t.Error("expected Carol listed once")
+	}
+}
+
+func TestFavorites_PerUser(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := UserMiddleware(h.Routes())
+
+	// newUser returns a request helper with its own user cookie.
+	newUser := func() func(method, path string, form url.Values) string {
+		var cookie *http.Cookie
+		return func(method, path string, form url.Values) string {
+			req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if cookie != nil {
+				req.AddCookie(cookie)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if cookies := rec.Result().Cookies(); len(cookies) > 0 {
+				cookie = cookies[0]
+			}
+			return rec.Body.String()
+		}
+	}
+	alice, bob := newUser(), newUser()
+
+	alice(http.MethodPost, "/contacts/4/star", url.Values{"starred": {"true"}})
+	alice(http.MethodGet, "/contacts/3", nil)
+	bob(http.MethodPost, "/contacts/2/star", url.Values{"starred": {"true"}})
+
+	list := alice(http.MethodGet, "/contacts?starred=true", nil)
+	if !strings.Contains(list, `id="contact-4"`) || strings.Contains(list, `id="contact-2"`) {
+		t.Error("expected alice's starred list to hold only David")
+	}
+	list = bob(http.MethodGet, "/contacts?starred=true", nil)
+	if !strings.Contains(list, `id="contact-2"`) || strings.Contains(list, `id="contact-4"`) {
+		t.Error("expected bob's starred list to hold only Bob")
+	}
+	if page := bob(http.MethodGet, "/contacts/4", nil); strings.Contains(page, `aria-pressed="true"`) {
+		t.Error("expected David unstarred on bob's view of his page")
+	}
+
+	_, recent, _ := strings.Cut(alice(http.MethodGet, "/", nil), "Recently Viewed")
+	if !strings.Contains(recent, `href="/contacts/3"`) || strings.Contains(recent, `href="/contacts/4"`) {
+		t.Errorf("expected alice to have viewed only Carol:\n%s", recent)
+	}
+	_, recent, _ = strings.Cut(bob(http.MethodGet, "/", nil), "Recently Viewed")
+	if strings.Contains(recent, `href="/contacts/3"`) {
+		t.Errorf("expected bob not to see alice's views:\n%s", recent)
+	}
+}
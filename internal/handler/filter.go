@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+// filterData is the filter builder above the contacts table.
+type filterData struct {
+	Any    bool
+	Groups []filterGroupView
+
+	// Fields are the built-in fields conditions can test; custom fields
+	// follow them.
+	Fields []string
+
+	// Applied is the number of conditions the list is filtered by, and
+	// ClearQuery the list's query without them.
+	Applied    int
+	ClearQuery string
+}
+
+type filterGroupView struct {
+	Index      int
+	Any        bool
+	Conditions []conditionView
+}
+
+// conditionView is a row of the filter builder: a condition with the
+// operators its field offers and the input its value needs.
+type conditionView struct {
+	store.Condition
+	Group, Index int
+	Operators    []string
+
+	// InputType is the value input's type, or "" if the operator takes
+	// no value.
+	InputType string
+	Error     string
+}
+
+// filterView lays out f for the filter builder. A condition whose
+// operator doesn't suit its field, as after the field is changed, gets
+// the field's first operator.
+func (h *Handler) filterView(f store.Filter) filterData {
+	data := filterData{Any: f.Any, Fields: store.FilterFields}
+	for gi, g := range f.Groups {
+		group := filterGroupView{Index: gi, Any: g.Any}
+		for ci, c := range g.Conditions {
+			ops := store.Operators(c.Field)
+			if !slices.Contains(ops, c.Op) {
+				c.Op, c.Value = ops[0], ""
+			}
+			v := conditionView{Condition: c, Group: gi, Index: ci, Operators: ops}
+			switch {
+			case !store.TakesValue(c.Op):
+				v.Value = ""
+			case c.Op == store.OpBefore || c.Op == store.OpAfter:
+				v.InputType = "date"
+			case c.Op == store.OpWithinDays || c.Op == store.OpNotWithinDays:
+				v.InputType = "number"
+			default:
+				v.InputType = "text"
+			}
+			if c.Complete() {
+				v.Error = c.Validate(h.fields)
+			}
+			group.Conditions = append(group.Conditions, v)
+		}
+		data.Groups = append(data.Groups, group)
+	}
+	for _, g := range f.Usable(h.fields).Groups {
+		data.Applied += len(g.Conditions)
+	}
+	return data
+}
+
+// EditFilter returns the filter builder for the filter in the query,
+// after adding a condition to the group add (a new group if there is no
+// such group), removing the condition remove ("<group>.<condition>"), or
+// offering a changed field's operators.
+func (h *Handler) EditFilter(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	f := store.ParseFilter(v)
+
+	if g, err := strconv.Atoi(v.Get("add")); err == nil && g >= 0 && conditionCount(f) < store.MaxFilterConditions {
+		c := store.Condition{Field: store.FieldName, Op: store.OpContains}
+		if g < len(f.Groups) {
+			f.Groups[g].Conditions = append(f.Groups[g].Conditions, c)
+		} else {
+			f.Groups = append(f.Groups, store.Group{Conditions: []store.Condition{c}})
+		}
+	}
+	if gs, cs, ok := strings.Cut(v.Get("remove"), "."); ok {
+		g, errG := strconv.Atoi(gs)
+		c, errC := strconv.Atoi(cs)
+		if errG == nil && errC == nil && g >= 0 && g < len(f.Groups) && c >= 0 && c < len(f.Groups[g].Conditions) {
+			f.Groups[g].Conditions = slices.Delete(f.Groups[g].Conditions, c, c+1)
+			if len(f.Groups[g].Conditions) == 0 {
+				f.Groups = slices.Delete(f.Groups, g, g+1)
+			}
+		}
+	}
+
+	data := h.filterView(f)
+	data.ClearQuery = clearFilterQuery(v)
+	h.renderComponent(w, r, http.StatusOK, "filter-builder", data)
+}
+
+func conditionCount(f store.Filter) int {
+	n := 0
+	for _, g := range f.Groups {
+		n += len(g.Conditions)
+	}
+	return n
+}
+
+// clearFilterQuery returns the query string of the contacts list v
+// without its filter: "?" and the parameters, or "" if there are none.
+func clearFilterQuery(v url.Values) string {
+	kept := keptParams(v)
+	if len(kept) == 0 {
+		return ""
+	}
+	return "?" + kept.Encode()
+}
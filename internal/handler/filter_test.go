@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestListContacts_Filter(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	get := func(query string) string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contacts?"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		return rec.Body.String()
+	}
+
+	// Alice by name, or David by company.
+	body := get("f.match=any&f.0.0.field=name&f.0.0.op=contains&f.0.0.value=alice&f.1.0.field=company&f.1.0.op=is&f.1.0.value=initech")
+	for id, want := range map[string]bool{"1": true, "2": false, "3": false, "4": true, "5": false} {
+		if got := strings.Contains(body, `id="contact-`+id+`"`); got != want {
+			t.Errorf("contact %s listed = %v, want %v", id, got, want)
+		}
+	}
+	if !strings.Contains(body, `<span class="badge filter-count">2</span>`) {
+		t.Error("expected the number of applied conditions")
+	}
+	if !strings.Contains(body, `href="/contacts?f.0.0.field=name&amp;f.0.0.op=contains`) {
+		t.Error("expected sort links to keep the filter")
+	}
+	if !strings.Contains(body, `<a href="/contacts" class="btn btn-sm btn-secondary">Clear filters</a>`) {
+		t.Error("expected a link clearing the filter")
+	}
+
+	// Invalid conditions are shown with an error and not applied.
+	body = get("f.0.0.field=created&f.0.0.op=within_days&f.0.0.value=soon")
+	if !strings.Contains(body, "Enter a number of days") || !strings.Contains(body, `id="contact-5"`) {
+		t.Error("expected the invalid condition reported and ignored")
+	}
+	if strings.Contains(body, "filter-count") {
+		t.Error("expected no applied conditions")
+	}
+}
+
+func TestSearchContacts_Filter(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/search?q=o&f.0.0.field=title&f.0.0.op=not_empty", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	if !strings.Contains(body, `id="contact-3"`) || !strings.Contains(body, `id="contact-4"`) || strings.Contains(body, `id="contact-2"`) {
+		t.Errorf("expected only contacts with a title:\n%s", body)
+	}
+}
+
+func TestEditFilter(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	edit := func(query string) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/contacts/filter?"+query, nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		return rec.Body.String()
+	}
+
+	body := edit("add=0")
+	if !strings.Contains(body, `name="f.0.0.field"`) || !strings.Contains(body, `<option value="contains" selected>`) {
+		t.Errorf("expected a new condition:\n%s", body)
+	}
+
+	// Changing the field to a date offers date operators.
+	body = edit("f.0.0.field=created&f.0.0.op=contains&f.0.0.value=x&add=1")
+	if !strings.Contains(body, `<option value="within_days" selected>`) || !strings.Contains(body, `type="number" name="f.0.0.value" value=""`) {
+		t.Errorf("expected date operators:\n%s", body)
+	}
+	if !strings.Contains(body, `name="f.1.0.field"`) || !strings.Contains(body, `name="f.match"`) {
+		t.Error("expected a second group with a match selector")
+	}
+
+	// Removing renumbers the remaining conditions.
+	body = edit("f.0.3.field=phone&f.0.3.op=empty&f.0.7.field=email&f.0.7.op=contains&f.0.7.value=x&remove=0.0")
+	if strings.Contains(body, `value="phone" selected`) || !strings.Contains(body, `name="f.0.0.field"`) {
+		t.Errorf("expected only the email condition left:\n%s", body)
+	}
+	if !strings.Contains(body, `name="f.0.0.value" value="x"`) {
+		t.Error("expected the value kept")
+	}
+}
+
+func TestSaveSearch_Filter(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	form := url.Values{"name": {"No title"}, "f.0.4.field": {"title"}, "f.0.4.op": {"empty"}}
+	if rec := postForm(mux, "/searches", form, true); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	list, _ := s.ListSavedSearches(context.Background(), "")
+	if len(list) != 1 || list[0].Query != "f.0.0.field=title&f.0.0.op=empty" {
+		t.Fatalf("expected the filter saved, got %+v", list)
+	}
+
+	// A filter in the URL replaces the saved one.
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contacts?list="+list[0].ID+"&f.0.0.field=name&f.0.0.op=is&f.0.0.value=Eve+Davis", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, `id="contact-5"`) || strings.Contains(body, `id="contact-1"`) {
+		t.Error("expected only the URL's filter applied")
+	}
+}
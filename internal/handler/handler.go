@@ -4,7 +4,13 @@ import (
 	"embed"
 	"io/fs"
 	"net/http"
+	"net/url"
+	"sync"
+	"time"
 
+	"github.com/devaloi/htmxapp/internal/blob"
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/quality"
 	"github.com/devaloi/htmxapp/internal/store"
 	"github.com/devaloi/htmxapp/internal/tmpl"
 )
@@ -14,13 +20,120 @@ var staticFS embed.FS
 
 // Handler holds dependencies for HTTP handlers.
 type Handler struct {
-	store    store.ContactStore
-	renderer *tmpl.Renderer
+	store     store.Store
+	renderer  *tmpl.Renderer
+	static    fs.FS
+	nameOrder model.NameOrder
+	emails    model.EmailPolicy
+	fields    []model.FieldDef
+
+	// quality runs the data-quality report's checks.
+	quality quality.Analyzer
+
+	// calendarSecret names the iCalendar feed; empty disables it.
+	calendarSecret string
+
+	// blobs stores contact photos and attachments; nil disables uploads.
+	blobs blob.Store
+
+	// attachmentMax and attachmentQuota limit the size of an attachment
+	// and of all of a contact's attachments.
+	attachmentMax   int64
+	attachmentQuota int64
+
+	// blobMu serializes recording an attachment against its blob with
+	// deleting blobs no attachment uses, since identical files share one
+	// blob.
+	blobMu sync.Mutex
+}
+
+// Option configures optional Handler dependencies.
+type Option func(*Handler)
+
+// WithStaticFS serves static assets from fsys instead of the copy embedded
+// in the binary, so development mode can pick up edits without a rebuild.
+func WithStaticFS(fsys fs.FS) Option {
+	return func(h *Handler) {
+		h.static = fsys
+	}
+}
+
+// WithNameOrder sets the order name parts are read in when splitting a
+// pasted full name.
+func WithNameOrder(order model.NameOrder) Option {
+	return func(h *Handler) {
+		h.nameOrder = order
+	}
+}
+
+// WithEmailPolicy restricts the domains contact email addresses may use.
+func WithEmailPolicy(p model.EmailPolicy) Option {
+	return func(h *Handler) {
+		h.emails = p
+	}
+}
+
+// WithFields sets the custom field definitions contact forms read and
+// validate.
+func WithFields(defs []model.FieldDef) Option {
+	return func(h *Handler) {
+		h.fields = defs
+	}
+}
+
+// WithQuality sets the checks the data-quality report runs. Without it
+// the report runs every check, with no list of dead email domains.
+func WithQuality(a quality.Analyzer) Option {
+	return func(h *Handler) {
+		h.quality = a
+	}
+}
+
+// WithCalendarSecret serves the iCalendar feed of contacts' dates at
+// /calendar/<secret>.ics. Without a secret the feed is not served.
+func WithCalendarSecret(secret string) Option {
+	return func(h *Handler) {
+		h.calendarSecret = secret
+	}
+}
+
+// WithBlobs stores uploaded contact photos and attachments in b. Without
+// it the contact form has no photo field and contacts have no attachments.
+func WithBlobs(b blob.Store) Option {
+	return func(h *Handler) {
+		h.blobs = b
+	}
+}
+
+// WithAttachmentLimits sets the largest attachment accepted and the total
+// size of the attachments one contact may have, in bytes. Limits that
+// aren't positive keep their defaults.
+func WithAttachmentLimits(maxFile, quota int64) Option {
+	return func(h *Handler) {
+		if maxFile > 0 {
+			h.attachmentMax = maxFile
+		}
+		if quota > 0 {
+			h.attachmentQuota = quota
+		}
+	}
 }
 
 // New creates a Handler with the given store and renderer.
-func New(s store.ContactStore, r *tmpl.Renderer) *Handler {
-	return &Handler{store: s, renderer: r}
+func New(s store.Store, r *tmpl.Renderer, opts ...Option) *Handler {
+	h := &Handler{
+		store:           s,
+		renderer:        r,
+		attachmentMax:   DefaultAttachmentMaxBytes,
+		attachmentQuota: DefaultAttachmentQuota,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.static == nil {
+		h.static, _ = fs.Sub(staticFS, "static")
+	}
+	return h
 }
 
 // Routes returns an http.Handler with all routes registered.
@@ -28,22 +141,110 @@ func (h *Handler) Routes() http.Handler {
 	mux := http.NewServeMux()
 
 	// Static files
-	staticSub, _ := fs.Sub(staticFS, "static")
-	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticSub))))
+	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(h.static))))
 
 	// Pages
 	mux.HandleFunc("GET /{$}", h.Home)
+	mux.HandleFunc("GET /dashboard/created", h.DashboardCreated)
+	mux.HandleFunc("GET /dashboard/domains", h.DashboardDomains)
+	mux.HandleFunc("GET /dashboard/changes", h.DashboardChanges)
+	mux.HandleFunc("GET /dashboard/quality", h.DashboardQuality)
 	mux.HandleFunc("GET /contacts", h.ListContacts)
 	mux.HandleFunc("GET /contacts/new", h.NewContact)
 	mux.HandleFunc("POST /contacts", h.CreateContact)
+	mux.HandleFunc("POST /contacts/delete", h.DeleteContacts)
+	mux.HandleFunc("GET /contacts/export", h.ExportContacts)
+	mux.HandleFunc("POST /contacts/draft", h.SaveDraft)
+	mux.HandleFunc("DELETE /contacts/draft", h.DiscardDraft)
 	mux.HandleFunc("GET /contacts/search", h.SearchContacts)
+	mux.HandleFunc("GET /contacts/filter", h.EditFilter)
+	mux.HandleFunc("POST /contacts/parse-name", h.ParseName)
+	mux.HandleFunc("POST /contacts/suggest-company", h.SuggestCompany)
+	mux.HandleFunc("GET /contacts/{id}", h.ShowContact)
 	mux.HandleFunc("GET /contacts/{id}/edit", h.EditContact)
 	mux.HandleFunc("POST /contacts/{id}", h.UpdateContact)
 	mux.HandleFunc("DELETE /contacts/{id}", h.DeleteContact)
+	mux.HandleFunc("POST /contacts/{id}/draft", h.SaveDraft)
+	mux.HandleFunc("DELETE /contacts/{id}/draft", h.DiscardDraft)
+	mux.HandleFunc("POST /contacts/{id}/star", h.StarContact)
+	mux.HandleFunc("GET /contacts/{id}/photo/{size}", h.ContactPhoto)
+	mux.HandleFunc("POST /contacts/{id}/attachments", h.UploadAttachment)
+	mux.HandleFunc("GET /contacts/{id}/attachments/{aid}", h.DownloadAttachment)
+	mux.HandleFunc("DELETE /contacts/{id}/attachments/{aid}", h.DeleteAttachment)
+	mux.HandleFunc("POST /contacts/{id}/notes", h.AddNote)
+	mux.HandleFunc("GET /contacts/{id}/notes/{nid}", h.ShowNote)
+	mux.HandleFunc("GET /contacts/{id}/notes/{nid}/edit", h.EditNote)
+	mux.HandleFunc("POST /contacts/{id}/notes/{nid}", h.UpdateNote)
+	mux.HandleFunc("POST /contacts/{id}/notes/{nid}/pin", h.PinNote)
+	mux.HandleFunc("DELETE /contacts/{id}/notes/{nid}", h.DeleteNote)
+	mux.HandleFunc("POST /notes/preview", h.PreviewNote)
+	mux.HandleFunc("GET /contacts/{id}/relationships/picker", h.PickContact)
+	mux.HandleFunc("POST /contacts/{id}/relationships", h.AddRelationship)
+	mux.HandleFunc("DELETE /contacts/{id}/relationships/{rid}", h.DeleteRelationship)
+	mux.HandleFunc("GET /contacts/{id}/graph", h.RelationshipGraph)
+	mux.HandleFunc("GET /contacts/{id}/interactions/picker", h.PickParticipant)
+	mux.HandleFunc("POST /contacts/{id}/interactions", h.LogInteraction)
+	mux.HandleFunc("DELETE /contacts/{id}/interactions/{iid}", h.DeleteInteraction)
+	mux.HandleFunc("POST /contacts/{id}/reminders", h.AddReminder)
+	mux.HandleFunc("GET /palette", h.Palette)
+	mux.HandleFunc("GET /searches", h.SavedSearches)
+	mux.HandleFunc("POST /searches", h.SaveSearch)
+	mux.HandleFunc("DELETE /searches/{sid}", h.DeleteSavedSearch)
+	mux.HandleFunc("GET /trash", h.ListTrash)
+	mux.HandleFunc("POST /trash/{id}/restore", h.RestoreContact)
+	mux.HandleFunc("DELETE /trash/{id}", h.PurgeContact)
+	mux.HandleFunc("GET /quality", h.QualityReport)
+	mux.HandleFunc("POST /quality/fix", h.FixIssues)
+	mux.HandleFunc("GET /companies", h.ListCompanies)
+	mux.HandleFunc("GET /companies/new", h.NewCompany)
+	mux.HandleFunc("POST /companies", h.CreateCompany)
+	mux.HandleFunc("GET /companies/{id}", h.ShowCompany)
+	mux.HandleFunc("GET /companies/{id}/edit", h.EditCompany)
+	mux.HandleFunc("POST /companies/{id}", h.UpdateCompany)
+	mux.HandleFunc("DELETE /companies/{id}", h.DeleteCompany)
+	mux.HandleFunc("GET /reminders", h.ListReminders)
+	mux.HandleFunc("POST /reminders/{rid}/snooze", h.SnoozeReminder)
+	mux.HandleFunc("POST /reminders/{rid}/complete", h.CompleteReminder)
+	mux.HandleFunc("GET /notifications", h.ListNotifications)
+	mux.HandleFunc("GET /notifications/badge", h.NotificationBadge)
+	mux.HandleFunc("GET /calendar/{file}", h.CalendarFeed)
+	mux.HandleFunc("GET /locale/{lang}", h.SetLocale)
 
 	return mux
 }
 
+// timezone returns the IANA timezone name the browser reported in the tz
+// cookie, or "" if it hasn't reported one.
+func timezone(r *http.Request) string {
+	c, err := r.Cookie("tz")
+	if err != nil {
+		return ""
+	}
+	tz, err := url.QueryUnescape(c.Value)
+	if err != nil {
+		return ""
+	}
+	return tz
+}
+
 func isHTMX(r *http.Request) bool {
 	return r.Header.Get("HX-Request") == "true"
 }
+
+func isBoosted(r *http.Request) bool {
+	return isHTMX(r) && r.Header.Get("HX-Boosted") == "true"
+}
+
+// transferTimeout bounds requests that upload or download a file, which
+// can take longer than the server's read and write timeouts allow.
+const transferTimeout = 10 * time.Minute
+
+// extendDeadlines gives the request transferTimeout from now to read its
+// body and write its response, in place of the server's timeouts. Writers
+// that don't support deadlines, as in tests, are left alone.
+func extendDeadlines(w http.ResponseWriter) {
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(transferTimeout)
+	_ = rc.SetReadDeadline(deadline)
+	_ = rc.SetWriteDeadline(deadline)
+}
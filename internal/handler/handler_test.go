@@ -1,12 +1,17 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"slices"
 	"strings"
 	"testing"
+	"testing/fstest"
 
+	"github.com/devaloi/htmxapp/internal/model"
 	"github.com/devaloi/htmxapp/internal/store"
 	"github.com/devaloi/htmxapp/internal/tmpl"
 )
@@ -22,6 +27,61 @@ func setupTestHandler(t *testing.T) (*Handler, *store.Memory) {
 	return New(s, renderer), s
 }
 
+type failingStore struct {
+	store.Store
+}
+
+func (failingStore) List(context.Context, store.Query) ([]model.Contact, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func TestListContacts_StoreError(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	h.store = failingStore{h.store}
+	mux := RequestIDMiddleware(h.Routes())
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+	id := rec.Header().Get("X-Request-ID")
+	body := rec.Body.String()
+	if !strings.Contains(body, "<!DOCTYPE html>") {
+		t.Error("expected full error page")
+	}
+	if id == "" || !strings.Contains(body, id) {
+		t.Errorf("expected request ID %q in error page", id)
+	}
+}
+
+func TestSearchContacts_StoreErrorHTMX(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	h.store = failingStore{h.store}
+	mux := RequestIDMiddleware(h.Routes())
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/search?q=a", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("HX-Retarget"); got != "#alerts" {
+		t.Errorf("expected HX-Retarget #alerts, got %q", got)
+	}
+	body := rec.Body.String()
+	if strings.Contains(body, "<!DOCTYPE html>") {
+		t.Error("expected error fragment, got full page")
+	}
+	if id := rec.Header().Get("X-Request-ID"); !strings.Contains(body, id) {
+		t.Errorf("expected request ID %q in error fragment", id)
+	}
+}
+
 func TestHome(t *testing.T) {
 	h, _ := setupTestHandler(t)
 	mux := h.Routes()
@@ -55,6 +115,56 @@ func TestListContacts(t *testing.T) {
 	}
 }
 
+func TestListContacts_Boosted(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts?q=ali", nil)
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Boosted", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if strings.Contains(body, "<!DOCTYPE html>") || strings.Contains(body, "<nav>") {
+		t.Error("expected content fragment without layout")
+	}
+	if !strings.Contains(body, "<title>htmxapp — Contacts</title>") {
+		t.Error("expected title in fragment")
+	}
+	if !strings.Contains(body, "Alice") {
+		t.Error("expected contacts in fragment")
+	}
+	if got := rec.Header().Get("HX-Push-Url"); got != "/contacts?q=ali" {
+		t.Errorf("expected HX-Push-Url /contacts?q=ali, got %q", got)
+	}
+	if vary := rec.Header().Values("Vary"); !slices.Contains(vary, "HX-Request") {
+		t.Errorf("expected Vary: HX-Request, got %v", vary)
+	}
+}
+
+func TestListContacts_Vary(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if !strings.Contains(rec.Body.String(), "<!DOCTYPE html>") {
+		t.Error("expected full page for non-htmx request")
+	}
+	if vary := rec.Header().Values("Vary"); !slices.Contains(vary, "HX-Request") {
+		t.Errorf("expected Vary: HX-Request, got %v", vary)
+	}
+	if got := rec.Header().Get("HX-Push-Url"); got != "" {
+		t.Errorf("expected no HX-Push-Url, got %q", got)
+	}
+}
+
 func TestSearchContacts(t *testing.T) {
 	h, _ := setupTestHandler(t)
 	mux := h.Routes()
@@ -138,6 +248,212 @@ func TestCreateContact_ValidationError(t *testing.T) {
 	}
 }
 
+func TestCreateContact_BoostedValidationError(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	form := url.Values{"first_name": {"Frank"}}
+	req := httptest.NewRequest(http.MethodPost, "/contacts", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Boosted", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("HX-Push-Url"); got != "false" {
+		t.Errorf("expected HX-Push-Url false, got %q", got)
+	}
+	if strings.Contains(rec.Body.String(), "<!DOCTYPE html>") {
+		t.Error("expected form fragment without layout")
+	}
+}
+
+func TestCreateContact_NicknameOnly(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	form := url.Values{"nickname": {"Cher"}, "email": {"cher@example.com"}}
+	req := httptest.NewRequest(http.MethodPost, "/contacts", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303 redirect, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if s.Count(req.Context()) != 6 {
+		t.Errorf("expected 6 contacts after create, got %d", s.Count(req.Context()))
+	}
+}
+
+func TestCreateContact_DisplayNameEmail(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	form := url.Values{"first_name": {"Frank"}, "email": {"Frank Test <frank@example.com>"}}
+	req := httptest.NewRequest(http.MethodPost, "/contacts", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303 redirect, got %d", rec.Code)
+	}
+	contacts, _ := s.List(req.Context(), store.Query{Search: "frank"})
+	if len(contacts) != 1 || contacts[0].Email != "frank@example.com" {
+		t.Errorf("expected bare address stored, got %+v", contacts)
+	}
+}
+
+func TestCreateContact_EmailPolicy(t *testing.T) {
+	renderer, err := tmpl.New()
+	if err != nil {
+		t.Fatalf("tmpl.New: %v", err)
+	}
+	policy, err := model.NewEmailPolicy(nil, []string{"blocked.test"})
+	if err != nil {
+		t.Fatalf("NewEmailPolicy: %v", err)
+	}
+	mux := New(store.NewMemory(), renderer, WithEmailPolicy(policy)).Routes()
+
+	form := url.Values{"first_name": {"Frank"}, "email": {"frank@mail.blocked.test"}}
+	req := httptest.NewRequest(http.MethodPost, "/contacts", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "Email addresses at mail.blocked.test are not allowed") {
+		t.Errorf("expected domain error in body:\n%s", body)
+	}
+}
+
+func TestCreateContact_CustomFields(t *testing.T) {
+	fields := []model.FieldDef{
+		{Key: "account_id", Label: "Account ID", Type: model.FieldText, Required: true},
+		{Key: "seats", Label: "Seats", Type: model.FieldNumber, List: true},
+	}
+	renderer, err := tmpl.NewWithOptions(tmpl.Options{Fields: fields})
+	if err != nil {
+		t.Fatalf("tmpl.NewWithOptions: %v", err)
+	}
+	s := store.NewMemory(store.WithFields(fields))
+	mux := New(s, renderer, WithFields(fields)).Routes()
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/contacts", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(url.Values{"first_name": {"Frank"}, "email": {"frank@example.com"}, "custom.seats": {"many"}})
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	for _, want := range []string{"Account ID is required", "Seats must be a number", `value="many"`} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("expected %q in body", want)
+		}
+	}
+
+	rec = post(url.Values{"first_name": {"Frank"}, "email": {"frank@example.com"}, "custom.account_id": {"A-1"}, "custom.seats": {"12.0"}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303 redirect, got %d", rec.Code)
+	}
+	c, err := s.Get(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if c.Custom["account_id"] != "A-1" || c.Custom["seats"] != "12" {
+		t.Errorf("unexpected custom values: %v", c.Custom)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts?q=A-1&sort=-custom.seats", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	if !strings.Contains(body, "<td>12</td>") {
+		t.Errorf("expected seats column in list:\n%s", body)
+	}
+	if !strings.Contains(body, `aria-sort="descending"`) || !strings.Contains(body, `href="/contacts?q=A-1&amp;sort=custom.seats"`) {
+		t.Errorf("expected descending seats header toggling to ascending:\n%s", body)
+	}
+}
+
+func TestShowContact(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/1", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "<title>htmxapp — Alice Johnson</title>") || !strings.Contains(body, "mailto:alice@example.com") {
+		t.Errorf("unexpected detail page:\n%s", body)
+	}
+	if !strings.Contains(body, "<dd>Apr 12, 1985</dd>") {
+		t.Error("expected Alice's birthday")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/contacts/999", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestListContacts_Sort(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/search?sort=-name", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if smith, davis := strings.Index(body, "Bob Smith"), strings.Index(body, "Eve Davis"); smith < 0 || davis < 0 || smith > davis {
+		t.Errorf("expected descending name order:\n%s", body)
+	}
+}
+
+func TestParseName(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	form := url.Values{"full_name": {"Dr. Jane Q. Doe Jr."}}
+	req := httptest.NewRequest(http.MethodPost, "/contacts/parse-name", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if strings.Contains(body, "<html") {
+		t.Error("expected name-fields fragment, got full page")
+	}
+	for _, want := range []string{`value="Dr."`, `value="Jane"`, `value="Q."`, `value="Doe"`, `value="Jr."`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in response:\n%s", want, body)
+		}
+	}
+}
+
 func TestCreateContact_DuplicateEmail(t *testing.T) {
 	h, _ := setupTestHandler(t)
 	mux := h.Routes()
@@ -171,6 +487,9 @@ func TestEditContact(t *testing.T) {
 	if !strings.Contains(rec.Body.String(), "Edit Contact") {
 		t.Error("expected edit form")
 	}
+	if !strings.Contains(rec.Body.String(), "updated <time") {
+		t.Error("expected timestamps on edit form")
+	}
 }
 
 func TestEditContact_NotFound(t *testing.T) {
@@ -287,3 +606,124 @@ func TestStaticFiles(t *testing.T) {
 		t.Errorf("expected css content type, got %s", ct)
 	}
 }
+
+func TestStaticFiles_CustomFS(t *testing.T) {
+	renderer, err := tmpl.New()
+	if err != nil {
+		t.Fatalf("tmpl.New: %v", err)
+	}
+	static := fstest.MapFS{"css/style.css": {Data: []byte("body { color: red; }")}}
+	mux := New(store.NewMemory(), renderer, WithStaticFS(static)).Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/static/css/style.css", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Body.String(); got != "body { color: red; }" {
+		t.Errorf("expected custom stylesheet, got %q", got)
+	}
+}
+
+func TestLocale_AcceptLanguage(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts", nil)
+	req.Header.Set("Accept-Language", "es-MX,es;q=0.9,en;q=0.5")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `<html lang="es">`) || !strings.Contains(body, "Nuevo contacto") {
+		t.Error("expected Spanish contacts page")
+	}
+	if vary := rec.Header().Values("Vary"); !slices.Contains(vary, "Accept-Language") {
+		t.Errorf("expected Vary: Accept-Language, got %v", vary)
+	}
+}
+
+func TestLocale_CookieOverridesAcceptLanguage(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "es")
+	req.AddCookie(&http.Cookie{Name: "lang", Value: "en"})
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if !strings.Contains(rec.Body.String(), "View Contacts") {
+		t.Error("expected English page from cookie preference")
+	}
+}
+
+func TestSetLocale(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/locale/es", nil)
+	req.Header.Set("Referer", "http://evil.example/contacts?q=al")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusSeeOther {
+		t.Errorf("expected 303, got %d", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/contacts?q=al" {
+		t.Errorf("expected local redirect, got %q", loc)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "lang" || cookies[0].Value != "es" {
+		t.Errorf("expected lang=es cookie, got %v", cookies)
+	}
+
+	for _, ref := range []string{"https://example.com//evil.example/x", `https://example.com/\evil.example/x`} {
+		req = httptest.NewRequest(http.MethodGet, "/locale/es", nil)
+		req.Header.Set("Referer", ref)
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if loc := rec.Header().Get("Location"); loc != "/" {
+			t.Errorf("Referer %q: expected redirect to /, got %q", ref, loc)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/locale/xx", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unsupported locale, got %d", rec.Code)
+	}
+}
+
+func TestCreateContact_TranslatedErrors(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	form := url.Values{
+		"first_name": {"Dupe"},
+		"last_name":  {"User"},
+		"email":      {"alice@example.com"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/contacts", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "lang", Value: "es"})
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if !strings.Contains(rec.Body.String(), "Ya existe un contacto con este correo electrónico") {
+		t.Error("expected translated duplicate email error")
+	}
+
+	form.Set("email", "not-an-email")
+	req = httptest.NewRequest(http.MethodPost, "/contacts", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if !strings.Contains(rec.Body.String(), "Invalid email address: not-an-email") {
+		t.Error("expected English invalid email error with the address")
+	}
+}
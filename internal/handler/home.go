@@ -1,10 +1,52 @@
 package handler
 
-import "net/http"
+import (
+	"net/http"
+	"time"
 
-// Home renders the landing page.
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/store"
+	"github.com/devaloi/htmxapp/internal/tmpl"
+)
+
+type homeData struct {
+	// Total is the number of contacts. The dashboard's other panels are
+	// loaded separately.
+	Total int
+
+	// Starred and Recent are the starred and recently viewed contacts.
+	Starred []model.Contact
+	Recent  []model.ContactView
+
+	// Upcoming lists contacts' occasions from today to the end of the
+	// month in the user's timezone.
+	Upcoming    []upcomingOccasion
+	CalendarURL string
+	TZ          string
+}
+
+// Home renders the dashboard: the number of contacts, panels of
+// statistics loaded with htmx once the page is shown, starred and recently
+// viewed contacts, and the month's upcoming birthdays, anniversaries and
+// custom dates.
 func (h *Handler) Home(w http.ResponseWriter, r *http.Request) {
-	if err := h.renderer.RenderPage(w, "home", nil); err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	data := homeData{Total: h.store.Count(r.Context()), CalendarURL: h.calendarURL(r), TZ: timezone(r)}
+	now := time.Now().In(tmpl.Location(data.TZ))
+	endOfMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+
+	var err error
+	if data.Upcoming, err = h.upcomingOccasions(r.Context(), now, endOfMonth); err != nil {
+		h.serverError(w, r, "list upcoming dates", err)
+		return
+	}
+	user := UserID(r.Context())
+	if data.Starred, err = h.store.List(r.Context(), store.Query{User: user, Starred: true, Sort: store.SortName}); err != nil {
+		h.serverError(w, r, "list starred contacts", err)
+		return
+	}
+	if data.Recent, err = h.store.RecentlyViewed(r.Context(), user, recentLimit); err != nil {
+		h.serverError(w, r, "list recently viewed contacts", err)
+		return
 	}
+	h.renderPage(w, r, http.StatusOK, "home", data)
 }
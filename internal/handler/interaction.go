@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/tmpl"
+)
+
+// dateTimeLayout is the value format of an HTML datetime-local input.
+const dateTimeLayout = "2006-01-02T15:04"
+
+// interactionView is an interaction as seen from the contact being shown.
+type interactionView struct {
+	ID       string
+	Type     string
+	At       time.Time
+	Duration time.Duration
+	Others   []model.Contact
+	Body     string
+}
+
+// Minutes returns the interaction's duration in whole minutes.
+func (v interactionView) Minutes() int {
+	return int(v.Duration / time.Minute)
+}
+
+// interactionForm holds the quick-log form's values as submitted, so the
+// form can be shown again with validation errors.
+type interactionForm struct {
+	Type    string
+	At      string
+	Minutes string
+	Body    string
+}
+
+// loadInteractions fills in the contact page's timeline. An empty quick-log
+// form defaults to a call happening now in the user's timezone.
+func (h *Handler) loadInteractions(ctx context.Context, data *contactData) error {
+	list, err := h.store.ListInteractions(ctx, data.Contact.ID)
+	if err != nil {
+		return err
+	}
+	data.Interactions = data.Interactions[:0]
+	for _, i := range list {
+		v := interactionView{ID: i.ID, Type: i.Type, At: i.At, Duration: i.Duration, Body: i.Body}
+		for _, id := range i.ContactIDs {
+			if id == data.Contact.ID {
+				continue
+			}
+			other, err := h.store.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			v.Others = append(v.Others, other)
+		}
+		data.Interactions = append(data.Interactions, v)
+	}
+	data.InteractionTypes = model.InteractionTypes
+	if data.Log == (interactionForm{}) {
+		data.Log = interactionForm{
+			Type: model.InteractionTypes[0],
+			At:   time.Now().In(tmpl.Location(data.TZ)).Format(dateTimeLayout),
+		}
+	}
+	return nil
+}
+
+// PickParticipant returns contacts matching the quick-log form's search,
+// other than the contact being shown, as checkboxes for an htmx swap.
+func (h *Handler) PickParticipant(w http.ResponseWriter, r *http.Request) {
+	h.pickContacts(w, r, pickerData{Name: "participant", Multiple: true})
+}
+
+// LogInteraction records an interaction with the contact and any other
+// participants, and returns the updated timeline.
+func (h *Handler) LogInteraction(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+
+	tz := timezone(r)
+	form := interactionForm{
+		Type:    r.FormValue("type"),
+		At:      r.FormValue("at"),
+		Minutes: strings.TrimSpace(r.FormValue("minutes")),
+		Body:    strings.TrimSpace(r.FormValue("body")),
+	}
+	i := model.Interaction{
+		Type:       form.Type,
+		ContactIDs: append([]string{c.ID}, r.Form["participant"]...),
+		Body:       form.Body,
+	}
+
+	parseErrs := make(map[string]string)
+	if form.At != "" {
+		at, err := time.ParseInLocation(dateTimeLayout, form.At, tmpl.Location(tz))
+		if err != nil {
+			parseErrs["At"] = model.CodeInvalidDate
+		}
+		i.At = at
+	}
+	if form.Minutes != "" {
+		n, err := strconv.Atoi(form.Minutes)
+		if err != nil {
+			n = -1
+		}
+		i.Duration = time.Duration(n) * time.Minute
+	}
+
+	errs := i.Validate()
+	for field, code := range parseErrs {
+		errs[field] = code
+	}
+	if len(errs) == 0 {
+		_, err := h.store.LogInteraction(r.Context(), i)
+		switch {
+		case errors.Is(err, model.ErrNotFound):
+			errs["ContactIDs"] = model.CodeUnknownContact
+		case err != nil:
+			h.serverError(w, r, "log interaction", err)
+			return
+		default:
+			slog.Info("interaction logged", "contact", c.ID, "type", i.Type, "participants", len(i.ContactIDs))
+			form = interactionForm{}
+		}
+	}
+
+	status := http.StatusOK
+	if len(errs) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	h.renderInteractions(w, r, status, form, errs)
+}
+
+// DeleteInteraction removes an interaction the contact took part in and
+// returns the updated timeline.
+func (h *Handler) DeleteInteraction(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := h.store.ListInteractions(r.Context(), c.ID)
+	if err != nil {
+		h.serverError(w, r, "list interactions", err)
+		return
+	}
+	iid := r.PathValue("iid")
+	found := false
+	for _, i := range list {
+		found = found || i.ID == iid
+	}
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := h.store.DeleteInteraction(r.Context(), iid); err != nil && !errors.Is(err, model.ErrNotFound) {
+		h.serverError(w, r, "delete interaction", err)
+		return
+	}
+	slog.Info("interaction deleted", "id", iid)
+
+	h.renderInteractions(w, r, http.StatusOK, interactionForm{}, nil)
+}
+
+// renderInteractions answers a timeline change: htmx requests get the
+// timeline and the contact's last-contacted time, others are sent back to
+// the contact page.
+func (h *Handler) renderInteractions(w http.ResponseWriter, r *http.Request, status int, form interactionForm, errs map[string]string) {
+	// Reload the contact for its updated last-contacted time.
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+	if !isHTMX(r) && status == http.StatusOK {
+		http.Redirect(w, r, "/contacts/"+c.ID, http.StatusSeeOther)
+		return
+	}
+
+	data := contactData{Contact: c, TZ: timezone(r), Log: form, LogErrors: errs}
+	if !isHTMX(r) {
+		if err := h.loadContactPage(r.Context(), &data, defaultGraphHops); err != nil {
+			h.serverError(w, r, "load contact", err)
+			return
+		}
+		h.renderPage(w, r, status, "contact", data)
+		return
+	}
+	if err := h.loadInteractions(r.Context(), &data); err != nil {
+		h.serverError(w, r, "load interactions", err)
+		return
+	}
+	h.renderPartial(w, r, status, "interactions-updated", data)
+}
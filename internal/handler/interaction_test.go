@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShowContact_Interactions(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/1", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"Quarterly check-in.", "Team planning.", "45 minutes", "3 days ago", `name="at"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in contact page", want)
+		}
+	}
+	if i, j := strings.Index(body, "Quarterly"), strings.Index(body, "Team planning"); i > j {
+		t.Error("expected newest interaction first")
+	}
+}
+
+func TestLogInteraction_HTMX(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	at := time.Now().Add(-time.Hour).UTC().Format(dateTimeLayout)
+	form := url.Values{"type": {"meeting"}, "at": {at}, "minutes": {"30"}, "participant": {"3"}, "body": {"Lunch"}}
+	rec := postForm(mux, "/contacts/4/interactions", form, true)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Lunch") || !strings.Contains(body, "Carol Williams") || !strings.Contains(body, "30 minutes") {
+		t.Errorf("expected new interaction in timeline:\n%s", body)
+	}
+	if !strings.Contains(body, `id="last-contacted" hx-swap-oob="true"`) || !strings.Contains(body, "1 hour ago") {
+		t.Errorf("expected last contacted swapped out of band:\n%s", body)
+	}
+
+	// The interaction shows on every participant's timeline.
+	list, _ := s.ListInteractions(context.Background(), "3")
+	if len(list) != 2 || list[0].Body != "Lunch" {
+		t.Errorf("expected Carol's timeline to include the meeting, got %+v", list)
+	}
+}
+
+func TestLogInteraction_Redirect(t *testing.T) {
+	h, _ := setupTestHandler(t)
+
+	form := url.Values{"type": {"note"}, "at": {"2020-01-02T09:30"}, "body": {"Met at a conference"}}
+	rec := postForm(h.Routes(), "/contacts/2/interactions", form, false)
+
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/contacts/2" {
+		t.Errorf("expected redirect to /contacts/2, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestLogInteraction_ValidationError(t *testing.T) {
+	tests := []struct {
+		name string
+		form url.Values
+		want string
+	}{
+		{"future", url.Values{"type": {"call"}, "at": {"2999-01-01T10:00"}}, "in the future"},
+		{"bad time", url.Values{"type": {"call"}, "at": {"yesterday"}}, "Enter a valid date and time"},
+		{"duration", url.Values{"type": {"call"}, "at": {"2020-01-01T10:00"}, "minutes": {"-5"}}, "Enter a whole number of minutes"},
+		{"empty note", url.Values{"type": {"note"}, "at": {"2020-01-01T10:00"}}, "Notes need some text"},
+		{"unknown participant", url.Values{"type": {"call"}, "at": {"2020-01-01T10:00"}, "participant": {"999"}}, "A participant no longer exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := setupTestHandler(t)
+			rec := postForm(h.Routes(), "/contacts/1/interactions", tt.form, true)
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected 422, got %d", rec.Code)
+			}
+			body := rec.Body.String()
+			if !strings.Contains(body, tt.want) {
+				t.Errorf("expected %q in response:\n%s", tt.want, body)
+			}
+			if at := tt.form.Get("at"); !strings.Contains(body, `value="`+at+`"`) {
+				t.Errorf("expected submitted time %q kept in the form", at)
+			}
+		})
+	}
+}
+
+func TestDeleteInteraction(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	// Interaction 3 is Carol's email, which Alice wasn't part of.
+	req := httptest.NewRequest(http.MethodDelete, "/contacts/1/interactions/3", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another contact's interaction, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/contacts/3/interactions/3", nil)
+	req.Header.Set("HX-Request", "true")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "No interactions logged yet.") {
+		t.Error("expected empty timeline")
+	}
+	if c, _ := s.Get(context.Background(), "3"); !c.LastContacted.IsZero() {
+		t.Errorf("expected Carol never contacted, got %v", c.LastContacted)
+	}
+}
+
+func TestPickParticipant(t *testing.T) {
+	h, _ := setupTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/1/interactions/picker?q=e", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	h.Routes().ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `type="checkbox" name="participant"`) {
+		t.Errorf("expected participant checkboxes:\n%s", body)
+	}
+}
+
+func TestListContacts_Contacted(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	tests := []struct {
+		filter string
+		want   []string
+		not    []string
+	}{
+		{"7d", []string{"Alice Johnson"}, []string{"Bob Smith", "Carol Williams", "David Brown"}},
+		{"over-30d", []string{"Carol Williams", "David Brown"}, []string{"Alice Johnson", "Bob Smith"}},
+		{"never", []string{"David Brown"}, []string{"Carol Williams"}},
+		{"bogus", []string{"Alice Johnson", "David Brown"}, nil},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/contacts/search?contacted="+tt.filter, nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		body := rec.Body.String()
+		for _, name := range tt.want {
+			if !strings.Contains(body, name) {
+				t.Errorf("%s: expected %s", tt.filter, name)
+			}
+		}
+		for _, name := range tt.not {
+			if strings.Contains(body, name) {
+				t.Errorf("%s: unexpected %s", tt.filter, name)
+			}
+		}
+	}
+}
+
+func TestListContacts_SortLastContacted(t *testing.T) {
+	h, _ := setupTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts?sort=-last_contacted", nil)
+	rec := httptest.NewRecorder()
+	h.Routes().ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	alice, carol, david := strings.Index(body, "Alice Johnson"), strings.Index(body, "Carol Williams"), strings.Index(body, "David Brown")
+	if alice > carol || carol > david {
+		t.Error("expected most recently contacted first and never contacted last")
+	}
+	if !strings.Contains(body, `aria-sort="descending"`) {
+		t.Error("expected last contacted column marked as sorted")
+	}
+}
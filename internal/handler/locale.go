@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/i18n"
+	"github.com/devaloi/htmxapp/internal/tmpl"
+)
+
+// localeCookie stores the language the user picked in the switcher.
+const localeCookie = "lang"
+
+// locale negotiates the locale for a request. An explicit choice stored in
+// the cookie wins over the browser's Accept-Language preferences.
+func (h *Handler) locale(r *http.Request) string {
+	bundle := h.renderer.Bundle()
+	if c, err := r.Cookie(localeCookie); err == nil && bundle.Catalog(c.Value) != nil {
+		return c.Value
+	}
+	return bundle.Match(i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+}
+
+// view returns the renderer for the request's locale.
+func (h *Handler) view(r *http.Request) *tmpl.Renderer {
+	return h.renderer.In(h.locale(r))
+}
+
+// SetLocale stores the chosen language and sends the user back to the page
+// they came from.
+func (h *Handler) SetLocale(w http.ResponseWriter, r *http.Request) {
+	lang := r.PathValue("lang")
+	if h.renderer.Bundle().Catalog(lang) == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     localeCookie,
+		Value:    lang,
+		Path:     "/",
+		MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, backTo(r), http.StatusSeeOther)
+}
+
+// backTo returns the local path of the referring page, or "/" if there is
+// none. Only the path and query are kept so the redirect can't leave the
+// site; paths starting "//" or "/\" are refused because browsers read them
+// as another host.
+func backTo(r *http.Request) string {
+	ref, err := url.Parse(r.Referer())
+	if err != nil || ref.Path == "" || ref.Path[0] != '/' ||
+		strings.HasPrefix(ref.Path, "//") || strings.HasPrefix(ref.Path, "/\\") {
+		return "/"
+	}
+	back := url.URL{Path: ref.Path, RawQuery: ref.RawQuery}
+	return back.String()
+}
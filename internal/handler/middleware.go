@@ -11,7 +11,15 @@ import (
 
 type contextKey string
 
-const requestIDKey contextKey = "request_id"
+const (
+	requestIDKey contextKey = "request_id"
+	userIDKey    contextKey = "user_id"
+)
+
+// userCookie holds the random ID that tells one browser's saved searches,
+// stars and drafts apart from another's. It identifies, it doesn't
+// authenticate: the app has no accounts.
+const userCookie = "uid"
 
 // RequestID returns the request ID from the context.
 func RequestID(ctx context.Context) string {
@@ -60,6 +68,54 @@ func RequestIDMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// UserID returns the ID of the browser making the request, or "" if the
+// request didn't pass through UserMiddleware.
+func UserID(ctx context.Context) string {
+	if id, ok := ctx.Value(userIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// UserMiddleware identifies the browser making each request by an opaque
+// random cookie, setting one on its first visit.
+func UserMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var id string
+		if c, err := r.Cookie(userCookie); err == nil && validUserID(c.Value) {
+			id = c.Value
+		} else {
+			id = generateUserID()
+			http.SetCookie(w, &http.Cookie{
+				Name:     userCookie,
+				Value:    id,
+				Path:     "/",
+				MaxAge:   int((10 * 365 * 24 * time.Hour).Seconds()),
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		ctx := context.WithValue(r.Context(), userIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// userIDBytes is the length of a user ID before hex encoding.
+const userIDBytes = 16
+
+func generateUserID() string {
+	b := make([]byte, userIDBytes)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validUserID reports whether id looks like one generateUserID made, so a
+// forged cookie can't smuggle arbitrary text into store keys.
+func validUserID(id string) bool {
+	b, err := hex.DecodeString(id)
+	return err == nil && len(b) == userIDBytes && id == hex.EncodeToString(b)
+}
+
 func generateID() string {
 	b := make([]byte, 8)
 	_, _ = rand.Read(b)
@@ -86,3 +142,9 @@ func (sw *statusWriter) Write(b []byte) (int, error) {
 	}
 	return sw.ResponseWriter.Write(b)
 }
+
+// Unwrap lets http.ResponseController reach the underlying writer, which
+// streaming responses need for flushing and deadline control.
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -59,3 +59,40 @@ func TestRequestIDMiddleware(t *testing.T) {
 		t.Errorf("header ID %q != context ID %q", headerID, capturedID)
 	}
 }
+
+func TestUserMiddleware(t *testing.T) {
+	var captured string
+	handler := UserMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		captured = UserID(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != userCookie {
+		t.Fatalf("expected a %s cookie on the first visit, got %v", userCookie, cookies)
+	}
+	if captured == "" || captured != cookies[0].Value {
+		t.Errorf("expected context user %q to match the cookie %q", captured, cookies[0].Value)
+	}
+
+	first := captured
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if captured != first {
+		t.Errorf("expected the cookie's user %q, got %q", first, captured)
+	}
+	if len(rec.Result().Cookies()) != 0 {
+		t.Error("expected no new cookie for a known user")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: userCookie, Value: "../../etc"})
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if captured == "../../etc" || captured == first {
+		t.Errorf("expected a forged cookie to be replaced, got %q", captured)
+	}
+}
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// noteView is a note on the contact page, shown rendered or, while it is
+// being edited, in its editor.
+type noteView struct {
+	Note    model.Note
+	TZ      string
+	Editing bool
+	Errors  map[string]string
+}
+
+// loadNotes fills in the contact page's notes: the pinned one, shown at
+// the top of the page, and the rest. The note with ID editing, if any, is
+// shown in its editor.
+func (h *Handler) loadNotes(ctx context.Context, data *contactData, editing string) error {
+	list, err := h.store.ListNotes(ctx, data.Contact.ID)
+	if err != nil {
+		return err
+	}
+	data.Notes = data.Notes[:0]
+	data.PinnedNote = nil
+	for _, n := range list {
+		v := noteView{Note: n, TZ: data.TZ, Editing: n.ID == editing}
+		if n.Pinned {
+			data.PinnedNote = &v
+			continue
+		}
+		data.Notes = append(data.Notes, v)
+	}
+	return nil
+}
+
+// AddNote adds a note about the contact and returns the updated notes.
+func (h *Handler) AddNote(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+
+	n := model.Note{ContactID: c.ID, Body: r.FormValue("body"), Pinned: r.FormValue("pinned") == "true"}
+	errs := n.Validate()
+	if len(errs) == 0 {
+		added, err := h.store.AddNote(r.Context(), n)
+		if err != nil {
+			h.serverError(w, r, "add note", err)
+			return
+		}
+		slog.Info("note added", "id", added.ID, "contact", c.ID)
+		n.Body = ""
+	}
+
+	status := http.StatusOK
+	if len(errs) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	h.renderNotes(w, r, status, c, n.Body, errs)
+}
+
+// getNote looks up the note in the path, which must be about the contact
+// in the path, writing a 404 otherwise.
+func (h *Handler) getNote(w http.ResponseWriter, r *http.Request) (model.Note, bool) {
+	n, err := h.store.GetNote(r.Context(), r.PathValue("nid"))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return model.Note{}, false
+		}
+		h.serverError(w, r, "get note", err)
+		return model.Note{}, false
+	}
+	if n.ContactID != r.PathValue("id") {
+		http.NotFound(w, r)
+		return model.Note{}, false
+	}
+	return n, true
+}
+
+// ShowNote returns a rendered note, so cancelling an edit puts it back.
+func (h *Handler) ShowNote(w http.ResponseWriter, r *http.Request) {
+	n, ok := h.getNote(w, r)
+	if !ok {
+		return
+	}
+	if !isHTMX(r) {
+		http.Redirect(w, r, "/contacts/"+n.ContactID, http.StatusSeeOther)
+		return
+	}
+	h.renderComponent(w, r, http.StatusOK, "note", noteView{Note: n, TZ: timezone(r)})
+}
+
+// EditNote returns a note's editor in its place, or the contact page with
+// the editor open for requests outside htmx.
+func (h *Handler) EditNote(w http.ResponseWriter, r *http.Request) {
+	n, ok := h.getNote(w, r)
+	if !ok {
+		return
+	}
+	if isHTMX(r) {
+		h.renderComponent(w, r, http.StatusOK, "note", noteView{Note: n, TZ: timezone(r), Editing: true})
+		return
+	}
+
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+	data := contactData{Contact: c, TZ: timezone(r)}
+	if err := h.loadContactPage(r.Context(), &data, defaultGraphHops); err != nil {
+		h.serverError(w, r, "load contact", err)
+		return
+	}
+	if err := h.loadNotes(r.Context(), &data, n.ID); err != nil {
+		h.serverError(w, r, "load notes", err)
+		return
+	}
+	h.renderPage(w, r, http.StatusOK, "contact", data)
+}
+
+// UpdateNote saves an edited note and returns it rendered in place of its
+// editor, or the editor again with errors.
+func (h *Handler) UpdateNote(w http.ResponseWriter, r *http.Request) {
+	n, ok := h.getNote(w, r)
+	if !ok {
+		return
+	}
+
+	n.Body = r.FormValue("body")
+	if errs := n.Validate(); len(errs) > 0 {
+		h.renderComponent(w, r, http.StatusUnprocessableEntity, "note", noteView{Note: n, TZ: timezone(r), Editing: true, Errors: errs})
+		return
+	}
+	updated, err := h.store.UpdateNote(r.Context(), n)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "update note", err)
+		return
+	}
+	slog.Info("note updated", "id", n.ID, "contact", n.ContactID)
+
+	if !isHTMX(r) {
+		http.Redirect(w, r, "/contacts/"+n.ContactID, http.StatusSeeOther)
+		return
+	}
+	h.renderComponent(w, r, http.StatusOK, "note", noteView{Note: updated, TZ: timezone(r)})
+}
+
+// PinNote pins a note to the top of the contact page, replacing any
+// pinned before, or unpins it when pinned is "false".
+func (h *Handler) PinNote(w http.ResponseWriter, r *http.Request) {
+	n, ok := h.getNote(w, r)
+	if !ok {
+		return
+	}
+
+	n.Pinned = r.FormValue("pinned") != "false"
+	if _, err := h.store.UpdateNote(r.Context(), n); err != nil && !errors.Is(err, model.ErrNotFound) {
+		h.serverError(w, r, "pin note", err)
+		return
+	}
+	slog.Info("note pinned", "id", n.ID, "contact", n.ContactID, "pinned", n.Pinned)
+
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+	h.renderNotes(w, r, http.StatusOK, c, "", nil)
+}
+
+// DeleteNote removes a note and returns the updated notes.
+func (h *Handler) DeleteNote(w http.ResponseWriter, r *http.Request) {
+	n, ok := h.getNote(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.DeleteNote(r.Context(), n.ID); err != nil && !errors.Is(err, model.ErrNotFound) {
+		h.serverError(w, r, "delete note", err)
+		return
+	}
+	slog.Info("note deleted", "id", n.ID, "contact", n.ContactID)
+
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+	h.renderNotes(w, r, http.StatusOK, c, "", nil)
+}
+
+// PreviewNote renders the Markdown being written in a note editor.
+func (h *Handler) PreviewNote(w http.ResponseWriter, r *http.Request) {
+	n := model.Note{Body: r.FormValue("body")}
+	v := noteView{Note: n, Errors: n.Validate()}
+	if v.Errors["Body"] == model.CodeRequired {
+		delete(v.Errors, "Body")
+	}
+	h.renderComponent(w, r, http.StatusOK, "note-preview", v)
+}
+
+// renderNotes answers a change to the contact's notes: htmx requests get
+// the notes section and the pinned note, others are sent back to the
+// contact page. body is the new note's text, kept when it had errors.
+func (h *Handler) renderNotes(w http.ResponseWriter, r *http.Request, status int, c model.Contact, body string, errs map[string]string) {
+	if !isHTMX(r) && status == http.StatusOK {
+		http.Redirect(w, r, "/contacts/"+c.ID, http.StatusSeeOther)
+		return
+	}
+
+	data := contactData{Contact: c, TZ: timezone(r), NoteForm: body, NoteErrors: errs}
+	if !isHTMX(r) {
+		if err := h.loadContactPage(r.Context(), &data, defaultGraphHops); err != nil {
+			h.serverError(w, r, "load contact", err)
+			return
+		}
+		h.renderPage(w, r, status, "contact", data)
+		return
+	}
+	if err := h.loadNotes(r.Context(), &data, ""); err != nil {
+		h.serverError(w, r, "load notes", err)
+		return
+	}
+	h.renderPartial(w, r, status, "notes-updated", data)
+}
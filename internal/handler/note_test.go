@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestAddNote_HTMX(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	body := "Met at **GopherCon**.\n\n<script>alert(1)</script> [x](javascript:alert(1))"
+	rec := postForm(mux, "/contacts/1/notes", url.Values{"body": {body}}, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	out := rec.Body.String()
+	if !strings.Contains(out, "<strong>GopherCon</strong>") || !strings.Contains(out, "&lt;script&gt;alert(1)&lt;/script&gt;") {
+		t.Errorf("expected the note rendered and escaped:\n%s", out)
+	}
+	if strings.Contains(out, "<script>alert") || strings.Contains(out, "javascript:") {
+		t.Errorf("unsafe markup in the rendered note:\n%s", out)
+	}
+	if !strings.Contains(out, `id="pinned-note" class="note-list pinned-note" hx-swap-oob="true"`) {
+		t.Error("expected the pinned note swapped out of band")
+	}
+	if list, _ := s.ListNotes(context.Background(), "1"); len(list) != 1 || list[0].Body != body {
+		t.Errorf("expected the note stored as written, got %+v", list)
+	}
+}
+
+func TestAddNote_Invalid(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	rec := postForm(mux, "/contacts/1/notes", url.Values{"body": {"   "}}, true)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "Notes need some text") {
+		t.Errorf("expected 422 with the error, got %d:\n%s", rec.Code, rec.Body)
+	}
+
+	long := strings.Repeat("a", model.MaxNoteLength+1)
+	rec = postForm(mux, "/contacts/1/notes", url.Values{"body": {long}}, true)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), long) {
+		t.Errorf("expected 422 with the text kept, got %d", rec.Code)
+	}
+}
+
+func TestPinNote(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	ctx := context.Background()
+
+	s.AddNote(ctx, model.Note{ContactID: "1", Body: "Prefers email."})
+	kids, _ := s.AddNote(ctx, model.Note{ContactID: "1", Body: "Kids: Sam and Jo."})
+
+	rec := postForm(mux, "/contacts/1/notes/"+kids.ID+"/pin", url.Values{"pinned": {"true"}}, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	_, pinned, _ := strings.Cut(rec.Body.String(), `id="pinned-note"`)
+	if !strings.Contains(pinned, "Kids: Sam and Jo.") || strings.Contains(pinned, "Prefers email.") {
+		t.Errorf("expected only the pinned note out of band:\n%s", pinned)
+	}
+
+	// The contact page shows it at the top, above the other notes.
+	req := httptest.NewRequest(http.MethodGet, "/contacts/1", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	page := rec.Body.String()
+	if i, j := strings.Index(page, "Kids: Sam and Jo."), strings.Index(page, `id="notes"`); i < 0 || i > j {
+		t.Error("expected the pinned note above the notes section")
+	}
+	if strings.Count(page, "Kids: Sam and Jo.") != 1 {
+		t.Error("expected the pinned note shown once")
+	}
+
+	postForm(mux, "/contacts/1/notes/"+kids.ID+"/pin", url.Values{"pinned": {"false"}}, true)
+	if n, _ := s.GetNote(ctx, kids.ID); n.Pinned {
+		t.Error("expected the note unpinned")
+	}
+}
+
+func TestEditNote(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	n, _ := s.AddNote(context.Background(), model.Note{ContactID: "1", Body: "Likes tea."})
+	path := "/contacts/1/notes/" + n.ID
+
+	req := httptest.NewRequest(http.MethodGet, path+"/edit", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), ">Likes tea.</textarea>") || !strings.Contains(rec.Body.String(), `hx-post="/notes/preview"`) {
+		t.Errorf("expected the editor with a live preview:\n%s", rec.Body)
+	}
+
+	rec = postForm(mux, path, url.Values{"body": {""}}, true)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "<textarea") {
+		t.Errorf("expected the editor again with 422, got %d", rec.Code)
+	}
+
+	rec = postForm(mux, path, url.Values{"body": {"Likes *green* tea."}}, true)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Likes <em>green</em> tea.") || !strings.Contains(rec.Body.String(), "edited") {
+		t.Errorf("expected the edited note rendered, got %d:\n%s", rec.Code, rec.Body)
+	}
+
+	// Notes are only reachable through their contact.
+	rec = postForm(mux, "/contacts/2/notes/"+n.ID, url.Values{"body": {"Hijacked"}}, true)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("other contact: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestDeleteNote(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	n, _ := s.AddNote(context.Background(), model.Note{ContactID: "1", Body: "Old news."})
+
+	req := httptest.NewRequest(http.MethodDelete, "/contacts/1/notes/"+n.ID, nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "No notes yet.") {
+		t.Errorf("expected the emptied notes, got %d:\n%s", rec.Code, rec.Body)
+	}
+}
+
+func TestPreviewNote(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	rec := postForm(mux, "/notes/preview", url.Values{"body": {"# Plan\n- <b>one</b>"}}, true)
+	if got := rec.Body.String(); !strings.Contains(got, "<h3>Plan</h3>") || !strings.Contains(got, "<li>&lt;b&gt;one&lt;/b&gt;</li>") {
+		t.Errorf("unexpected preview:\n%s", got)
+	}
+	rec = postForm(mux, "/notes/preview", url.Values{"body": {""}}, true)
+	if !strings.Contains(rec.Body.String(), "The preview shows here") {
+		t.Errorf("expected the empty preview hint:\n%s", rec.Body)
+	}
+}
+
+func TestSearchContacts_Notes(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	s.AddNote(context.Background(), model.Note{ContactID: "4", Body: "Restores vintage motorcycles on weekends."})
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/search?q=motorcycle", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	if !strings.Contains(body, "David Brown") || !strings.Contains(body, `class="note-match"`) || !strings.Contains(body, "vintage motorcycles") {
+		t.Errorf("expected David found through his note:\n%s", body)
+	}
+}
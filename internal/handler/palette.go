@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+// paletteLimit is the number of contacts the command palette lists.
+const paletteLimit = 8
+
+// paletteAction is a command palette entry that goes to a page. Label is
+// the message key of its name.
+type paletteAction struct {
+	Label string
+	URL   string
+}
+
+// paletteActions are the command palette's actions, in the order listed.
+var paletteActions = []paletteAction{
+	{"palette.new_contact", "/contacts/new"},
+	{"palette.new_company", "/companies/new"},
+	{"palette.contacts", "/contacts"},
+	{"palette.starred", "/contacts?starred=true"},
+	{"palette.companies", "/companies"},
+	{"palette.reminders", "/reminders"},
+	{"palette.notifications", "/notifications"},
+	{"palette.quality", "/quality"},
+	{"palette.trash", "/trash"},
+	{"palette.home", "/"},
+}
+
+// paletteData is the command palette's results. Without a query the
+// contacts are the recently viewed ones.
+type paletteData struct {
+	Query    string
+	Actions  []paletteAction
+	Searches []model.SavedSearch
+	Contacts []model.Contact
+}
+
+// Palette returns the command palette's results for the query q: the
+// actions and saved searches whose names contain it and the contacts it
+// finds.
+func (h *Handler) Palette(w http.ResponseWriter, r *http.Request) {
+	data := paletteData{Query: strings.TrimSpace(r.URL.Query().Get("q"))}
+	query := strings.ToLower(data.Query)
+	p := h.renderer.Bundle().Printer(h.locale(r))
+	for _, a := range paletteActions {
+		if strings.Contains(strings.ToLower(p.T(a.Label)), query) {
+			data.Actions = append(data.Actions, a)
+		}
+	}
+
+	user := UserID(r.Context())
+	searches, err := h.store.ListSavedSearches(r.Context(), user)
+	if err != nil {
+		h.serverError(w, r, "list saved searches", err)
+		return
+	}
+	for _, s := range searches {
+		if strings.Contains(strings.ToLower(s.Name), query) {
+			data.Searches = append(data.Searches, s)
+		}
+	}
+
+	if data.Query == "" {
+		views, err := h.store.RecentlyViewed(r.Context(), user, paletteLimit)
+		if err != nil {
+			h.serverError(w, r, "list recently viewed contacts", err)
+			return
+		}
+		for _, v := range views {
+			data.Contacts = append(data.Contacts, v.Contact)
+		}
+	} else {
+		data.Contacts, err = h.store.List(r.Context(), store.Query{Search: data.Query, User: user})
+		if err != nil {
+			h.serverError(w, r, "search contacts", err)
+			return
+		}
+		data.Contacts = data.Contacts[:min(len(data.Contacts), paletteLimit)]
+	}
+	h.renderComponent(w, r, http.StatusOK, "palette-results", data)
+}
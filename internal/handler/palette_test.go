@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+func TestPalette(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	ctx := context.Background()
+	saved, _ := s.SaveSearch(ctx, model.SavedSearch{Name: "Acme people", Query: "q=acme"})
+	s.RecordView(ctx, "", "4", time.Now())
+
+	rec := getHTMX(mux, "/palette?q=")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	out := rec.Body.String()
+	for _, want := range []string{`href="/contacts/new">New contact<`, `href="/contacts?list=` + saved.ID + `">Acme people<`, `href="/contacts/4">David Brown<`, "Recently viewed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q with an empty query:\n%s", want, out)
+		}
+	}
+
+	out = getHTMX(mux, "/palette?q=ACME").Body.String()
+	if !strings.Contains(out, `href="/contacts/3">`) || !strings.Contains(out, "Acme people") {
+		t.Errorf("expected Carol and the saved search for acme:\n%s", out)
+	}
+	if strings.Contains(out, "New contact") || strings.Contains(out, `href="/contacts/4"`) {
+		t.Errorf("expected unmatched entries left out:\n%s", out)
+	}
+
+	out = getHTMX(mux, "/palette?q=remind").Body.String()
+	if !strings.Contains(out, `href="/reminders">Reminders<`) {
+		t.Errorf("expected the reminders action:\n%s", out)
+	}
+
+	out = getHTMX(mux, "/palette?q=trash").Body.String()
+	if !strings.Contains(out, `href="/trash">Go to trash<`) {
+		t.Errorf("expected the trash action:\n%s", out)
+	}
+
+	out = getHTMX(mux, "/palette?q=zzz").Body.String()
+	if !strings.Contains(out, "Nothing matches “zzz”.") {
+		t.Errorf("expected the empty message:\n%s", out)
+	}
+}
+
+func TestDeleteContacts(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	rec := postForm(mux, "/contacts/delete", url.Values{"id": {"1", "3", "99"}, "q": {"example"}}, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	out := rec.Body.String()
+	if strings.Contains(out, `id="contact-1"`) || strings.Contains(out, `id="contact-3"`) || !strings.Contains(out, `id="contact-2"`) {
+		t.Errorf("expected the remaining rows:\n%s", out)
+	}
+	if rec.Header().Get("HX-Trigger") != "contacts-changed" {
+		t.Error("expected contacts-changed")
+	}
+	if list, _ := s.List(context.Background(), store.Query{}); len(list) != 3 {
+		t.Errorf("expected 3 contacts left, got %d", len(list))
+	}
+
+	rec = postForm(mux, "/contacts/delete", url.Values{"id": {"2"}}, false)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/contacts" {
+		t.Errorf("expected a redirect to the list, got %d", rec.Code)
+	}
+}
+
+func TestListContacts_Shortcuts(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	page := getHTMX(h.Routes(), "/contacts").Body.String()
+	_, row, _ := strings.Cut(page, `id="contact-1"`)
+	row, _, _ = strings.Cut(row, "</tr>")
+	for _, want := range []string{`tabindex="-1"`, `form="contact-selection" data-shortcut="x"`, `data-shortcut="e"`, `data-shortcut="#"`} {
+		if !strings.Contains(row, want) {
+			t.Errorf("expected %q in the row:\n%s", want, row)
+		}
+	}
+	if !strings.Contains(page, `id="contact-selection"`) || !strings.Contains(page, "data-shortcuts") {
+		t.Error("expected the selection form and the shortcut list")
+	}
+}
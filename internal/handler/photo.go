@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/devaloi/htmxapp/internal/blob"
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/photo"
+)
+
+// maxContactForm bounds a contact form submission: a photo plus the
+// fields.
+const maxContactForm = photo.MaxBytes + 1<<20
+
+// parseContactForm parses the contact form, which is multipart when it
+// carries a photo, and then may take longer than the server's read
+// timeout to upload. Bodies over maxContactForm are rejected outright
+// since the form can't be shown again without them.
+func parseContactForm(w http.ResponseWriter, r *http.Request) bool {
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+		extendDeadlines(w)
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxContactForm)
+	err := r.ParseMultipartForm(1 << 20)
+	if err == nil || errors.Is(err, http.ErrNotMultipart) {
+		return true
+	}
+	if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return false
+	}
+	http.Error(w, "invalid form", http.StatusBadRequest)
+	return false
+}
+
+// readPhoto processes the photo uploaded with the contact form. It returns
+// nil renditions when nothing was uploaded or photos are disabled, and a
+// validation code for uploads that can't be used.
+func (h *Handler) readPhoto(r *http.Request) (map[string][]byte, string) {
+	if h.blobs == nil {
+		return nil, ""
+	}
+	f, _, err := r.FormFile("photo")
+	if err != nil {
+		return nil, ""
+	}
+	defer f.Close()
+
+	renditions, err := photo.Process(f)
+	switch {
+	case errors.Is(err, photo.ErrTooLarge):
+		return nil, model.CodeTooLarge
+	case err != nil:
+		return nil, model.CodeUnsupportedImage
+	}
+	return renditions, ""
+}
+
+// storePhoto stores a processed photo's renditions under a new photo ID.
+func (h *Handler) storePhoto(ctx context.Context, renditions map[string][]byte) (string, error) {
+	id := newPhotoID()
+	for size, data := range renditions {
+		if err := h.blobs.Put(ctx, photoKey(id, size), bytes.NewReader(data)); err != nil {
+			h.deletePhoto(ctx, id)
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+// deletePhoto removes a photo's renditions. Failures only leave unused
+// blobs behind, so they are logged rather than returned.
+func (h *Handler) deletePhoto(ctx context.Context, id string) {
+	if id == "" || h.blobs == nil {
+		return
+	}
+	for _, s := range photo.Sizes {
+		if err := h.blobs.Delete(ctx, photoKey(id, s.Name)); err != nil {
+			slog.Warn("deleting photo", "photo", id, "size", s.Name, "error", err)
+		}
+	}
+}
+
+func photoKey(id, size string) string {
+	return "photos/" + id + "/" + size + ".jpg"
+}
+
+func newPhotoID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ContactPhoto serves a rendition of the contact's photo. Links carry the
+// photo ID in the query, so responses to them can be cached for good: a
+// new photo gets a new ID and so a new URL.
+func (h *Handler) ContactPhoto(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+	size, ok := photo.SizeNamed(r.PathValue("size"))
+	if !ok || c.PhotoID == "" || h.blobs == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	rc, err := h.blobs.Open(r.Context(), photoKey(c.PhotoID, size.Name))
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "open photo", err)
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", photo.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if r.URL.Query().Get("v") == c.PhotoID {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	if _, err := io.Copy(w, rc); err != nil {
+		slog.Warn("writing photo", "contact", c.ID, "error", err)
+	}
+}
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/blob"
+)
+
+// withPhotos enables photo uploads, stored in a temporary directory.
+func withPhotos(t *testing.T, h *Handler) *blob.FS {
+	t.Helper()
+	b, err := blob.NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.blobs = b
+	return b
+}
+
+// postMultipart submits fields and, unless photo is nil, a photo file.
+func postMultipart(mux http.Handler, path string, fields map[string]string, photo []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	if photo != nil {
+		fw, _ := mw.CreateFormFile("photo", "me.png")
+		fw.Write(photo)
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCreateContact_Photo(t *testing.T) {
+	h, s := setupTestHandler(t)
+	withPhotos(t, h)
+	mux := h.Routes()
+
+	fields := map[string]string{"first_name": "Frank", "email": "frank@example.com"}
+	if rec := postMultipart(mux, "/contacts", fields, testPNG(t, 200, 120)); rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d: %s", rec.Code, rec.Body)
+	}
+	c, _ := s.Get(context.Background(), "6")
+	if c.PhotoID == "" {
+		t.Fatal("expected a photo")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/6/photo/thumb?v="+c.PhotoID, nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/jpeg" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+		t.Errorf("Cache-Control = %q, want immutable", cc)
+	}
+	img, err := jpeg.Decode(rec.Body)
+	if err != nil {
+		t.Fatalf("decoding thumbnail: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 96 || b.Dy() != 96 {
+		t.Errorf("thumbnail is %v, want 96x96", b)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/contacts", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), `src="/contacts/6/photo/thumb?v=`+c.PhotoID+`"`) {
+		t.Error("expected the photo in the contact's row")
+	}
+	if !strings.Contains(rec.Body.String(), `<svg class="avatar"`) {
+		t.Error("expected initials avatars for contacts without photos")
+	}
+}
+
+// TestCreateContact_SlowPhoto checks that a form with a photo may take
+// longer to upload than the server's read timeout.
+func TestCreateContact_SlowPhoto(t *testing.T) {
+	h, s := setupTestHandler(t)
+	withPhotos(t, h)
+	srv := httptest.NewUnstartedServer(h.Routes())
+	srv.Config.ReadTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	img := testPNG(t, 64, 64)
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		mw.WriteField("first_name", "Frank")
+		mw.WriteField("email", "frank@example.com")
+		fw, _ := mw.CreateFormFile("photo", "me.png")
+		fw.Write(img[:len(img)/2])
+		time.Sleep(300 * time.Millisecond)
+		fw.Write(img[len(img)/2:])
+		mw.Close()
+		pw.Close()
+	}()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/contacts", pr)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	client := srv.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", resp.StatusCode)
+	}
+	if c, _ := s.Get(context.Background(), "6"); c.PhotoID == "" {
+		t.Error("expected the photo stored")
+	}
+}
+
+func TestCreateContact_PhotoRejected(t *testing.T) {
+	h, s := setupTestHandler(t)
+	withPhotos(t, h)
+	mux := h.Routes()
+
+	fields := map[string]string{"first_name": "Frank", "email": "frank@example.com"}
+	rec := postMultipart(mux, "/contacts", fields, []byte("#!/bin/sh\necho not a photo\n"))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Upload a JPEG, PNG or GIF image") || !strings.Contains(rec.Body.String(), `value="Frank"`) {
+		t.Error("expected the form again with the photo error")
+	}
+	if s.Count(context.Background()) != 5 {
+		t.Error("contact was created despite the bad photo")
+	}
+
+	huge := bytes.Repeat([]byte("x"), maxContactForm+1)
+	if rec := postMultipart(mux, "/contacts", fields, huge); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized form: expected 413, got %d", rec.Code)
+	}
+}
+
+func TestUpdateContact_Photo(t *testing.T) {
+	h, s := setupTestHandler(t)
+	blobs := withPhotos(t, h)
+	mux := h.Routes()
+	ctx := context.Background()
+
+	fields := map[string]string{"first_name": "Alice", "last_name": "Johnson", "email": "alice@example.com"}
+	if rec := postMultipart(mux, "/contacts/1", fields, testPNG(t, 50, 50)); rec.Code != http.StatusSeeOther {
+		t.Fatalf("upload: expected 303, got %d", rec.Code)
+	}
+	first, _ := s.Get(ctx, "1")
+
+	// Saving without a new upload keeps the photo.
+	if rec := postMultipart(mux, "/contacts/1", fields, nil); rec.Code != http.StatusSeeOther {
+		t.Fatalf("save: expected 303, got %d", rec.Code)
+	}
+	if c, _ := s.Get(ctx, "1"); c.PhotoID != first.PhotoID {
+		t.Fatalf("photo changed from %q to %q without an upload", first.PhotoID, c.PhotoID)
+	}
+
+	// A new upload replaces it and removes the old renditions.
+	postMultipart(mux, "/contacts/1", fields, testPNG(t, 60, 60))
+	second, _ := s.Get(ctx, "1")
+	if second.PhotoID == "" || second.PhotoID == first.PhotoID {
+		t.Fatalf("expected a new photo, got %q", second.PhotoID)
+	}
+	if _, err := blobs.Open(ctx, photoKey(first.PhotoID, "thumb")); !errors.Is(err, blob.ErrNotFound) {
+		t.Errorf("old photo still stored: %v", err)
+	}
+
+	// Removing it deletes the renditions.
+	fields["remove_photo"] = "1"
+	postMultipart(mux, "/contacts/1", fields, nil)
+	if c, _ := s.Get(ctx, "1"); c.PhotoID != "" {
+		t.Errorf("expected no photo, got %q", c.PhotoID)
+	}
+	if _, err := blobs.Open(ctx, photoKey(second.PhotoID, "medium")); !errors.Is(err, blob.ErrNotFound) {
+		t.Errorf("removed photo still stored: %v", err)
+	}
+}
+
+func TestDeleteContact_Photo(t *testing.T) {
+	h, s := setupTestHandler(t)
+	blobs := withPhotos(t, h)
+	mux := h.Routes()
+	ctx := context.Background()
+
+	fields := map[string]string{"first_name": "Alice", "email": "alice@example.com"}
+	postMultipart(mux, "/contacts/1", fields, testPNG(t, 50, 50))
+	c, _ := s.Get(ctx, "1")
+
+	req := httptest.NewRequest(http.MethodDelete, "/contacts/1", nil)
+	req.Header.Set("HX-Request", "true")
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+	if _, err := blobs.Open(ctx, photoKey(c.PhotoID, "thumb")); err != nil {
+		t.Fatalf("photo of trashed contact removed: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/trash/1", nil)
+	req.Header.Set("HX-Request", "true")
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+	if _, err := blobs.Open(ctx, photoKey(c.PhotoID, "thumb")); !errors.Is(err, blob.ErrNotFound) {
+		t.Errorf("photo of purged contact still stored: %v", err)
+	}
+}
+
+func TestContactPhoto_NotFound(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	withPhotos(t, h)
+	mux := h.Routes()
+
+	for _, path := range []string{"/contacts/1/photo/thumb", "/contacts/1/photo/huge", "/contacts/999/photo/thumb"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s: expected 404, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestNewContact_PhotoField(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	get := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/contacts/new", nil)
+		rec := httptest.NewRecorder()
+		h.Routes().ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	if body := get(); strings.Contains(body, `type="file"`) || strings.Contains(body, "multipart") {
+		t.Error("expected no photo field without blob storage")
+	}
+	withPhotos(t, h)
+	if body := get(); !strings.Contains(body, `type="file"`) || !strings.Contains(body, `enctype="multipart/form-data"`) {
+		t.Error("expected a multipart form with a photo field")
+	}
+}
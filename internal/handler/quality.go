@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/quality"
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+// qualityData is the data-quality report, with the outcome of the fixes
+// just applied, if any.
+type qualityData struct {
+	Groups []quality.Group
+
+	// Fixed counts the contacts fixed; Failed are those whose fix didn't
+	// validate or couldn't be saved, with the validation errors.
+	Fixed  int
+	Failed []failedFix
+}
+
+type failedFix struct {
+	Contact model.Contact
+	Errors  map[string]string
+}
+
+// QualityReport renders the data-quality report: the contacts failing
+// each enabled check, with fixes for those that can be fixed.
+func (h *Handler) QualityReport(w http.ResponseWriter, r *http.Request) {
+	var data qualityData
+	if err := h.loadQuality(r, &data); err != nil {
+		h.serverError(w, r, "analyze contacts", err)
+		return
+	}
+	h.renderPage(w, r, http.StatusOK, "quality", data)
+}
+
+// FixIssues applies the fix for the check to the contacts id, each
+// validated and saved like an edit from the contact form, and returns the
+// updated report. Contacts no longer failing the check are skipped.
+func (h *Handler) FixIssues(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	check := r.PostForm.Get("check")
+	if !slices.Contains(h.quality.Enabled(), check) {
+		http.Error(w, "unknown check", http.StatusBadRequest)
+		return
+	}
+
+	var data qualityData
+	for _, id := range r.PostForm["id"] {
+		c, err := h.store.Get(r.Context(), id)
+		if errors.Is(err, model.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			h.serverError(w, r, "get contact", err)
+			return
+		}
+		fixed, ok := quality.Fix(c, check)
+		if !ok {
+			continue
+		}
+		if errs := h.validate(&fixed); len(errs) > 0 {
+			data.Failed = append(data.Failed, failedFix{Contact: c, Errors: errs})
+			continue
+		}
+		if _, err := h.store.Update(r.Context(), fixed); err != nil {
+			if errs, ok := contactStoreErrors(err); ok {
+				data.Failed = append(data.Failed, failedFix{Contact: c, Errors: errs})
+				continue
+			}
+			if errors.Is(err, model.ErrNotFound) {
+				continue
+			}
+			h.serverError(w, r, "update contact", err)
+			return
+		}
+		data.Fixed++
+		slog.Info("contact fixed", "id", c.ID, "check", check)
+	}
+
+	if !isHTMX(r) {
+		http.Redirect(w, r, "/quality", http.StatusSeeOther)
+		return
+	}
+	if err := h.loadQuality(r, &data); err != nil {
+		h.serverError(w, r, "analyze contacts", err)
+		return
+	}
+	if data.Fixed > 0 {
+		w.Header().Set("HX-Trigger", "contacts-changed")
+	}
+	h.renderComponent(w, r, http.StatusOK, "quality-report", data)
+}
+
+// loadQuality runs the analyzer over every contact, in name order.
+func (h *Handler) loadQuality(r *http.Request, data *qualityData) error {
+	contacts, err := h.store.List(r.Context(), store.Query{Sort: store.SortName})
+	if err != nil {
+		return err
+	}
+	data.Groups = h.quality.Analyze(r.Context(), contacts)
+	return nil
+}
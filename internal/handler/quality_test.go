@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/quality"
+)
+
+func TestQualityReport(t *testing.T) {
+	h, s := setupTestHandler(t)
+	ctx := context.Background()
+	c, _ := s.Get(ctx, "2")
+	c.FirstName, c.LastName, c.Phone = "BOB", "SMITH", "(555) 555.0102"
+	s.Update(ctx, c)
+
+	rec := httptest.NewRecorder()
+	h.Routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quality", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	page := rec.Body.String()
+	_, names, _ := strings.Cut(page, `id="quality-name_case"`)
+	names, _, _ = strings.Cut(names, "</section>")
+	if !strings.Contains(names, "BOB SMITH") || !strings.Contains(names, `class="quality-suggestion">Bob Smith<`) {
+		t.Errorf("expected Bob's name with its fix:\n%s", names)
+	}
+	if !strings.Contains(page, `class="quality-suggestion">555-555-0102<`) {
+		t.Error("expected the normalized phone suggested")
+	}
+	if !strings.Contains(page, `id="quality-missing_phone"`) || !strings.Contains(page, "No contacts fail this check.") {
+		t.Error("expected the empty missing-phone check")
+	}
+}
+
+func TestFixIssues(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	ctx := context.Background()
+	for id, name := range map[string]string{"1": "ALICE", "3": "carol"} {
+		c, _ := s.Get(ctx, id)
+		c.FirstName = name
+		s.Update(ctx, c)
+	}
+
+	rec := postForm(mux, "/quality/fix", url.Values{"check": {quality.NameCase}, "id": {"1", "3", "4", "99"}}, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Fixed 2 contacts.") || rec.Header().Get("HX-Trigger") != "contacts-changed" {
+		t.Errorf("expected two contacts fixed:\n%s", rec.Body)
+	}
+	for id, want := range map[string]string{"1": "Alice", "3": "Carol"} {
+		if c, _ := s.Get(ctx, id); c.FirstName != want {
+			t.Errorf("contact %s: got %q, want %q", id, c.FirstName, want)
+		}
+	}
+
+	rec = postForm(mux, "/quality/fix", url.Values{"check": {"spelling"}, "id": {"1"}}, true)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown check: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestFixIssues_Validates(t *testing.T) {
+	h, s := setupTestHandler(t)
+	ctx := context.Background()
+	emails, _ := model.NewEmailPolicy(nil, []string{"example.com"})
+	WithEmailPolicy(emails)(h)
+
+	c, _ := s.Get(ctx, "5")
+	c.Phone = "555.555.0105"
+	s.Update(ctx, c)
+
+	rec := postForm(h.Routes(), "/quality/fix", url.Values{"check": {quality.MalformedPhone}, "id": {"5"}}, true)
+	body := rec.Body.String()
+	if strings.Contains(body, "Fixed") || !strings.Contains(body, `href="/contacts/5/edit">Eve Davis</a>`) {
+		t.Errorf("expected Eve's fix refused by the email policy:\n%s", body)
+	}
+	if c, _ := s.Get(ctx, "5"); c.Phone != "555.555.0105" {
+		t.Errorf("expected the phone unchanged, got %q", c.Phone)
+	}
+
+	rec = postForm(h.Routes(), "/quality/fix", url.Values{"check": {quality.MalformedPhone}, "id": {"5"}}, false)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/quality" {
+		t.Errorf("expected a redirect to the report, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/devaloi/htmxapp/internal/graph"
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+// Relationship graph limits. Neighborhoods are drawn up to maxGraphHops
+// away and capped at maxGraphNodes contacts to keep the SVG legible.
+const (
+	defaultGraphHops = 2
+	maxGraphHops     = 3
+	maxGraphNodes    = 40
+	graphSize        = 560
+	maxPickerResults = 10
+)
+
+// relationView is a relationship as seen from the contact being shown.
+type relationView struct {
+	ID            string
+	Type          string
+	Other         model.Contact
+	Bidirectional bool
+}
+
+// pickerData lists the contacts matching a picker search as radio
+// buttons, or checkboxes if Multiple, named Name.
+type pickerData struct {
+	Contacts []model.Contact
+	Search   string
+	Name     string
+	Multiple bool
+}
+
+// loadRelationships fills in the contact page's relationships and graph.
+func (h *Handler) loadRelationships(ctx context.Context, data *contactData, hops int) error {
+	rels, err := h.store.ListRelationships(ctx, data.Contact.ID)
+	if err != nil {
+		return err
+	}
+	data.Relationships = data.Relationships[:0]
+	for _, r := range rels {
+		otherID, typ, _ := r.From(data.Contact.ID)
+		other, err := h.store.Get(ctx, otherID)
+		if err != nil {
+			return err
+		}
+		data.Relationships = append(data.Relationships, relationView{
+			ID: r.ID, Type: typ, Other: other, Bidirectional: r.Bidirectional,
+		})
+	}
+	data.RelationTypes = model.RelationTypes
+
+	g, err := graph.Neighborhood(ctx, data.Contact.ID, hops, maxGraphNodes, h.store.ListRelationships)
+	if err != nil {
+		return err
+	}
+	for i, n := range g.Nodes {
+		c, err := h.store.Get(ctx, n.ID)
+		if err != nil {
+			return err
+		}
+		g.Nodes[i].Label = c.DisplayName(h.nameOrder)
+	}
+	g.Layout(graphSize)
+	data.Graph = g
+	return nil
+}
+
+// graphHops reads the hops parameter, clamped to the supported range.
+func graphHops(r *http.Request) int {
+	hops, err := strconv.Atoi(r.URL.Query().Get("hops"))
+	if err != nil {
+		return defaultGraphHops
+	}
+	return min(max(hops, 1), maxGraphHops)
+}
+
+// getContact loads the contact named in the path, writing a 404 or server
+// error and returning false if it can't.
+func (h *Handler) getContact(w http.ResponseWriter, r *http.Request) (model.Contact, bool) {
+	c, err := h.store.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return model.Contact{}, false
+		}
+		h.serverError(w, r, "get contact", err)
+		return model.Contact{}, false
+	}
+	return c, true
+}
+
+// PickContact returns contacts matching the picker's search, other than
+// the contact being related, as radio options for an htmx swap.
+func (h *Handler) PickContact(w http.ResponseWriter, r *http.Request) {
+	h.pickContacts(w, r, pickerData{Name: "to_id"})
+}
+
+// pickContacts renders the picker options for the contact in the path and
+// the search in the q parameter.
+func (h *Handler) pickContacts(w http.ResponseWriter, r *http.Request, data pickerData) {
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+
+	data.Search = r.URL.Query().Get("q")
+	if data.Search != "" {
+		contacts, err := h.store.List(r.Context(), store.Query{Search: data.Search})
+		if err != nil {
+			h.serverError(w, r, "search contacts", err)
+			return
+		}
+		for _, other := range contacts {
+			if other.ID != c.ID && len(data.Contacts) < maxPickerResults {
+				data.Contacts = append(data.Contacts, other)
+			}
+		}
+	}
+	h.renderComponent(w, r, http.StatusOK, "contact-picker", data)
+}
+
+// AddRelationship records a relationship from the contact and returns the
+// updated relationships section, with the graph swapped out of band.
+func (h *Handler) AddRelationship(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+
+	rel := model.Relationship{
+		FromID:        c.ID,
+		ToID:          r.FormValue("to_id"),
+		Type:          r.FormValue("type"),
+		Bidirectional: r.FormValue("bidirectional") != "",
+	}
+	errs := rel.Validate()
+	if len(errs) == 0 {
+		_, err := h.store.AddRelationship(r.Context(), rel)
+		switch {
+		case errors.Is(err, model.ErrDuplicateRelationship):
+			errs["ToID"] = model.CodeDuplicate
+		case errors.Is(err, model.ErrNotFound):
+			errs["ToID"] = model.CodeUnknownContact
+		case err != nil:
+			h.serverError(w, r, "add relationship", err)
+			return
+		default:
+			slog.Info("relationship added", "from", rel.FromID, "to", rel.ToID, "type", rel.Type)
+		}
+	}
+
+	status := http.StatusOK
+	if len(errs) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	h.renderRelationships(w, r, status, c, errs)
+}
+
+// DeleteRelationship removes a relationship visible from the contact and
+// returns the updated relationships section.
+func (h *Handler) DeleteRelationship(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+
+	rels, err := h.store.ListRelationships(r.Context(), c.ID)
+	if err != nil {
+		h.serverError(w, r, "list relationships", err)
+		return
+	}
+	rid := r.PathValue("rid")
+	found := false
+	for _, rel := range rels {
+		found = found || rel.ID == rid
+	}
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := h.store.DeleteRelationship(r.Context(), rid); err != nil && !errors.Is(err, model.ErrNotFound) {
+		h.serverError(w, r, "delete relationship", err)
+		return
+	}
+	slog.Info("relationship deleted", "id", rid)
+
+	h.renderRelationships(w, r, http.StatusOK, c, nil)
+}
+
+// renderRelationships answers a relationship change: htmx requests get
+// the relationships section and graph, others are sent back to the
+// contact page.
+func (h *Handler) renderRelationships(w http.ResponseWriter, r *http.Request, status int, c model.Contact, errs map[string]string) {
+	if !isHTMX(r) && status == http.StatusOK {
+		http.Redirect(w, r, "/contacts/"+c.ID, http.StatusSeeOther)
+		return
+	}
+
+	data := contactData{Contact: c, TZ: timezone(r), Errors: errs}
+	if !isHTMX(r) {
+		if err := h.loadContactPage(r.Context(), &data, defaultGraphHops); err != nil {
+			h.serverError(w, r, "load contact", err)
+			return
+		}
+		h.renderPage(w, r, status, "contact", data)
+		return
+	}
+	if err := h.loadRelationships(r.Context(), &data, defaultGraphHops); err != nil {
+		h.serverError(w, r, "load relationships", err)
+		return
+	}
+	h.renderPartial(w, r, status, "relationships-updated", data)
+}
+
+// RelationshipGraph returns the contact's relationship graph for the
+// requested number of hops.
+func (h *Handler) RelationshipGraph(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+
+	data := contactData{Contact: c}
+	if err := h.loadRelationships(r.Context(), &data, graphHops(r)); err != nil {
+		h.serverError(w, r, "load relationships", err)
+		return
+	}
+	h.renderComponent(w, r, http.StatusOK, "relationship-graph", data)
+}
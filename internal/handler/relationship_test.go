@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestShowContact_Relationships(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/1", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"Direct report", "Bob Smith", "Eve Davis", "<svg class=\"graph\""} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in contact page", want)
+		}
+	}
+}
+
+func TestAddRelationship_HTMX(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	form := url.Values{"to_id": {"3"}, "type": {"mentor"}}
+	rec := postForm(mux, "/contacts/1/relationships", form, true)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Mentor") || !strings.Contains(body, "Carol Williams") {
+		t.Errorf("expected new relationship in response:\n%s", body)
+	}
+	if !strings.Contains(body, `hx-swap-oob`) {
+		t.Error("expected graph swapped out of band")
+	}
+
+	// One-way relationships aren't listed on the other contact.
+	rels, _ := s.ListRelationships(context.Background(), "3")
+	for _, r := range rels {
+		if r.Type == "mentor" {
+			t.Errorf("unexpected one-way relationship on Carol: %+v", r)
+		}
+	}
+}
+
+func TestAddRelationship_Redirect(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	form := url.Values{"to_id": {"5"}, "type": {"friend"}, "bidirectional": {"true"}}
+	rec := postForm(mux, "/contacts/2/relationships", form, false)
+
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/contacts/2" {
+		t.Errorf("expected redirect to /contacts/2, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestAddRelationship_ValidationError(t *testing.T) {
+	tests := []struct {
+		name string
+		form url.Values
+		want string
+	}{
+		{"self", url.Values{"to_id": {"1"}, "type": {"friend"}}, "be related to themselves"},
+		{"type", url.Values{"to_id": {"3"}, "type": {"nemesis"}}, "Choose a type"},
+		{"duplicate", url.Values{"to_id": {"2"}, "type": {"report"}}, "That relationship already exists"},
+		{"unknown", url.Values{"to_id": {"999"}, "type": {"friend"}}, "That contact no longer exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := setupTestHandler(t)
+			rec := postForm(h.Routes(), "/contacts/1/relationships", tt.form, true)
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected 422, got %d", rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("expected %q in response:\n%s", tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestDeleteRelationship(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	// Relationship 3 is between Carol and David, not Alice.
+	req := httptest.NewRequest(http.MethodDelete, "/contacts/1/relationships/3", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for another contact's relationship, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/contacts/1/relationships/1", nil)
+	req.Header.Set("HX-Request", "true")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "relationships/1\"") {
+		t.Error("expected relationship removed from response")
+	}
+	if rels, _ := s.ListRelationships(context.Background(), "2"); len(rels) != 0 {
+		t.Errorf("expected Bob's relationship deleted, got %v", rels)
+	}
+}
+
+func TestPickContact(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/1/relationships/picker?q=o", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Bob Smith") || !strings.Contains(body, `name="to_id"`) {
+		t.Errorf("expected Bob as an option:\n%s", body)
+	}
+	if strings.Contains(body, "Alice Johnson") {
+		t.Error("contact should not be offered as related to itself")
+	}
+}
+
+func TestRelationshipGraph(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := h.Routes()
+
+	tests := []struct {
+		hops  string
+		eve   bool
+		nodes int
+	}{
+		{"1", false, 2},
+		{"2", true, 3},
+		{"9", true, 3},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/contacts/2/graph?hops="+tt.hops, nil)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("hops=%s: expected 200, got %d", tt.hops, rec.Code)
+		}
+		body := rec.Body.String()
+		if got := strings.Count(body, "<circle"); got != tt.nodes {
+			t.Errorf("hops=%s: %d nodes, want %d", tt.hops, got, tt.nodes)
+		}
+		if strings.Contains(body, "Eve Davis") != tt.eve {
+			t.Errorf("hops=%s: Eve shown = %v, want %v", tt.hops, !tt.eve, tt.eve)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/tmpl"
+)
+
+// reminderHour is the local hour reminders set for a day fall due.
+const reminderHour = 9
+
+// reminderPresets are the "remind me in" choices, in days; "1m" is a
+// calendar month.
+var reminderPresets = []string{"1d", "3d", "1w", "2w", "1m"}
+
+// reminderView is a reminder with its contact, classified relative to the
+// current day in the user's timezone.
+type reminderView struct {
+	Reminder model.Reminder
+	Contact  model.Contact
+	TZ       string
+	Overdue  bool
+	Today    bool
+
+	// ShowContact names the contact, for lists spanning contacts.
+	ShowContact bool
+}
+
+type remindersData struct {
+	Overdue  []reminderView
+	Today    []reminderView
+	Upcoming []reminderView
+	TZ       string
+}
+
+// reminderForm holds the contact page's reminder form as submitted, so it
+// can be shown again with validation errors.
+type reminderForm struct {
+	Note string
+	In   string
+	Date string
+}
+
+type notificationView struct {
+	Notification model.Notification
+	Contact      model.Contact
+}
+
+type notificationsData struct {
+	Notifications []notificationView
+	TZ            string
+}
+
+// atReminderHour returns reminderHour on day's date, in day's location.
+// It sets the clock rather than adding hours to midnight so the hour is
+// right on days a DST change shifts.
+func atReminderHour(day time.Time) time.Time {
+	y, m, d := day.Date()
+	return time.Date(y, m, d, reminderHour, 0, 0, 0, day.Location())
+}
+
+// reminderDue returns reminderHour on the day offset from now's day by
+// preset, in now's location. ok is false for unknown presets.
+func reminderDue(now time.Time, preset string) (time.Time, bool) {
+	day := model.StartOfDay(now)
+	switch preset {
+	case "1d":
+		day = day.AddDate(0, 0, 1)
+	case "3d":
+		day = day.AddDate(0, 0, 3)
+	case "1w":
+		day = day.AddDate(0, 0, 7)
+	case "2w":
+		day = day.AddDate(0, 0, 14)
+	case "1m":
+		day = day.AddDate(0, 1, 0)
+	default:
+		return time.Time{}, false
+	}
+	return atReminderHour(day), true
+}
+
+// newReminderView classifies r for display to a user in tz.
+func (h *Handler) newReminderView(ctx context.Context, r model.Reminder, tz string, showContact bool) (reminderView, error) {
+	c, err := h.store.Get(ctx, r.ContactID)
+	if err != nil {
+		return reminderView{}, err
+	}
+	now := time.Now().In(tmpl.Location(tz))
+	return reminderView{
+		Reminder:    r,
+		Contact:     c,
+		TZ:          tz,
+		Overdue:     r.Overdue(now),
+		Today:       r.DueToday(now),
+		ShowContact: showContact,
+	}, nil
+}
+
+// loadReminders fills in the contact page's open reminders.
+func (h *Handler) loadReminders(ctx context.Context, data *contactData) error {
+	list, err := h.store.ListReminders(ctx, data.Contact.ID)
+	if err != nil {
+		return err
+	}
+	data.Reminders = data.Reminders[:0]
+	for _, r := range list {
+		v, err := h.newReminderView(ctx, r, data.TZ, false)
+		if err != nil {
+			return err
+		}
+		data.Reminders = append(data.Reminders, v)
+	}
+	data.ReminderPresets = reminderPresets
+	return nil
+}
+
+// ListReminders renders open reminders grouped into overdue, due today and
+// upcoming.
+func (h *Handler) ListReminders(w http.ResponseWriter, r *http.Request) {
+	list, err := h.store.ListReminders(r.Context(), "")
+	if err != nil {
+		h.serverError(w, r, "list reminders", err)
+		return
+	}
+
+	data := remindersData{TZ: timezone(r)}
+	for _, rem := range list {
+		v, err := h.newReminderView(r.Context(), rem, data.TZ, true)
+		if err != nil {
+			h.serverError(w, r, "get contact", err)
+			return
+		}
+		switch {
+		case v.Overdue:
+			data.Overdue = append(data.Overdue, v)
+		case v.Today:
+			data.Today = append(data.Today, v)
+		default:
+			data.Upcoming = append(data.Upcoming, v)
+		}
+	}
+	h.renderPage(w, r, http.StatusOK, "reminders", data)
+}
+
+// AddReminder sets a reminder for the contact, due on a chosen date or
+// after a preset delay, and returns the updated reminders section.
+func (h *Handler) AddReminder(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.getContact(w, r)
+	if !ok {
+		return
+	}
+
+	tz := timezone(r)
+	form := reminderForm{
+		Note: strings.TrimSpace(r.FormValue("note")),
+		In:   r.FormValue("in"),
+		Date: r.FormValue("date"),
+	}
+	rem := model.Reminder{ContactID: c.ID, Note: form.Note}
+
+	errs := make(map[string]string)
+	switch {
+	case form.Date != "":
+		day, err := time.ParseInLocation(model.DateLayout, form.Date, tmpl.Location(tz))
+		if err != nil {
+			errs["Due"] = model.CodeInvalidDate
+			break
+		}
+		rem.Due = atReminderHour(day)
+	case form.In != "":
+		due, ok := reminderDue(time.Now().In(tmpl.Location(tz)), form.In)
+		if !ok {
+			errs["Due"] = model.CodeInvalidOption
+			break
+		}
+		rem.Due = due
+	}
+	if len(errs) == 0 {
+		errs = rem.Validate()
+	}
+
+	if len(errs) == 0 {
+		added, err := h.store.AddReminder(r.Context(), rem)
+		if err != nil {
+			h.serverError(w, r, "add reminder", err)
+			return
+		}
+		slog.Info("reminder added", "id", added.ID, "contact", c.ID, "due", added.Due)
+		if !isHTMX(r) {
+			http.Redirect(w, r, "/contacts/"+c.ID, http.StatusSeeOther)
+			return
+		}
+		form = reminderForm{}
+	}
+
+	status := http.StatusOK
+	if len(errs) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	data := contactData{Contact: c, TZ: tz, ReminderForm: form, ReminderErrors: errs}
+	if !isHTMX(r) {
+		if err := h.loadContactPage(r.Context(), &data, defaultGraphHops); err != nil {
+			h.serverError(w, r, "load contact", err)
+			return
+		}
+		h.renderPage(w, r, status, "contact", data)
+		return
+	}
+	if err := h.loadReminders(r.Context(), &data); err != nil {
+		h.serverError(w, r, "load reminders", err)
+		return
+	}
+	h.renderComponent(w, r, status, "reminders", data)
+}
+
+// SnoozeReminder moves a reminder to a later day and returns its updated
+// row.
+func (h *Handler) SnoozeReminder(w http.ResponseWriter, r *http.Request) {
+	h.changeReminder(w, r, func(rem *model.Reminder, now time.Time) bool {
+		due, ok := reminderDue(now, r.FormValue("for"))
+		if ok {
+			rem.Snooze(due)
+		}
+		return ok
+	})
+}
+
+// CompleteReminder marks a reminder done and returns its updated row.
+func (h *Handler) CompleteReminder(w http.ResponseWriter, r *http.Request) {
+	h.changeReminder(w, r, func(rem *model.Reminder, now time.Time) bool {
+		rem.CompletedAt = now
+		return true
+	})
+}
+
+// changeReminder applies change to the reminder in the path at the current
+// time in the user's timezone. A change returning false is a bad request.
+func (h *Handler) changeReminder(w http.ResponseWriter, r *http.Request, change func(*model.Reminder, time.Time) bool) {
+	rem, err := h.store.GetReminder(r.Context(), r.PathValue("rid"))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "get reminder", err)
+		return
+	}
+
+	tz := timezone(r)
+	if !change(&rem, time.Now().In(tmpl.Location(tz))) {
+		http.Error(w, "invalid snooze", http.StatusBadRequest)
+		return
+	}
+	if rem, err = h.store.UpdateReminder(r.Context(), rem); err != nil {
+		h.serverError(w, r, "update reminder", err)
+		return
+	}
+	slog.Info("reminder updated", "id", rem.ID, "due", rem.Due, "completed", rem.Completed())
+
+	if !isHTMX(r) {
+		http.Redirect(w, r, "/reminders", http.StatusSeeOther)
+		return
+	}
+	v, err := h.newReminderView(r.Context(), rem, tz, r.FormValue("show_contact") != "")
+	if err != nil {
+		h.serverError(w, r, "get contact", err)
+		return
+	}
+	h.renderComponent(w, r, http.StatusOK, "reminder-row", v)
+}
+
+// ListNotifications renders notifications newest first and marks them all
+// read, telling the nav badge to refresh.
+func (h *Handler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	list, err := h.store.ListNotifications(r.Context())
+	if err != nil {
+		h.serverError(w, r, "list notifications", err)
+		return
+	}
+
+	data := notificationsData{TZ: timezone(r)}
+	for _, n := range list {
+		c, err := h.store.Get(r.Context(), n.ContactID)
+		if err != nil {
+			h.serverError(w, r, "get contact", err)
+			return
+		}
+		data.Notifications = append(data.Notifications, notificationView{Notification: n, Contact: c})
+	}
+	if err := h.store.MarkNotificationsRead(r.Context()); err != nil {
+		h.serverError(w, r, "mark notifications read", err)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", "notifications-read")
+	h.renderPage(w, r, http.StatusOK, "notifications", data)
+}
+
+// NotificationBadge returns the nav link to notifications with the unread
+// count, which polls for updates.
+func (h *Handler) NotificationBadge(w http.ResponseWriter, r *http.Request) {
+	unread, err := h.store.UnreadNotifications(r.Context())
+	if err != nil {
+		h.serverError(w, r, "count notifications", err)
+		return
+	}
+	h.renderComponent(w, r, http.StatusOK, "notification-badge", unread)
+}
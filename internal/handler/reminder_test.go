@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListReminders(t *testing.T) {
+	h, _ := setupTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/reminders", nil)
+	rec := httptest.NewRecorder()
+	h.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	overdue, today, upcoming := strings.Index(body, "Overdue"), strings.Index(body, "Due Today"), strings.Index(body, "Upcoming")
+	carol, bob, david := strings.Index(body, "Carol Williams"), strings.Index(body, "Bob Smith"), strings.Index(body, "David Brown")
+	if overdue < 0 || today < 0 || upcoming < 0 {
+		t.Fatalf("expected overdue, today and upcoming sections:\n%s", body)
+	}
+	if !(overdue < carol && carol < today && today < bob && bob < upcoming && upcoming < david) {
+		t.Error("expected Carol overdue, Bob due today and David upcoming")
+	}
+}
+
+func TestAddReminder(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	form := url.Values{"note": {"Send the contract"}, "in": {"2w"}}
+	rec := postForm(mux, "/contacts/1/reminders", form, true)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "Send the contract") {
+		t.Errorf("expected new reminder in response:\n%s", rec.Body.String())
+	}
+
+	list, _ := s.ListReminders(context.Background(), "1")
+	if len(list) != 1 {
+		t.Fatalf("expected one reminder for Alice, got %+v", list)
+	}
+	want := time.Now().UTC().AddDate(0, 0, 14)
+	if due := list[0].Due; due.Hour() != reminderHour || due.YearDay() != want.YearDay() {
+		t.Errorf("expected reminder due at %d:00 in two weeks, got %v", reminderHour, due)
+	}
+}
+
+func TestReminderDue_DST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone data")
+	}
+	// Clocks in New York went forward on March 8, 2026.
+	now := time.Date(2026, 3, 7, 12, 0, 0, 0, ny)
+	due, ok := reminderDue(now, "1d")
+	if want := time.Date(2026, 3, 8, reminderHour, 0, 0, 0, ny); !ok || !due.Equal(want) {
+		t.Errorf("reminderDue(%v, 1d) = %v, want %v", now, due, want)
+	}
+}
+
+func TestAddReminder_Date(t *testing.T) {
+	h, s := setupTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/contacts/1/reminders", strings.NewReader("date=2030-06-01&in=1d"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "tz", Value: "America/New_York"})
+	rec := httptest.NewRecorder()
+	h.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d", rec.Code)
+	}
+	list, _ := s.ListReminders(context.Background(), "1")
+	if len(list) != 1 || !list[0].Due.Equal(time.Date(2030, 6, 1, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 9:00 New York time on the chosen date, got %+v", list)
+	}
+}
+
+func TestAddReminder_ValidationError(t *testing.T) {
+	h, _ := setupTestHandler(t)
+
+	rec := postForm(h.Routes(), "/contacts/1/reminders", url.Values{"note": {"Keep me"}, "date": {"soon"}}, true)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Enter a valid date") || !strings.Contains(body, `value="Keep me"`) {
+		t.Errorf("expected error with the note kept:\n%s", body)
+	}
+}
+
+func TestSnoozeReminder(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	// Reminder 1 is Carol's overdue follow-up.
+	rec := postForm(mux, "/reminders/1/snooze", url.Values{"for": {"1w"}, "show_contact": {"true"}}, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `id="reminder-1"`) || strings.Contains(body, "reminder-overdue") || !strings.Contains(body, "Carol Williams") {
+		t.Errorf("expected snoozed row:\n%s", body)
+	}
+	r, _ := s.GetReminder(context.Background(), "1")
+	if !r.Due.After(time.Now().AddDate(0, 0, 6)) || !r.NotifiedAt.IsZero() {
+		t.Errorf("expected reminder moved a week out and undelivered, got %+v", r)
+	}
+
+	if rec := postForm(mux, "/reminders/1/snooze", url.Values{"for": {"forever"}}, true); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown snooze, got %d", rec.Code)
+	}
+	if rec := postForm(mux, "/reminders/999/snooze", url.Values{"for": {"1d"}}, true); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestCompleteReminder(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	rec := postForm(mux, "/reminders/3/complete", nil, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "reminder-done") {
+		t.Errorf("expected completed row:\n%s", rec.Body.String())
+	}
+	if list, _ := s.ListReminders(context.Background(), "4"); len(list) != 0 {
+		t.Errorf("expected no open reminders for David, got %+v", list)
+	}
+
+	rec = postForm(mux, "/reminders/2/complete", nil, false)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/reminders" {
+		t.Errorf("expected redirect to /reminders, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	ctx := context.Background()
+
+	if _, err := s.DeliverDueReminders(ctx, time.Now()); err != nil {
+		t.Fatalf("DeliverDueReminders: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/notifications/badge", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), `<span class="badge" aria-label="2 unread notifications">2</span>`) {
+		t.Errorf("expected unread badge:\n%s", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/notifications", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	if !strings.Contains(body, "Follow up on the proposal") || !strings.Contains(body, "notification-unread") {
+		t.Errorf("expected unread notifications listed:\n%s", body)
+	}
+	if rec.Header().Get("HX-Trigger") != "notifications-read" {
+		t.Error("expected badge refresh trigger")
+	}
+	if unread, _ := s.UnreadNotifications(ctx); unread != 0 {
+		t.Errorf("expected notifications marked read, %d unread", unread)
+	}
+}
+
+func TestShowContact_Reminders(t *testing.T) {
+	h, _ := setupTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/contacts/3", nil)
+	rec := httptest.NewRecorder()
+	h.Routes().ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "Follow up on the proposal") || !strings.Contains(body, `action="/contacts/3/reminders"`) {
+		t.Errorf("expected Carol's reminder and form:\n%s", body)
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/devaloi/htmxapp/internal/tmpl"
+)
+
+type errorData struct {
+	Status    int
+	RequestID string
+}
+
+// renderPage writes a page with the given status. Boosted htmx navigation
+// only receives the page's content block, so the same URL serves two
+// representations and the response must vary on the htmx headers as well
+// as the locale inputs.
+func (h *Handler) renderPage(w http.ResponseWriter, r *http.Request, status int, name string, data any) {
+	w.Header().Add("Vary", "HX-Request")
+	w.Header().Add("Vary", "HX-Boosted")
+	varyOnLocale(w)
+	view := h.view(r)
+
+	var err error
+	if isBoosted(r) {
+		// Only successful GETs are navigations worth a history entry;
+		// failed form submissions stay on the page they were posted from.
+		if r.Method == http.MethodGet && status == http.StatusOK {
+			w.Header().Set("HX-Push-Url", r.URL.RequestURI())
+		} else {
+			w.Header().Set("HX-Push-Url", "false")
+		}
+		err = view.WriteContent(w, status, name, data)
+	} else {
+		err = view.WritePage(w, status, name, data)
+	}
+	if err != nil {
+		h.renderFailed(w, r, "page", name, err)
+	}
+}
+
+// renderPartial writes a partial template with the given status.
+func (h *Handler) renderPartial(w http.ResponseWriter, r *http.Request, status int, name string, data any) {
+	varyOnLocale(w)
+	if err := h.view(r).WritePartial(w, status, name, data); err != nil {
+		h.renderFailed(w, r, "partial", name, err)
+	}
+}
+
+// renderComponent writes a single shared component with the given status.
+func (h *Handler) renderComponent(w http.ResponseWriter, r *http.Request, status int, name string, data any) {
+	varyOnLocale(w)
+	if err := h.view(r).WriteComponent(w, status, name, data); err != nil {
+		h.renderFailed(w, r, "component", name, err)
+	}
+}
+
+func (h *Handler) renderFailed(w http.ResponseWriter, r *http.Request, kind, name string, err error) {
+	if errors.Is(err, tmpl.ErrCommitted) {
+		slog.Warn("write response", "template", name, "error", err, "request_id", RequestID(r.Context()))
+		return
+	}
+	h.serverError(w, r, "render "+kind, err, "template", name)
+}
+
+// serverError logs err and responds with a 500 that carries the request ID,
+// so a user's report can be matched to the log line. htmx requests get an
+// alert fragment retargeted into the layout; everything else gets a page.
+func (h *Handler) serverError(w http.ResponseWriter, r *http.Request, msg string, err error, args ...any) {
+	id := RequestID(r.Context())
+	slog.Error(msg, append(args, "error", err, "request_id", id)...)
+
+	w.Header().Del("HX-Push-Url")
+	data := errorData{Status: http.StatusInternalServerError, RequestID: id}
+
+	var renderErr error
+	if isHTMX(r) {
+		w.Header().Set("HX-Retarget", "#alerts")
+		w.Header().Set("HX-Reswap", "innerHTML")
+		renderErr = h.view(r).WritePartial(w, data.Status, "error-alert", data)
+	} else {
+		renderErr = h.view(r).WritePage(w, data.Status, "error", data)
+	}
+	if renderErr != nil {
+		slog.Error("render error page", "error", renderErr, "request_id", id)
+		if !errors.Is(renderErr, tmpl.ErrCommitted) {
+			http.Error(w, "Internal Server Error (request "+id+")", http.StatusInternalServerError)
+		}
+	}
+}
+
+func varyOnLocale(w http.ResponseWriter) {
+	w.Header().Add("Vary", "Accept-Language")
+	w.Header().Add("Vary", "Cookie")
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+// listParams are the contacts list parameters besides the filter.
+var listParams = []string{"q", "starred", "contacted", "sort"}
+
+// savedSearchView is a saved search with the number of contacts it
+// currently matches.
+type savedSearchView struct {
+	Search model.SavedSearch
+	Count  int
+}
+
+// savedSearchesData is the contacts page's sidebar of saved searches.
+type savedSearchesData struct {
+	Searches []savedSearchView
+
+	// Active is the ID of the saved search the list was opened from.
+	Active string
+
+	// Name is the name being saved, kept when it had errors.
+	Name   string
+	Errors map[string]string
+}
+
+// keptParams returns the list parameters of v a saved search keeps,
+// leaving out the filter and empty values.
+func keptParams(v url.Values) url.Values {
+	kept := make(url.Values)
+	for _, k := range listParams {
+		if val := v.Get(k); val != "" {
+			kept.Set(k, val)
+		}
+	}
+	return kept
+}
+
+// savedQuery encodes the list parameters of v that a saved search keeps,
+// with its filter.
+func savedQuery(v url.Values) string {
+	kept := keptParams(v)
+	maps.Copy(kept, store.ParseFilter(v).Values())
+	return kept.Encode()
+}
+
+// listValues returns the contacts list parameters in v. With a list
+// parameter they start from that saved search's, and parameters in v
+// override them, so sorting a saved search keeps it open. A filter in v
+// replaces the saved filter as a whole.
+func (h *Handler) listValues(ctx context.Context, v url.Values) (url.Values, error) {
+	id := v.Get("list")
+	if id == "" {
+		return v, nil
+	}
+	s, err := h.store.GetSavedSearch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	merged, _ := url.ParseQuery(s.Query)
+	for _, k := range listParams {
+		if v.Has(k) {
+			merged.Set(k, v.Get(k))
+		}
+	}
+	if f := store.ParseFilter(v); !f.IsZero() {
+		maps.DeleteFunc(merged, func(k string, _ []string) bool { return store.IsFilterParam(k) })
+		maps.Copy(merged, f.Values())
+	}
+	merged.Set("list", id)
+	return merged, nil
+}
+
+// loadSavedSearches returns the user's saved searches with their counts,
+// marking the one with ID active.
+func (h *Handler) loadSavedSearches(ctx context.Context, active string) (savedSearchesData, error) {
+	list, err := h.store.ListSavedSearches(ctx, UserID(ctx))
+	if err != nil {
+		return savedSearchesData{}, err
+	}
+	data := savedSearchesData{Active: active}
+	for _, s := range list {
+		v, _ := url.ParseQuery(s.Query)
+		q, _ := listQuery(v, UserID(ctx))
+		n, err := h.store.CountMatching(ctx, q)
+		if err != nil {
+			return savedSearchesData{}, err
+		}
+		data.Searches = append(data.Searches, savedSearchView{Search: s, Count: n})
+	}
+	return data, nil
+}
+
+// SavedSearches returns the saved searches sidebar, which polls to keep
+// its counts current.
+func (h *Handler) SavedSearches(w http.ResponseWriter, r *http.Request) {
+	data, err := h.loadSavedSearches(r.Context(), r.URL.Query().Get("list"))
+	if err != nil {
+		h.serverError(w, r, "list saved searches", err)
+		return
+	}
+	h.renderComponent(w, r, http.StatusOK, "saved-searches", data)
+}
+
+// SaveSearch saves the contacts list's current search, filters and sort
+// under a name, and opens it so its URL can be shared.
+func (h *Handler) SaveSearch(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	s := model.SavedSearch{
+		Owner: UserID(r.Context()),
+		Name:  r.PostFormValue("name"),
+		Query: savedQuery(r.PostForm),
+	}
+
+	errs := s.Validate()
+	if len(errs) == 0 {
+		saved, err := h.store.SaveSearch(r.Context(), s)
+		switch {
+		case errors.Is(err, model.ErrDuplicateSearch):
+			errs["Name"] = model.CodeDuplicate
+		case err != nil:
+			h.serverError(w, r, "save search", err)
+			return
+		default:
+			slog.Info("search saved", "id", saved.ID, "name", saved.Name)
+			target := "/contacts?list=" + saved.ID
+			if !isHTMX(r) {
+				http.Redirect(w, r, target, http.StatusSeeOther)
+				return
+			}
+			w.Header().Set("HX-Push-Url", target)
+			h.renderSavedSearches(w, r, http.StatusOK, saved.ID, "", nil)
+			return
+		}
+	}
+
+	if isHTMX(r) {
+		h.renderSavedSearches(w, r, http.StatusUnprocessableEntity, "", s.Name, errs)
+		return
+	}
+	data, err := h.contactList(r.Context(), r.PostForm)
+	if err != nil {
+		h.serverError(w, r, "list contacts", err)
+		return
+	}
+	data.Saved.Name, data.Saved.Errors = s.Name, errs
+	h.renderPage(w, r, http.StatusUnprocessableEntity, "contacts", data)
+}
+
+// DeleteSavedSearch removes one of the user's saved searches and returns
+// the sidebar.
+func (h *Handler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("sid")
+	if err := h.store.DeleteSavedSearch(r.Context(), UserID(r.Context()), id); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "delete saved search", err)
+		return
+	}
+	slog.Info("saved search deleted", "id", id)
+
+	if !isHTMX(r) {
+		http.Redirect(w, r, "/contacts", http.StatusSeeOther)
+		return
+	}
+	active := r.URL.Query().Get("list")
+	if active == id {
+		active = ""
+	}
+	h.renderSavedSearches(w, r, http.StatusOK, active, "", nil)
+}
+
+// renderSavedSearches writes the saved searches sidebar.
+func (h *Handler) renderSavedSearches(w http.ResponseWriter, r *http.Request, status int, active, name string, errs map[string]string) {
+	data, err := h.loadSavedSearches(r.Context(), active)
+	if err != nil {
+		h.serverError(w, r, "list saved searches", err)
+		return
+	}
+	data.Name, data.Errors = name, errs
+	h.renderComponent(w, r, status, "saved-searches", data)
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+func TestSaveSearch(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+
+	form := url.Values{"name": {"Ro people"}, "q": {"ro"}, "contacted": {""}, "sort": {"-name"}}
+	rec := postForm(mux, "/searches", form, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	list, _ := s.ListSavedSearches(context.Background(), "")
+	if len(list) != 1 || list[0].Query != "q=ro&sort=-name" {
+		t.Fatalf("expected the search saved without empty parameters, got %+v", list)
+	}
+	if got := rec.Header().Get("HX-Push-Url"); got != "/contacts?list="+list[0].ID {
+		t.Errorf("HX-Push-Url = %q", got)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, ">Ro people</a>") || !strings.Contains(body, `<span class="count" aria-label="2 contacts">2</span>`) {
+		t.Errorf("expected the saved search with its count:\n%s", body)
+	}
+
+	rec = postForm(mux, "/searches", url.Values{"name": {"ro PEOPLE"}}, true)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "A saved search with this name already exists") {
+		t.Errorf("duplicate: expected 422 with the error, got %d:\n%s", rec.Code, rec.Body)
+	}
+	rec = postForm(mux, "/searches", url.Values{"name": {" "}, "q": {"ro"}}, false)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "Name the search to save it") || !strings.Contains(rec.Body.String(), `value="ro"`) {
+		t.Errorf("blank name: expected the contacts page with 422, got %d", rec.Code)
+	}
+}
+
+func TestListContacts_SavedSearch(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	saved, _ := s.SaveSearch(context.Background(), model.SavedSearch{Name: "Ro people", Query: "q=ro&sort=-name"})
+
+	get := func(path string) string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", path, rec.Code)
+		}
+		return rec.Body.String()
+	}
+
+	body := get("/contacts?list=" + saved.ID)
+	if strings.Contains(body, "Alice Johnson") || !strings.Contains(body, `value="ro"`) {
+		t.Error("expected the saved search applied")
+	}
+	if i, j := strings.Index(body, `id="contact-3"`), strings.Index(body, `id="contact-4"`); i < 0 || j < 0 || i > j {
+		t.Error("expected the saved sort applied")
+	}
+	if !strings.Contains(body, `aria-current="page">Ro people</a>`) {
+		t.Error("expected the saved search marked current")
+	}
+	if !strings.Contains(body, `href="/contacts?list=`+saved.ID+`&amp;q=ro&amp;sort=name"`) {
+		t.Error("expected sort links to keep the saved search open")
+	}
+
+	// Parameters in the URL override the saved ones.
+	body = get("/contacts?list=" + saved.ID + "&sort=name")
+	if i, j := strings.Index(body, `id="contact-3"`), strings.Index(body, `id="contact-4"`); i < j {
+		t.Error("expected the sort parameter to override the saved sort")
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contacts?list=999", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown saved search: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestSavedSearches_Counts(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	saved, _ := s.SaveSearch(context.Background(), model.SavedSearch{Name: "Ro people", Query: "q=ro"})
+
+	req := httptest.NewRequest(http.MethodDelete, "/contacts/4", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if got := rec.Header().Get("HX-Trigger"); got != "contacts-changed" {
+		t.Errorf("HX-Trigger = %q, want contacts-changed", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/searches?list="+saved.ID, nil)
+	req.Header.Set("HX-Request", "true")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	if !strings.Contains(body, `aria-label="1 contact">1</span>`) || !strings.Contains(body, `hx-get="/searches?list=`+saved.ID+`"`) {
+		t.Errorf("expected the updated count:\n%s", body)
+	}
+}
+
+func TestDeleteSavedSearch(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	saved, _ := s.SaveSearch(context.Background(), model.SavedSearch{Name: "Ro people", Query: "q=ro"})
+
+	req := httptest.NewRequest(http.MethodDelete, "/searches/"+saved.ID+"?list="+saved.ID, nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "Ro people") || !strings.Contains(rec.Body.String(), `hx-get="/searches"`) {
+		t.Errorf("expected the sidebar without the search, got %d:\n%s", rec.Code, rec.Body)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/searches/"+saved.ID, nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("deleting twice: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestSavedSearches_PerUser(t *testing.T) {
+	h, _ := setupTestHandler(t)
+	mux := UserMiddleware(h.Routes())
+
+	// visit returns a cookie identifying a new browser.
+	visit := func() *http.Cookie {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contacts", nil))
+		cookies := rec.Result().Cookies()
+		if len(cookies) != 1 {
+			t.Fatalf("expected a user cookie, got %v", cookies)
+		}
+		return cookies[0]
+	}
+	do := func(user *http.Cookie, req *http.Request) *httptest.ResponseRecorder {
+		req.AddCookie(user)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	save := func(user *http.Cookie, name string) string {
+		t.Helper()
+		form := url.Values{"name": {name}, "q": {"ro"}}
+		req := httptest.NewRequest(http.MethodPost, "/searches", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := do(user, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("saving %q: expected 200, got %d", name, rec.Code)
+		}
+		return strings.TrimPrefix(rec.Header().Get("HX-Push-Url"), "/contacts?list=")
+	}
+
+	alice, bob := visit(), visit()
+	mine := save(alice, "Ro people")
+	save(bob, "ro people") // names only clash within one user's searches
+
+	sidebar := func(user *http.Cookie) string {
+		return do(user, httptest.NewRequest(http.MethodGet, "/searches", nil)).Body.String()
+	}
+	if got := strings.Count(sidebar(alice), `class="saved-search-remove"`); got != 1 {
+		t.Errorf("expected one saved search in each sidebar, alice has %d", got)
+	}
+	if strings.Contains(sidebar(bob), "Ro people") {
+		t.Error("expected bob not to see alice's saved search")
+	}
+
+	// A shared URL opens for anyone, but only its owner can delete it.
+	if rec := do(bob, httptest.NewRequest(http.MethodGet, "/contacts?list="+mine, nil)); rec.Code != http.StatusOK {
+		t.Errorf("opening a shared search: expected 200, got %d", rec.Code)
+	}
+	if rec := do(bob, httptest.NewRequest(http.MethodDelete, "/searches/"+mine, nil)); rec.Code != http.StatusNotFound {
+		t.Errorf("deleting another user's search: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestDeleteContacts_SavedSearch(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	ctx := context.Background()
+	saved, _ := s.SaveSearch(ctx, model.SavedSearch{Name: "Ro people", Query: "q=ro"})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contacts?list="+saved.ID, nil))
+	if page := rec.Body.String(); !strings.Contains(page, `name="list" value="`+saved.ID+`"`) {
+		t.Errorf("expected a form deleting the saved search's contacts:\n%s", page)
+	}
+
+	rec = postForm(mux, "/contacts/delete", url.Values{"list": {saved.ID}}, true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	list, _ := s.List(ctx, store.Query{})
+	if len(list) != 3 {
+		t.Errorf("expected Carol and David deleted, %d contacts left", len(list))
+	}
+	for _, c := range list {
+		if c.ID == "3" || c.ID == "4" {
+			t.Errorf("expected %s deleted", c.FullName())
+		}
+	}
+	if strings.Contains(rec.Body.String(), `id="contact-`) {
+		t.Errorf("expected no rows left in the list:\n%s", rec.Body.String())
+	}
+
+	rec = postForm(mux, "/contacts/delete", url.Values{"list": {saved.ID}}, false)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/contacts?list="+saved.ID {
+		t.Errorf("expected a redirect to the saved search, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec = postForm(mux, "/contacts/delete", url.Values{"list": {"999"}}, true); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown saved search: expected 404, got %d", rec.Code)
+	}
+	if rec = postForm(mux, "/contacts/delete", url.Values{}, true); rec.Code != http.StatusBadRequest {
+		t.Errorf("no list: expected 400, got %d", rec.Code)
+	}
+}
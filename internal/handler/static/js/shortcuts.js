@@ -0,0 +1,80 @@
+// Keyboard shortcuts. Ctrl+K (⌘K on a Mac) opens the command palette,
+// whose results the server renders. In a list marked data-shortcuts, j
+// and k move between rows and other keys press the focused row's control
+// marked with that key in data-shortcut.
+(() => {
+    const palette = () => document.getElementById("palette");
+
+    function openPalette() {
+        const dialog = palette();
+        const input = document.getElementById("palette-input");
+        if (!dialog.open) dialog.showModal();
+        input.value = "";
+        input.focus();
+        htmx.trigger(input, "open-palette");
+    }
+
+    // typing reports whether the key was pressed in a field that takes
+    // text, where shortcuts must not get in the way.
+    function typing(el) {
+        if (el.isContentEditable || el instanceof HTMLSelectElement || el instanceof HTMLTextAreaElement) return true;
+        return el instanceof HTMLInputElement && el.type !== "checkbox" && el.type !== "radio";
+    }
+
+    // move focuses the next or previous of items from the one containing
+    // the focus, or the first.
+    function move(items, step) {
+        if (!items.length) return;
+        const i = items.findIndex((el) => el.contains(document.activeElement));
+        const next = i < 0 ? 0 : Math.min(Math.max(i + step, 0), items.length - 1);
+        items[next].focus();
+        items[next].scrollIntoView({ block: "nearest" });
+    }
+
+    document.addEventListener("keydown", (e) => {
+        if ((e.ctrlKey || e.metaKey) && e.key.toLowerCase() === "k") {
+            e.preventDefault();
+            openPalette();
+            return;
+        }
+
+        const dialog = palette();
+        if (dialog && dialog.open) {
+            const links = [...dialog.querySelectorAll("#palette-results a")];
+            if (e.key === "ArrowDown" || e.key === "ArrowUp") {
+                e.preventDefault();
+                move(links, e.key === "ArrowDown" ? 1 : -1);
+            } else if (e.key === "Enter" && e.target.id === "palette-input" && links.length) {
+                e.preventDefault();
+                links[0].click();
+            }
+            return;
+        }
+
+        if (e.ctrlKey || e.metaKey || e.altKey || typing(e.target)) return;
+        const list = document.querySelector("[data-shortcuts]");
+        if (!list) return;
+        const rows = [...list.querySelectorAll(":scope > [tabindex]")];
+        if (e.key === "j" || e.key === "k") {
+            e.preventDefault();
+            move(rows, e.key === "j" ? 1 : -1);
+            return;
+        }
+        const row = rows.find((el) => el.contains(document.activeElement));
+        const control = row && [...row.querySelectorAll("[data-shortcut]")].find((el) => el.dataset.shortcut === e.key);
+        if (control) {
+            e.preventDefault();
+            control.click();
+        }
+    });
+
+    document.addEventListener("click", (e) => {
+        if (e.target.closest("[data-palette]")) {
+            openPalette();
+        } else if (e.target.closest("#palette a")) {
+            palette().close();
+        } else if (e.target === palette()) {
+            palette().close();
+        }
+    });
+})();
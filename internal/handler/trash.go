@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// trashRow is a contact in the trash, with the error that kept it from
+// being restored, if any.
+type trashRow struct {
+	model.TrashedContact
+	TZ     string
+	Errors map[string]string
+}
+
+// ListTrash renders the deleted contacts, most recently deleted first.
+func (h *Handler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	trash, err := h.store.ListTrash(r.Context())
+	if err != nil {
+		h.serverError(w, r, "list trash", err)
+		return
+	}
+	h.renderPage(w, r, http.StatusOK, "trash", newTrashRows(trash, timezone(r)))
+}
+
+func newTrashRows(trash []model.TrashedContact, tz string) []trashRow {
+	rows := make([]trashRow, len(trash))
+	for i, t := range trash {
+		rows[i] = trashRow{TrashedContact: t, TZ: tz}
+	}
+	return rows
+}
+
+// RestoreContact takes a contact out of the trash and returns empty
+// content for htmx swap. If another contact has taken its email, it
+// re-renders the contact's row with the error.
+func (h *Handler) RestoreContact(w http.ResponseWriter, r *http.Request) {
+	c, err := h.store.RestoreContact(r.Context(), r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrNotFound):
+			http.NotFound(w, r)
+		case errors.Is(err, model.ErrDuplicateEmail):
+			h.renderTrashError(w, r, map[string]string{"Email": model.CodeDuplicate})
+		default:
+			h.serverError(w, r, "restore contact", err)
+		}
+		return
+	}
+	slog.Info("contact restored", "id", c.ID)
+
+	if isHTMX(r) {
+		w.Header().Set("HX-Trigger", "contacts-changed")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Redirect(w, r, "/contacts/"+c.ID, http.StatusSeeOther)
+}
+
+// renderTrashError re-renders the row of the contact in the path with
+// errs, or the trash page without htmx.
+func (h *Handler) renderTrashError(w http.ResponseWriter, r *http.Request, errs map[string]string) {
+	trash, err := h.store.ListTrash(r.Context())
+	if err != nil {
+		h.serverError(w, r, "list trash", err)
+		return
+	}
+	rows := newTrashRows(trash, timezone(r))
+	for i := range rows {
+		if rows[i].Contact.ID != r.PathValue("id") {
+			continue
+		}
+		rows[i].Errors = errs
+		if isHTMX(r) {
+			h.renderComponent(w, r, http.StatusUnprocessableEntity, "trash-row", rows[i])
+			return
+		}
+	}
+	h.renderPage(w, r, http.StatusUnprocessableEntity, "trash", rows)
+}
+
+// PurgeContact deletes a contact in the trash for good with its photo and
+// attachments, and returns empty content for htmx swap.
+func (h *Handler) PurgeContact(w http.ResponseWriter, r *http.Request) {
+	t, err := h.store.PurgeContact(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.serverError(w, r, "purge contact", err)
+		return
+	}
+	h.deletePhoto(r.Context(), t.Contact.PhotoID)
+	h.releaseAttachments(r.Context(), t.Attachments)
+	slog.Info("contact purged", "id", t.Contact.ID)
+
+	if isHTMX(r) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Redirect(w, r, "/trash", http.StatusSeeOther)
+}
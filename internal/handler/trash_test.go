@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestTrash(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	ctx := context.Background()
+	s.AddNote(ctx, model.Note{ContactID: "1", Body: "Prefers email."})
+
+	req := httptest.NewRequest(http.MethodDelete, "/contacts/1", nil)
+	req.Header.Set("HX-Request", "true")
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/trash", nil))
+	page := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(page, `id="trash-1"`) || !strings.Contains(page, "Alice Johnson") {
+		t.Fatalf("expected Alice in the trash, got %d:\n%s", rec.Code, page)
+	}
+
+	rec = postForm(mux, "/trash/1/restore", nil, true)
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 || rec.Header().Get("HX-Trigger") != "contacts-changed" {
+		t.Fatalf("restore: got %d %q", rec.Code, rec.Body)
+	}
+	if notes, _ := s.ListNotes(ctx, "1"); len(notes) != 1 {
+		t.Errorf("expected Alice's note back, got %+v", notes)
+	}
+	if rec := postForm(mux, "/trash/1/restore", nil, true); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 restoring twice, got %d", rec.Code)
+	}
+
+	// Without htmx, restoring opens the contact and purging goes back to
+	// the trash.
+	postForm(mux, "/contacts/delete", url.Values{"id": {"2", "3"}}, false)
+	rec = postForm(mux, "/trash/2/restore", nil, false)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/contacts/2" {
+		t.Errorf("expected a redirect to Bob, got %d %s", rec.Code, rec.Header().Get("Location"))
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/trash/3", nil))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/trash" {
+		t.Errorf("expected a redirect to the trash, got %d %s", rec.Code, rec.Header().Get("Location"))
+	}
+	if _, err := s.RestoreContact(ctx, "3"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected Carol purged, got %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/trash", nil))
+	if !strings.Contains(rec.Body.String(), "The trash is empty.") {
+		t.Errorf("expected an empty trash:\n%s", rec.Body)
+	}
+}
+
+func TestRestoreContact_DuplicateEmail(t *testing.T) {
+	h, s := setupTestHandler(t)
+	mux := h.Routes()
+	ctx := context.Background()
+
+	alice, _ := s.Get(ctx, "1")
+	s.Delete(ctx, "1")
+	s.Create(ctx, model.Contact{FirstName: "Alicia", Email: alice.Email})
+
+	rec := postForm(mux, "/trash/1/restore", nil, true)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "A contact with this email already exists") {
+		t.Fatalf("expected the row with the error, got %d:\n%s", rec.Code, rec.Body)
+	}
+	if _, err := s.Get(ctx, "1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected Alice to stay in the trash, got %v", err)
+	}
+}
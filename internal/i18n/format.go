@@ -0,0 +1,35 @@
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Date formats t's calendar date in the printer's locale, e.g.
+// "Apr 12, 1985" or "12 abr 1985".
+func (p *Printer) Date(t time.Time) string {
+	return p.T("format.date", "month", p.month(t.Month()), "day", t.Day(), "year", t.Year())
+}
+
+// MonthDay formats t's day and month in the printer's locale, e.g.
+// "Apr 12" or "12 abr".
+func (p *Printer) MonthDay(t time.Time) string {
+	return p.T("format.month_day", "month", p.month(t.Month()), "day", t.Day())
+}
+
+// DateTime formats t's date and time of day, with its zone abbreviation,
+// in the printer's locale, e.g. "Apr 12, 1985 3:04 PM UTC". The
+// "format.clock" message picks a 12- or 24-hour clock.
+func (p *Printer) DateTime(t time.Time) string {
+	clock := fmt.Sprintf("%d:%02d", t.Hour(), t.Minute())
+	if p.T("format.clock") == "12" {
+		clock = t.Format("3:04 PM")
+	}
+	return p.T("format.date_time", "date", p.Date(t), "time", clock, "zone", t.Format("MST"))
+}
+
+// month returns the abbreviated name of m.
+func (p *Printer) month(m time.Month) string {
+	return p.T("month.short." + strconv.Itoa(int(m)))
+}
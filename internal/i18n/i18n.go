@@ -0,0 +1,281 @@
+// Package i18n loads message catalogs and negotiates the locale used to
+// render each request.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// DefaultLocale is used when nothing the client asks for is available. Its
+// catalog is the reference every other catalog must match.
+const DefaultLocale = "en"
+
+// message holds one translation. Simple messages only have an "other"
+// form; pluralized messages have one form per CLDR plural category.
+type message map[string]string
+
+func (m *message) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*m = message{"other": s}
+		return nil
+	}
+	var forms map[string]string
+	if err := json.Unmarshal(b, &forms); err != nil {
+		return fmt.Errorf("message must be a string or an object of plural forms: %w", err)
+	}
+	*m = forms
+	return nil
+}
+
+// Catalog holds the messages for one locale.
+type Catalog struct {
+	Locale   string
+	messages map[string]message
+}
+
+// Name is the language's own name for itself, for the language switcher.
+func (c *Catalog) Name() string {
+	return c.messages["language.name"]["other"]
+}
+
+// Bundle holds the catalogs for every supported locale.
+type Bundle struct {
+	catalogs map[string]*Catalog
+	locales  []string
+}
+
+// Default loads the catalogs embedded in the binary.
+func Default() (*Bundle, error) {
+	sub, err := fs.Sub(localeFS, "locales")
+	if err != nil {
+		return nil, err
+	}
+	return Load(sub)
+}
+
+// Load reads every <locale>.json file in fsys. The default locale must be
+// present.
+func Load(fsys fs.FS) (*Bundle, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, fmt.Errorf("globbing catalogs: %w", err)
+	}
+
+	b := &Bundle{catalogs: make(map[string]*Catalog)}
+	for _, file := range files {
+		data, readErr := fs.ReadFile(fsys, file)
+		if readErr != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, readErr)
+		}
+		locale := strings.TrimSuffix(path.Base(file), ".json")
+		c := &Catalog{Locale: locale}
+		if jsonErr := json.Unmarshal(data, &c.messages); jsonErr != nil {
+			return nil, fmt.Errorf("parsing %s: %w", file, jsonErr)
+		}
+		b.catalogs[locale] = c
+		b.locales = append(b.locales, locale)
+	}
+	if _, ok := b.catalogs[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("missing catalog for default locale %q", DefaultLocale)
+	}
+	sort.Strings(b.locales)
+	return b, nil
+}
+
+// Locales returns the supported locales in sorted order.
+func (b *Bundle) Locales() []string {
+	return slices.Clone(b.locales)
+}
+
+// Catalog returns the catalog for locale, or nil if it isn't supported.
+func (b *Bundle) Catalog(locale string) *Catalog {
+	return b.catalogs[locale]
+}
+
+// Match returns the first supported locale among the preferred language
+// tags, trying each tag's base language after the full tag. It falls back
+// to DefaultLocale.
+func (b *Bundle) Match(preferred ...string) string {
+	for _, tag := range preferred {
+		tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+		if _, ok := b.catalogs[tag]; ok {
+			return tag
+		}
+		if base, _, found := strings.Cut(tag, "-"); found {
+			if _, ok := b.catalogs[base]; ok {
+				return base
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// Printer translates messages for a single locale.
+type Printer struct {
+	locale   string
+	catalog  *Catalog
+	fallback *Catalog
+}
+
+// Printer returns a Printer for locale, which should come from Match.
+// Messages missing from its catalog fall back to the default locale.
+func (b *Bundle) Printer(locale string) *Printer {
+	c, ok := b.catalogs[locale]
+	if !ok {
+		locale, c = DefaultLocale, b.catalogs[DefaultLocale]
+	}
+	return &Printer{locale: locale, catalog: c, fallback: b.catalogs[DefaultLocale]}
+}
+
+// Locale returns the locale the printer translates into.
+func (p *Printer) Locale() string {
+	return p.locale
+}
+
+// T translates key. Args are name/value pairs substituted for {name}
+// placeholders. A "count" argument selects the plural form. Unknown keys
+// are returned as-is so gaps are visible rather than blank.
+func (p *Printer) T(key string, args ...any) string {
+	msg, ok := p.catalog.messages[key]
+	if !ok {
+		if msg, ok = p.fallback.messages[key]; !ok {
+			return key
+		}
+	}
+
+	form := "other"
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i] == "count" {
+			if n, isInt := toInt(args[i+1]); isInt {
+				form = PluralCategory(p.locale, n)
+			}
+		}
+	}
+	text, ok := msg[form]
+	if !ok {
+		text = msg["other"]
+	}
+
+	if len(args) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+fmt.Sprint(args[i])+"}", fmt.Sprint(args[i+1]))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case string:
+		i, err := strconv.Atoi(n)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// PluralCategory returns the CLDR plural category of n for a locale's
+// language: "one" or "other" for the languages whose integer rules need
+// nothing more, and "few"/"many" where they do.
+func PluralCategory(locale string, n int) string {
+	lang, _, _ := strings.Cut(locale, "-")
+	if n < 0 {
+		n = -n
+	}
+	switch lang {
+	case "ja", "ko", "zh", "th", "vi", "id":
+		return "other"
+	case "fr", "pt":
+		if n == 0 || n == 1 {
+			return "one"
+		}
+		if n != 0 && n%1000000 == 0 {
+			return "many"
+		}
+		return "other"
+	case "es", "it":
+		if n == 1 {
+			return "one"
+		}
+		if n != 0 && n%1000000 == 0 {
+			return "many"
+		}
+		return "other"
+	case "pl":
+		switch {
+		case n == 1:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "ru", "uk":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	default: // en, de, nl, sv and other one/other languages
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
+
+// ParseAcceptLanguage returns the language tags in an Accept-Language
+// header, most preferred first. Tags with q=0 are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag, q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = t.tag
+	}
+	return out
+}
@@ -0,0 +1,216 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestCatalogsComplete(t *testing.T) {
+	b, err := Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+	if len(b.Locales()) < 2 {
+		t.Fatalf("expected at least two bundled locales, got %v", b.Locales())
+	}
+
+	placeholder := regexp.MustCompile(`\{[a-z_]+\}`)
+	placeholders := func(m message) []string {
+		var found []string
+		for _, text := range m {
+			for _, p := range placeholder.FindAllString(text, -1) {
+				if !slices.Contains(found, p) {
+					found = append(found, p)
+				}
+			}
+		}
+		slices.Sort(found)
+		return found
+	}
+
+	ref := b.Catalog(DefaultLocale)
+	for _, locale := range b.Locales() {
+		c := b.Catalog(locale)
+		if c.Name() == "" {
+			t.Errorf("%s: missing language.name", locale)
+		}
+		for key, refMsg := range ref.messages {
+			msg, ok := c.messages[key]
+			if !ok {
+				t.Errorf("%s: missing message %q", locale, key)
+				continue
+			}
+			if got, want := placeholders(msg), placeholders(refMsg); !slices.Equal(got, want) {
+				t.Errorf("%s: %q has placeholders %v, want %v", locale, key, got, want)
+			}
+			if _, plural := refMsg["one"]; plural {
+				for _, form := range []string{"one", "other"} {
+					if msg[form] == "" {
+						t.Errorf("%s: %q is missing plural form %q", locale, key, form)
+					}
+				}
+			}
+		}
+		for key := range c.messages {
+			if _, ok := ref.messages[key]; !ok {
+				t.Errorf("%s: message %q is not in the %s catalog", locale, key, DefaultLocale)
+			}
+		}
+	}
+}
+
+func TestPrinter_T(t *testing.T) {
+	b, err := Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+	en, es := b.Printer("en"), b.Printer("es")
+
+	tests := []struct {
+		p    *Printer
+		key  string
+		args []any
+		want string
+	}{
+		{en, "contacts.new", nil, "New Contact"},
+		{es, "contacts.new", nil, "Nuevo contacto"},
+		{en, "contacts.count", []any{"count", 1}, "1 contact"},
+		{en, "contacts.count", []any{"count", 0}, "0 contacts"},
+		{es, "contacts.count", []any{"count", 1}, "1 contacto"},
+		{es, "contacts.count", []any{"count", 1000000}, "1000000 contactos"},
+		{en, "contacts.confirm_delete", []any{"name", "Ann Lee"}, "Move Ann Lee to the trash?"},
+		{en, "no.such.key", nil, "no.such.key"},
+	}
+	for _, tt := range tests {
+		if got := tt.p.T(tt.key, tt.args...); got != tt.want {
+			t.Errorf("%s T(%q, %v) = %q, want %q", tt.p.Locale(), tt.key, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestPrinter_Dates(t *testing.T) {
+	b, err := Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+	en, es := b.Printer("en"), b.Printer("es")
+	ts := time.Date(2026, time.August, 3, 0, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		got, want string
+	}{
+		{en.Date(ts), "Aug 3, 2026"},
+		{es.Date(ts), "3 ago 2026"},
+		{en.MonthDay(ts), "Aug 3"},
+		{es.MonthDay(ts), "3 ago"},
+		{en.DateTime(ts), "Aug 3, 2026 12:05 AM UTC"},
+		{en.DateTime(ts.Add(13 * time.Hour)), "Aug 3, 2026 1:05 PM UTC"},
+		{es.DateTime(ts.Add(13 * time.Hour)), "3 ago 2026, 13:05 UTC"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestPrinter_FallsBackToDefault(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.json": {Data: []byte(`{"greeting": "Hello", "only.en": "English only"}`)},
+		"de.json": {Data: []byte(`{"greeting": "Hallo"}`)},
+	}
+	b, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	de := b.Printer("de")
+	if got := de.T("greeting"); got != "Hallo" {
+		t.Errorf("expected Hallo, got %q", got)
+	}
+	if got := de.T("only.en"); got != "English only" {
+		t.Errorf("expected fallback to default locale, got %q", got)
+	}
+	if got := b.Printer("xx").Locale(); got != DefaultLocale {
+		t.Errorf("expected unsupported locale to use %s, got %s", DefaultLocale, got)
+	}
+}
+
+func TestLoad_RequiresDefaultLocale(t *testing.T) {
+	fsys := fstest.MapFS{"de.json": {Data: []byte(`{}`)}}
+	if _, err := Load(fsys); err == nil {
+		t.Error("expected error without a default locale catalog")
+	}
+}
+
+func TestBundle_Match(t *testing.T) {
+	b, err := Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+	tests := []struct {
+		prefs []string
+		want  string
+	}{
+		{nil, "en"},
+		{[]string{"es"}, "es"},
+		{[]string{"es-MX"}, "es"},
+		{[]string{"ES_ar"}, "es"},
+		{[]string{"fr-CA", "es"}, "es"},
+		{[]string{"fr", "de"}, "en"},
+	}
+	for _, tt := range tests {
+		if got := b.Match(tt.prefs...); got != tt.want {
+			t.Errorf("Match(%v) = %q, want %q", tt.prefs, got, tt.want)
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"es", []string{"es"}},
+		{"fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5", []string{"fr-CH", "fr", "en", "de"}},
+		{"en;q=0.5, es", []string{"es", "en"}},
+		{"en;q=0, es;q=bogus, de", []string{"de"}},
+	}
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		locale string
+		n      int
+		want   string
+	}{
+		{"en", 0, "other"},
+		{"en", 1, "one"},
+		{"en-GB", 2, "other"},
+		{"es", 1, "one"},
+		{"es", 1000000, "many"},
+		{"fr", 0, "one"},
+		{"fr", 2, "other"},
+		{"ja", 1, "other"},
+		{"pl", 1, "one"},
+		{"pl", 3, "few"},
+		{"pl", 13, "many"},
+		{"pl", 22, "few"},
+		{"ru", 21, "one"},
+		{"ru", 11, "many"},
+		{"ru", 24, "few"},
+	}
+	for _, tt := range tests {
+		if got := PluralCategory(tt.locale, tt.n); got != tt.want {
+			t.Errorf("PluralCategory(%q, %d) = %q, want %q", tt.locale, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Package ical writes iCalendar (RFC 5545) feeds of all-day events.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the media type of an iCalendar feed.
+const ContentType = "text/calendar; charset=utf-8"
+
+// Calendar is a feed of events.
+type Calendar struct {
+	// ProdID identifies the product that wrote the feed and Name is shown
+	// by clients when subscribing.
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Event is an all-day event, optionally recurring.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+
+	// Date is the event's day; its time and location are ignored.
+	Date time.Time
+
+	// RRule is the recurrence rule without the "RRULE:" prefix, e.g.
+	// "FREQ=YEARLY", or empty for a single occurrence.
+	RRule string
+
+	// Stamp is when the event was last changed.
+	Stamp time.Time
+}
+
+// WriteTo writes the calendar with CRLF line endings and long lines folded
+// at 75 octets.
+func (c Calendar) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", c.ProdID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", e.Stamp.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE", e.Date.Format("20060102"))
+		if e.RRule != "" {
+			line("RRULE", e.RRule)
+		}
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// escape escapes a TEXT property value. CRLF, LF and a lone CR are all
+// line breaks.
+var escape = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+).Replace
+
+// writeFolded writes a content line, folding it onto continuation lines
+// that start with a space so no line exceeds 75 octets. Lines are only
+// folded between characters, never inside a UTF-8 sequence.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // the leading space counts
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// String returns the calendar as written by WriteTo.
+func (c Calendar) String() string {
+	var b strings.Builder
+	_, _ = c.WriteTo(&b)
+	return b.String()
+}
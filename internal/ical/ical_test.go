@@ -0,0 +1,72 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendar_WriteTo(t *testing.T) {
+	stamp := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	cal := Calendar{
+		ProdID: "-//htmxapp//EN",
+		Name:   "Birthdays",
+		Events: []Event{{
+			UID:         "contact-1-birthday@htmxapp",
+			Summary:     "Alice's birthday; party, maybe",
+			Description: "Born 1985\nbring cake",
+			Date:        time.Date(1985, 4, 12, 0, 0, 0, 0, time.UTC),
+			RRule:       "FREQ=YEARLY",
+			Stamp:       stamp,
+		}},
+	}
+	out := cal.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//htmxapp//EN\r\n",
+		"X-WR-CALNAME:Birthdays\r\n",
+		"DTSTAMP:20260102T020405Z\r\n",
+		"DTSTART;VALUE=DATE:19850412\r\n",
+		"RRULE:FREQ=YEARLY\r\n",
+		`SUMMARY:Alice's birthday\; party\, maybe` + "\r\n",
+		`DESCRIPTION:Born 1985\nbring cake` + "\r\n",
+		"END:VEVENT\r\nEND:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Error("found a bare LF")
+	}
+}
+
+func TestEscape_LineBreaks(t *testing.T) {
+	out := Calendar{Events: []Event{{Summary: "a\rb\r\nc\nd", Date: time.Now()}}}.String()
+	if want := `SUMMARY:a\nb\nc\nd` + "\r\n"; !strings.Contains(out, want) {
+		t.Errorf("missing %q in:\n%s", want, out)
+	}
+	if strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\r") {
+		t.Errorf("found a bare CR in:\n%q", out)
+	}
+}
+
+func TestWriteFolded(t *testing.T) {
+	summary := strings.Repeat("ü", 60) // 120 octets
+	out := Calendar{Events: []Event{{Summary: summary, Date: time.Now()}}}.String()
+
+	var unfolded strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if rest, ok := strings.CutPrefix(line, " "); ok {
+			unfolded.WriteString(rest)
+			continue
+		}
+		unfolded.WriteString("\n" + line)
+	}
+	if !strings.Contains(unfolded.String(), "SUMMARY:"+summary+"\n") {
+		t.Errorf("folded summary doesn't unfold to the original:\n%s", out)
+	}
+}
@@ -0,0 +1,763 @@
+// Package markdown renders the Markdown contacts' notes are written in to
+// HTML.
+//
+// It supports the common subset of CommonMark: paragraphs, headings,
+// emphasis, strikethrough, code spans and fenced code blocks, block
+// quotes, nested lists, thematic breaks, inline links and autolinks,
+// including bare http and https URLs.
+//
+// The output is safe to embed in a page as it is. Everything taken from
+// the source is escaped, including any HTML it contains, so the only
+// elements are the ones the renderer writes itself. Links are kept only
+// for http, https, mailto and tel URLs and for relative ones, and images
+// are rendered as links so a note can't make the browser load anything.
+package markdown
+
+import (
+	"html/template"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxDepth bounds how deeply quotes, lists and emphasis nest. Deeper
+// markup is rendered as text.
+const maxDepth = 16
+
+// maxLinkLength bounds the destination and title of an inline link, so
+// that unclosed parentheses don't each make the renderer scan the rest of
+// the note.
+const maxLinkLength = 2048
+
+// headingShift is added to heading levels so that a note's headings sit
+// below the headings of the page showing it: "#" becomes h3.
+const headingShift = 2
+
+// Render converts Markdown to HTML.
+func Render(src string) template.HTML {
+	src = strings.ToValidUTF8(src, "\uFFFD")
+	src = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\t", "    ", "\x00", "\uFFFD").Replace(src)
+
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(src, "\n"), 0, false)
+	return template.HTML(b.String())
+}
+
+// renderBlocks renders lines as a sequence of blocks. Tight lists pass
+// tight so their items' paragraphs aren't wrapped in <p>.
+func renderBlocks(b *strings.Builder, lines []string, depth int, tight bool) {
+	for i := 0; i < len(lines); {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		indent := len(lines[i]) - len(trimmed)
+		nested := depth < maxDepth
+
+		switch {
+		case trimmed == "":
+			i++
+		case indent < 4 && fence(trimmed) != "":
+			i = renderFence(b, lines, i)
+		case indent < 4 && headingLevel(trimmed) > 0:
+			renderHeading(b, trimmed)
+			i++
+		case indent < 4 && isBreak(trimmed):
+			b.WriteString("<hr>\n")
+			i++
+		case indent < 4 && nested && strings.HasPrefix(trimmed, ">"):
+			i = renderQuote(b, lines, i, depth)
+		case indent < 4 && nested && isListItem(lines[i]):
+			i = renderList(b, lines, i, depth)
+		default:
+			i = renderParagraph(b, lines, i, tight)
+		}
+	}
+}
+
+// startsBlock reports whether line starts a block that interrupts a
+// paragraph. Of ordered lists only those starting at 1 do, so a line
+// such as "2024. A good year" stays part of the paragraph.
+func startsBlock(line string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) >= 4 {
+		return false
+	}
+	if m, ok := parseMarker(line); ok && isListItem(line) {
+		return !m.ordered || m.start == 1
+	}
+	return fence(trimmed) != "" || headingLevel(trimmed) > 0 || isBreak(trimmed) ||
+		strings.HasPrefix(trimmed, ">")
+}
+
+func renderParagraph(b *strings.Builder, lines []string, i int, tight bool) int {
+	start := i
+	for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsBlock(lines[i]); i++ {
+	}
+	para := make([]string, 0, i-start)
+	for _, l := range lines[start:i] {
+		para = append(para, strings.TrimLeft(l, " "))
+	}
+	text := strings.TrimRight(strings.Join(para, "\n"), " ")
+
+	if !tight {
+		b.WriteString("<p>")
+	}
+	renderInline(b, text, true, 0)
+	if !tight {
+		b.WriteString("</p>")
+	}
+	b.WriteString("\n")
+	return i
+}
+
+// fence returns the code fence trimmed opens with, such as "```", or ""
+// if it doesn't open one.
+func fence(trimmed string) string {
+	if trimmed == "" || (trimmed[0] != '`' && trimmed[0] != '~') {
+		return ""
+	}
+	n := run(trimmed, 0, trimmed[0])
+	if n < 3 || (trimmed[0] == '`' && strings.Contains(trimmed[n:], "`")) {
+		return ""
+	}
+	return trimmed[:n]
+}
+
+// renderFence renders the fenced code block opening at lines[i]. A fence
+// that is never closed runs to the end of the note.
+func renderFence(b *strings.Builder, lines []string, i int) int {
+	trimmed := strings.TrimLeft(lines[i], " ")
+	indent := len(lines[i]) - len(trimmed)
+	open := fence(trimmed)
+	lang, _, _ := strings.Cut(strings.TrimSpace(trimmed[len(open):]), " ")
+	lang = strings.Map(func(r rune) rune {
+		if r < 0x80 && (isAlnum(byte(r)) || strings.ContainsRune("-_+#.", r)) {
+			return r
+		}
+		return -1
+	}, lang)
+
+	b.WriteString("<pre><code")
+	if lang != "" {
+		b.WriteString(` class="language-` + lang + `"`)
+	}
+	b.WriteString(">")
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		t := strings.TrimLeft(line, " ")
+		if len(line)-len(t) < 4 && strings.HasPrefix(t, open) && strings.Trim(t, open[:1]+" ") == "" {
+			i++
+			break
+		}
+		// Remove as much indentation as the opening fence had.
+		for k := 0; k < indent && strings.HasPrefix(line, " "); k++ {
+			line = line[1:]
+		}
+		b.WriteString(template.HTMLEscapeString(line))
+		b.WriteString("\n")
+	}
+	b.WriteString("</code></pre>\n")
+	return i
+}
+
+// headingLevel returns the level of an ATX heading, or 0.
+func headingLevel(trimmed string) int {
+	n := run(trimmed, 0, '#')
+	if n == 0 || n > 6 || (n < len(trimmed) && trimmed[n] != ' ') {
+		return 0
+	}
+	return n
+}
+
+func renderHeading(b *strings.Builder, trimmed string) {
+	level := headingLevel(trimmed)
+	text := strings.TrimSpace(trimmed[level:])
+	// Drop an optional closing sequence of #s.
+	if t := strings.TrimRight(text, "#"); t == "" || strings.HasSuffix(t, " ") {
+		text = strings.TrimSpace(t)
+	}
+	tag := "h" + strconv.Itoa(min(level+headingShift, 6))
+	b.WriteString("<" + tag + ">")
+	renderInline(b, text, true, 0)
+	b.WriteString("</" + tag + ">\n")
+}
+
+// isBreak reports whether trimmed is a thematic break: three or more of
+// the same "-", "*" or "_", optionally separated by spaces.
+func isBreak(trimmed string) bool {
+	s := strings.ReplaceAll(trimmed, " ", "")
+	return len(s) >= 3 && strings.Contains("-*_", s[:1]) && strings.Count(s, s[:1]) == len(s)
+}
+
+func renderQuote(b *strings.Builder, lines []string, i, depth int) int {
+	var inner []string
+	for ; i < len(lines); i++ {
+		t := strings.TrimLeft(lines[i], " ")
+		if len(lines[i])-len(t) >= 4 || !strings.HasPrefix(t, ">") {
+			break
+		}
+		t = strings.TrimPrefix(t[1:], " ")
+		inner = append(inner, t)
+	}
+	b.WriteString("<blockquote>\n")
+	renderBlocks(b, inner, depth+1, false)
+	b.WriteString("</blockquote>\n")
+	return i
+}
+
+// listMarker describes the marker starting a list item.
+type listMarker struct {
+	ordered bool
+	delim   byte // '-', '*' or '+' for bullets, '.' or ')' after numbers
+	start   int
+	indent  int // spaces before the marker
+	content int // offset of the item's content in the line
+}
+
+// parseMarker parses the list item marker line starts with.
+func parseMarker(line string) (listMarker, bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	m := listMarker{indent: len(line) - len(trimmed)}
+	if m.indent >= 4 || trimmed == "" {
+		return m, false
+	}
+
+	var end int // offset of the end of the marker in trimmed
+	switch c := trimmed[0]; {
+	case c == '-' || c == '*' || c == '+':
+		m.delim, end = c, 1
+	case isDigit(c):
+		n := 0
+		for n < len(trimmed) && n < 9 && isDigit(trimmed[n]) {
+			n++
+		}
+		if n == len(trimmed) || (trimmed[n] != '.' && trimmed[n] != ')') {
+			return m, false
+		}
+		m.ordered, m.delim, end = true, trimmed[n], n+1
+		m.start, _ = strconv.Atoi(trimmed[:n])
+	default:
+		return m, false
+	}
+
+	rest := trimmed[end:]
+	spaces := len(rest) - len(strings.TrimLeft(rest, " "))
+	switch {
+	case rest == "" || strings.TrimSpace(rest) == "":
+		spaces = 1
+	case spaces == 0:
+		return m, false
+	case spaces > 4:
+		// Content indented this far is code in CommonMark; treat the
+		// item as starting after a single space.
+		spaces = 1
+	}
+	m.content = m.indent + end + spaces
+	return m, true
+}
+
+// isListItem reports whether line starts a list item. Thematic breaks
+// such as "* * *" are not items.
+func isListItem(line string) bool {
+	_, ok := parseMarker(line)
+	return ok && !isBreak(strings.TrimLeft(line, " "))
+}
+
+// renderList renders the list starting at lines[i]. Items continue on
+// lines indented to their content; a list is loose, with its items'
+// paragraphs wrapped in <p>, when blank lines separate its blocks.
+func renderList(b *strings.Builder, lines []string, i, depth int) int {
+	first, _ := parseMarker(lines[i])
+	var (
+		items [][]string
+		loose bool
+		cur   listMarker
+	)
+	for i < len(lines) {
+		line := lines[i]
+		if m, ok := parseMarker(line); ok && !isBreak(strings.TrimLeft(line, " ")) &&
+			(len(items) == 0 || m.indent < cur.content) {
+			if m.ordered != first.ordered || m.delim != first.delim {
+				break
+			}
+			cur = m
+			items = append(items, []string{contentAt(line, m.content)})
+			i++
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			// A blank line continues the list only if the item or list
+			// goes on after it.
+			j := i
+			for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+				j++
+			}
+			if j == len(lines) || !continuesList(lines[j], cur, first) {
+				break
+			}
+			loose = true
+			for ; i < j; i++ {
+				items[len(items)-1] = append(items[len(items)-1], "")
+			}
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		last := items[len(items)-1]
+		switch {
+		case indent >= cur.content:
+			items[len(items)-1] = append(last, line[cur.content:])
+		case last[len(last)-1] != "" && !startsBlock(line):
+			// A lazy continuation of the item's paragraph.
+			items[len(items)-1] = append(last, strings.TrimLeft(line, " "))
+		default:
+			return endList(b, items, first, loose, depth, i)
+		}
+		i++
+	}
+	return endList(b, items, first, loose, depth, i)
+}
+
+// continuesList reports whether line, following blank lines, continues
+// the current item or starts the list's next one.
+func continuesList(line string, cur, first listMarker) bool {
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	if indent >= cur.content {
+		return true
+	}
+	m, ok := parseMarker(line)
+	return ok && m.ordered == first.ordered && m.delim == first.delim && !isBreak(strings.TrimLeft(line, " "))
+}
+
+func endList(b *strings.Builder, items [][]string, first listMarker, loose bool, depth, i int) int {
+	tag := "ul"
+	if first.ordered {
+		tag = "ol"
+	}
+	b.WriteString("<" + tag)
+	if first.ordered && first.start != 1 {
+		b.WriteString(` start="` + strconv.Itoa(first.start) + `"`)
+	}
+	b.WriteString(">\n")
+	for _, item := range items {
+		b.WriteString("<li>")
+		var inner strings.Builder
+		renderBlocks(&inner, item, depth+1, !loose)
+		b.WriteString(strings.TrimSuffix(inner.String(), "\n"))
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+func contentAt(line string, offset int) string {
+	if offset >= len(line) {
+		return ""
+	}
+	return line[offset:]
+}
+
+// renderInline renders the inline markup of text. Inside link text links
+// is false, since links can't nest.
+func renderInline(b *strings.Builder, s string, links bool, depth int) {
+	text := 0 // start of the literal text not yet written
+	unclosed := make(map[string]bool)
+	var brackets []int // see matchBrackets, computed on demand
+	flush := func(end int) {
+		b.WriteString(template.HTMLEscapeString(s[text:end]))
+	}
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			flush(i)
+			b.WriteString(template.HTMLEscapeString(s[i+1 : i+2]))
+			i += 2
+			text = i
+			continue
+
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			flush(i)
+			b.WriteString("<br>\n")
+			i += 2
+			text = i
+			continue
+
+		case c == '\n':
+			// Two or more trailing spaces make a hard line break.
+			j := i
+			for j > text && s[j-1] == ' ' {
+				j--
+			}
+			flush(j)
+			if i-j >= 2 {
+				b.WriteString("<br>")
+			}
+			b.WriteString("\n")
+			i++
+			text = i
+			continue
+
+		case c == '`':
+			n := run(s, i, '`')
+			end := codeSpanEnd(s, i+n, n)
+			if end < 0 {
+				i += n
+				continue
+			}
+			flush(i)
+			code := strings.ReplaceAll(s[i+n:end], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			b.WriteString("<code>" + template.HTMLEscapeString(code) + "</code>")
+			i = end + n
+			text = i
+			continue
+
+		case c == '<' && links:
+			if end, href, label, ok := autolink(s, i); ok {
+				flush(i)
+				writeLink(b, href, "", label, depth)
+				i = end
+				text = i
+				continue
+			}
+
+		case (c == '[' || (c == '!' && i+1 < len(s) && s[i+1] == '[')) && links:
+			open := i
+			if c == '!' {
+				open++
+			}
+			if brackets == nil {
+				brackets = matchBrackets(s)
+			}
+			if l, ok := parseLink(s, open, brackets[open]); ok {
+				flush(i)
+				label := l.text
+				if label == "" {
+					label = l.dest
+				}
+				if href, ok := safeURL(l.dest); ok {
+					writeLink(b, href, l.title, label, depth)
+				} else {
+					renderInline(b, label, false, depth+1)
+				}
+				i = l.end
+				text = i
+				continue
+			}
+
+		case (c == '*' || c == '_' || c == '~') && depth < maxDepth:
+			n := run(s, i, c)
+			if delim := s[i : i+n]; !unclosed[delim] {
+				end, ok := emphasis(b, s, i, links, depth, func() { flush(i) })
+				if ok {
+					i = end
+					text = i
+					continue
+				}
+				// Later runs like it can't be closed either.
+				unclosed[delim] = end < 0
+			}
+			i += n
+			continue
+
+		case c == 'h' && links && (i == 0 || strings.IndexByte(" \n(*_~", s[i-1]) >= 0):
+			if end := bareURL(s, i); end > 0 {
+				flush(i)
+				writeLink(b, s[i:end], "", s[i:end], depth)
+				i = end
+				text = i
+				continue
+			}
+		}
+		i++
+	}
+	flush(len(s))
+}
+
+// writeLink writes a link to an already vetted href.
+func writeLink(b *strings.Builder, href, title, label string, depth int) {
+	b.WriteString(`<a href="` + template.HTMLEscapeString(href) + `"`)
+	if title != "" {
+		b.WriteString(` title="` + template.HTMLEscapeString(title) + `"`)
+	}
+	b.WriteString(` rel="nofollow noopener noreferrer">`)
+	renderInline(b, label, false, depth+1)
+	b.WriteString("</a>")
+}
+
+// codeSpanEnd returns the offset of the backtick run of exactly n that
+// closes a code span opened before from, or -1.
+func codeSpanEnd(s string, from, n int) int {
+	for j := from; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		m := run(s, j, '`')
+		if m == n {
+			return j
+		}
+		j += m
+	}
+	return -1
+}
+
+// emphasis renders the emphasis the delimiter run at s[i] opens, calling
+// flush first. It reports false, writing nothing, if the run can't open
+// emphasis, with an end of -1 if that is because nothing closes it.
+func emphasis(b *strings.Builder, s string, i int, links bool, depth int, flush func()) (int, bool) {
+	c := s[i]
+	n := run(s, i, c)
+	if (c == '~' && n != 2) || n > 3 {
+		return 0, false
+	}
+	from := i + n
+	if from == len(s) || isSpace(s[from]) || (c == '_' && i > 0 && isWord(s[i-1])) {
+		return 0, false
+	}
+
+	end := -1
+	for j := from; j < len(s) && end < 0; {
+		switch s[j] {
+		case '\\':
+			j += 2
+		case '`':
+			m := run(s, j, '`')
+			if e := codeSpanEnd(s, j+m, m); e >= 0 {
+				j = e + m
+			} else {
+				j += m
+			}
+		case c:
+			m := run(s, j, c)
+			if m == n && !isSpace(s[j-1]) && (c != '_' || j+m == len(s) || !isWord(s[j+m])) {
+				end = j
+			}
+			j += m
+		default:
+			j++
+		}
+	}
+	if end < 0 {
+		return -1, false
+	}
+
+	open, close := "<em>", "</em>"
+	switch {
+	case c == '~':
+		open, close = "<del>", "</del>"
+	case n == 2:
+		open, close = "<strong>", "</strong>"
+	case n == 3:
+		open, close = "<strong><em>", "</em></strong>"
+	}
+	flush()
+	b.WriteString(open)
+	renderInline(b, s[from:end], links, depth+1)
+	b.WriteString(close)
+	return end + n, true
+}
+
+// link is an inline link parsed from "[text](dest "title")".
+type link struct {
+	text, dest, title string
+	end               int
+}
+
+// matchBrackets returns the offset of the "]" closing each "[" in s, or
+// -1 for brackets left open. Escaped brackets don't count.
+func matchBrackets(s string) []int {
+	match := make([]int, len(s))
+	var open []int
+	for i := 0; i < len(s); i++ {
+		match[i] = -1
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				match[i] = -1
+			}
+		case '[':
+			open = append(open, i)
+		case ']':
+			if n := len(open); n > 0 {
+				match[open[n-1]] = i
+				open = open[:n-1]
+			}
+		}
+	}
+	return match
+}
+
+// parseLink parses the inline link whose text runs from the bracket at
+// s[i] to the one at s[j].
+func parseLink(s string, i, j int) (link, bool) {
+	var l link
+	if j < 0 || j+1 >= len(s) || s[j+1] != '(' {
+		return l, false
+	}
+	l.text = s[i+1 : j]
+	s = s[:min(len(s), j+maxLinkLength)]
+
+	k := skipSpace(s, j+2)
+	if k < len(s) && s[k] == '<' {
+		e := strings.IndexAny(s[k+1:], ">\n")
+		if e < 0 || s[k+1+e] != '>' {
+			return l, false
+		}
+		l.dest = s[k+1 : k+1+e]
+		k += e + 2
+	} else {
+		start, parens := k, 0
+	dest:
+		for ; k < len(s); k++ {
+			switch c := s[k]; {
+			case c == '\\':
+				k++
+			case c == '(':
+				parens++
+			case c == ')':
+				if parens == 0 {
+					break dest
+				}
+				parens--
+			case c <= ' ':
+				break dest
+			}
+		}
+		l.dest = s[start:min(k, len(s))]
+	}
+
+	if t := skipSpace(s, k); t > k && t < len(s) && (s[t] == '"' || s[t] == '\'') {
+		e := strings.IndexByte(s[t+1:], s[t])
+		if e < 0 {
+			return l, false
+		}
+		l.title = unescape(s[t+1 : t+1+e])
+		k = t + e + 2
+	}
+	k = skipSpace(s, k)
+	if k >= len(s) || s[k] != ')' {
+		return l, false
+	}
+	l.dest = unescape(l.dest)
+	l.end = k + 1
+	return l, true
+}
+
+// autolink parses "<https://…>" or "<name@example.com>" at s[i].
+func autolink(s string, i int) (end int, href, label string, ok bool) {
+	e := strings.IndexAny(s[i+1:], "<> \n")
+	if e <= 0 || s[i+1+e] != '>' {
+		return 0, "", "", false
+	}
+	label = s[i+1 : i+1+e]
+	href = label
+	if !strings.Contains(label, ":") {
+		if !strings.Contains(label, "@") {
+			return 0, "", "", false
+		}
+		href = "mailto:" + label
+	}
+	if !hasScheme(href) {
+		return 0, "", "", false
+	}
+	if href, ok = safeURL(href); !ok {
+		return 0, "", "", false
+	}
+	return i + e + 2, href, label, true
+}
+
+// bareURL returns the end of the http or https URL at s[i], or 0.
+// Trailing punctuation and unbalanced closing parentheses are left out,
+// since they usually belong to the surrounding sentence.
+func bareURL(s string, i int) int {
+	rest := s[i:]
+	if !strings.HasPrefix(rest, "http://") && !strings.HasPrefix(rest, "https://") {
+		return 0
+	}
+	end := strings.IndexFunc(rest, func(r rune) bool { return r <= ' ' || r == '<' })
+	if end < 0 {
+		end = len(rest)
+	}
+	u := strings.TrimRight(rest[:end], ".,:;!?'\"*_~")
+	for strings.HasSuffix(u, ")") && strings.Count(u, ")") > strings.Count(u, "(") {
+		u = strings.TrimRight(u[:len(u)-1], ".,:;!?'\"*_~")
+	}
+	if _, after, _ := strings.Cut(u, "://"); after == "" {
+		return 0
+	}
+	return i + len(u)
+}
+
+// safeSchemes are the URL schemes links may use.
+var safeSchemes = []string{"http", "https", "mailto", "tel"}
+
+// safeURL vets a link destination: URLs with a scheme must use one of
+// safeSchemes, which rules out javascript: and data: URLs. Destinations
+// with whitespace or control characters are rejected, since browsers
+// ignore some of them inside schemes.
+func safeURL(raw string) (string, bool) {
+	u := strings.TrimSpace(raw)
+	if u == "" || strings.ContainsFunc(u, func(r rune) bool { return r <= ' ' || r == 0x7f }) {
+		return "", false
+	}
+	if hasScheme(u) {
+		scheme, _, _ := strings.Cut(u, ":")
+		if !slices.Contains(safeSchemes, strings.ToLower(scheme)) {
+			return "", false
+		}
+	}
+	return u, true
+}
+
+// hasScheme reports whether u starts with a scheme, i.e. has a colon
+// before any slash, question mark or hash.
+func hasScheme(u string) bool {
+	i := strings.IndexAny(u, ":/?#")
+	return i >= 0 && u[i] == ':'
+}
+
+// unescape removes backslash escapes from a link destination or title.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// run returns the length of the run of c starting at s[i].
+func run(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\n' }
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+func isAlnum(c byte) bool { return isDigit(c) || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') }
+
+// isWord reports whether c is part of a word: an ASCII letter or digit,
+// or a byte of a non-ASCII character.
+func isWord(c byte) bool { return isAlnum(c) || c >= 0x80 }
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
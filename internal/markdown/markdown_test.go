@@ -0,0 +1,129 @@
+package markdown
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"paragraphs", "one\ntwo\n\nthree", "<p>one\ntwo</p>\n<p>three</p>\n"},
+		{"hard break", "one  \ntwo\\\nthree", "<p>one<br>\ntwo<br>\nthree</p>\n"},
+		{"headings", "# Title\n### Sub ###\n#tag", "<h3>Title</h3>\n<h5>Sub</h5>\n<p>#tag</p>\n"},
+		{"deep heading", "###### Six", "<h6>Six</h6>\n"},
+		{"emphasis", "*a* **b** ***c*** _d_ ~~e~~", "<p><em>a</em> <strong>b</strong> <strong><em>c</em></strong> <em>d</em> <del>e</del></p>\n"},
+		{"nested emphasis", "*a **b** c*", "<p><em>a <strong>b</strong> c</em></p>\n"},
+		{"unclosed emphasis", "2 * 3 and **open", "<p>2 * 3 and **open</p>\n"},
+		{"intraword underscore", "snake_case_name", "<p>snake_case_name</p>\n"},
+		{"code span", "run `go test ./...` and ``a ` b``", "<p>run <code>go test ./...</code> and <code>a ` b</code></p>\n"},
+		{"code span is literal", "`*not* <b>`", "<p><code>*not* &lt;b&gt;</code></p>\n"},
+		{"fence", "```go\nfunc main() {}\n  <b>\n```\nafter", "<pre><code class=\"language-go\">func main() {}\n  &lt;b&gt;\n</code></pre>\n<p>after</p>\n"},
+		{"unclosed fence", "~~~\ncode", "<pre><code>code\n</code></pre>\n"},
+		{"quote", "> quoted\n> > nested\n\nout", "<blockquote>\n<p>quoted</p>\n<blockquote>\n<p>nested</p>\n</blockquote>\n</blockquote>\n<p>out</p>\n"},
+		{"thematic break", "a\n\n* * *\n---", "<p>a</p>\n<hr>\n<hr>\n"},
+		{"tight list", "- one\n- two\ncontinued\n- three", "<ul>\n<li>one</li>\n<li>two\ncontinued</li>\n<li>three</li>\n</ul>\n"},
+		{"loose list", "1. one\n\n2. two", "<ol>\n<li><p>one</p></li>\n<li><p>two</p></li>\n</ol>\n"},
+		{"ordered start", "3) three\n4) four", "<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n"},
+		{"nested list", "- a\n  - b\n  - c\n- d", "<ul>\n<li>a\n<ul>\n<li>b</li>\n<li>c</li>\n</ul></li>\n<li>d</li>\n</ul>\n"},
+		{"list interrupts paragraph", "Todo:\n- call", "<p>Todo:</p>\n<ul>\n<li>call</li>\n</ul>\n"},
+		{"year is not a list", "We met in\n2019. It rained.", "<p>We met in\n2019. It rained.</p>\n"},
+		{"list kinds", "- a\n+ b", "<ul>\n<li>a</li>\n</ul>\n<ul>\n<li>b</li>\n</ul>\n"},
+		{"link", `[site](https://example.com/a?b=1&c=2 "The site")`, `<p><a href="https://example.com/a?b=1&amp;c=2" title="The site" rel="nofollow noopener noreferrer">site</a></p>` + "\n"},
+		{"relative link", "[Bob](/contacts/2)", `<p><a href="/contacts/2" rel="nofollow noopener noreferrer">Bob</a></p>` + "\n"},
+		{"link text markup", "[**bold** <https://x.test>](https://example.com)", `<p><a href="https://example.com" rel="nofollow noopener noreferrer"><strong>bold</strong> &lt;https://x.test&gt;</a></p>` + "\n"},
+		{"autolinks", "<https://example.com> <ann@example.com>", `<p><a href="https://example.com" rel="nofollow noopener noreferrer">https://example.com</a> <a href="mailto:ann@example.com" rel="nofollow noopener noreferrer">ann@example.com</a></p>` + "\n"},
+		{"bare url", "See https://example.com/x_(y). Or (https://example.com).", `<p>See <a href="https://example.com/x_(y)" rel="nofollow noopener noreferrer">https://example.com/x_(y)</a>. Or (<a href="https://example.com" rel="nofollow noopener noreferrer">https://example.com</a>).</p>` + "\n"},
+		{"image is a link", "![photo](https://example.com/p.png)", `<p><a href="https://example.com/p.png" rel="nofollow noopener noreferrer">photo</a></p>` + "\n"},
+		{"escapes", `\*not emphasis\* 1 \< 2`, "<p>*not emphasis* 1 &lt; 2</p>\n"},
+		{"entities", "AT&T's \"plan\"", "<p>AT&amp;T&#39;s &#34;plan&#34;</p>\n"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(Render(tt.src)); got != tt.want {
+				t.Errorf("Render(%q)\n got %q\nwant %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_Unsafe(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{`<img src=x onerror="alert(1)">`, "<p>&lt;img src=x onerror=&#34;alert(1)&#34;&gt;</p>\n"},
+		{"[click](javascript:alert(1))", "<p>click</p>\n"},
+		{"[click](JaVaScRiPt:alert(1))", "<p>click</p>\n"},
+		{"[click](data:text/html;base64,PHNjcmlwdD4=)", "<p>click</p>\n"},
+		{"[click](<java\tscript:alert(1)>)", "<p>click</p>\n"},
+		{"<javascript:alert(1)>", "<p>&lt;javascript:alert(1)&gt;</p>\n"},
+		{`[x](https://example.com/"onmouseover="alert(1))`, `<p><a href="https://example.com/&#34;onmouseover=&#34;alert(1)" rel="nofollow noopener noreferrer">x</a></p>` + "\n"},
+		{"```\"><script>\nx\n```", "<pre><code class=\"language-script\">x\n</code></pre>\n"},
+	}
+	for _, tt := range tests {
+		if got := string(Render(tt.src)); got != tt.want {
+			t.Errorf("Render(%q)\n got %q\nwant %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+// allowed maps the elements the renderer may write to their attributes.
+var allowed = map[string][]string{
+	"p": nil, "br": nil, "hr": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+	"em": nil, "strong": nil, "del": nil, "code": {"class"}, "pre": nil,
+	"blockquote": nil, "ul": nil, "ol": {"start"}, "li": nil,
+	"a": {"href", "title", "rel"},
+}
+
+// checkSafe fails unless out contains only allowed elements and
+// attributes, and links only to safe URLs.
+func checkSafe(t *testing.T, src, out string) {
+	t.Helper()
+	z := html.NewTokenizer(strings.NewReader(out))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				t.Fatalf("Render(%q): %v", src, z.Err())
+			}
+			return
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			attrs, ok := allowed[tok.Data]
+			if !ok {
+				t.Fatalf("Render(%q) wrote <%s>: %q", src, tok.Data, out)
+			}
+			for _, a := range tok.Attr {
+				if !slices.Contains(attrs, a.Key) {
+					t.Fatalf("Render(%q) wrote %s on <%s>: %q", src, a.Key, tok.Data, out)
+				}
+				if a.Key == "href" {
+					if _, ok := safeURL(a.Val); !ok {
+						t.Fatalf("Render(%q) linked to %q", src, a.Val)
+					}
+				}
+			}
+		}
+	}
+}
+
+func FuzzRender(f *testing.F) {
+	for _, s := range []string{
+		"# h\n\n*a* **b** `c` [d](https://e.test) <f@g.test> https://h.test",
+		"- a\n  - b\n\n1. c\n> d\n```\ne\n```",
+		"<script>x</script>[a](javascript:x)![b](data:x)",
+		"***a**b*c ~~d~ _e__ [f](<g h>) [i](j 'k')",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		checkSafe(t, src, string(Render(src)))
+	})
+}
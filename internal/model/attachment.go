@@ -0,0 +1,63 @@
+package model
+
+import (
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CodeQuotaExceeded is reported for uploads that would take a contact's
+// attachments over their storage quota.
+const CodeQuotaExceeded = "quota_exceeded"
+
+// maxFileName bounds attachment names, in bytes.
+const maxFileName = 200
+
+// Attachment is a file attached to a contact, such as a signed NDA or a
+// scanned business card. Its content is stored as a blob named by
+// Checksum, so identical files share storage.
+type Attachment struct {
+	ID          string
+	ContactID   string
+	Name        string
+	ContentType string
+	Size        int64
+
+	// Checksum is the hex SHA-256 of the content.
+	Checksum  string
+	CreatedAt time.Time
+}
+
+// CleanFileName reduces an uploaded file's name to its base name without
+// control characters, shortened to a sensible length with its extension
+// kept, or "file" if nothing usable is left.
+func CleanFileName(name string) string {
+	name = strings.ReplaceAll(name, `\`, "/")
+	name = path.Base(name)
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" || name == ".." {
+		return "file"
+	}
+
+	if len(name) > maxFileName {
+		ext := path.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		base := strings.TrimSuffix(name, ext)
+		for len(base)+len(ext) > maxFileName {
+			_, size := utf8.DecodeLastRuneInString(base)
+			base = base[:len(base)-size]
+		}
+		name = base + ext
+	}
+	return name
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCleanFileName(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"nda.pdf", "nda.pdf"},
+		{"C:\\Users\\bob\\résumé.docx", "résumé.docx"},
+		{"../../etc/passwd", "passwd"},
+		{"card\x00\r\n.jpg", "card.jpg"},
+		{"  spaced.txt ", "spaced.txt"},
+		{"", "file"},
+		{"..", "file"},
+		{"/", "file"},
+	}
+	for _, tt := range tests {
+		if got := CleanFileName(tt.in); got != tt.want {
+			t.Errorf("CleanFileName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	long := CleanFileName(strings.Repeat("é", 150) + ".pdf")
+	if len(long) > maxFileName || !strings.HasSuffix(long, "é.pdf") || !utf8.ValidString(long) {
+		t.Errorf("long name shortened to %q (%d bytes)", long, len(long))
+	}
+}
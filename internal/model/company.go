@@ -0,0 +1,57 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Company is an organization contacts can belong to.
+type Company struct {
+	ID      string
+	Name    string
+	Domain  string
+	Website string
+
+	// Contacts is the number of contacts linked to the company, filled in
+	// by the store when companies are listed or fetched.
+	Contacts int
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Company validation codes.
+const (
+	CodeInvalidDomain  = "invalid_domain"
+	CodeUnknownCompany = "unknown_company"
+)
+
+// Validate checks required fields and returns a map of field name to
+// validation error code.
+func (c Company) Validate() map[string]string {
+	errs := make(map[string]string)
+	if strings.TrimSpace(c.Name) == "" {
+		errs["Name"] = CodeRequired
+	}
+	if c.Domain != "" && CompanyDomain(c.Domain) == "" {
+		errs["Domain"] = CodeInvalidDomain
+	}
+	if c.Website != "" && !isWebURL(c.Website) {
+		errs["Website"] = CodeInvalidURL
+	}
+	return errs
+}
+
+// CompanyDomain returns the lowercase punycode form of a company domain,
+// accepting a bare domain or an "@domain" suffix, or "" if it is invalid.
+func CompanyDomain(s string) string {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "@")
+	if s == "" || strings.ContainsAny(s, "@/ ") || !strings.Contains(s, ".") {
+		return ""
+	}
+	d, err := asciiDomain(s)
+	if err != nil {
+		return ""
+	}
+	return d
+}
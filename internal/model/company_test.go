@@ -0,0 +1,47 @@
+package model
+
+import "testing"
+
+func TestCompany_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		company Company
+		want    map[string]string
+	}{
+		{"valid", Company{Name: "Acme", Domain: "acme.com", Website: "https://acme.com"}, map[string]string{}},
+		{"name only", Company{Name: "Acme"}, map[string]string{}},
+		{"missing name", Company{Name: " "}, map[string]string{"Name": CodeRequired}},
+		{"invalid domain", Company{Name: "Acme", Domain: "acme"}, map[string]string{"Domain": CodeInvalidDomain}},
+		{"email as domain", Company{Name: "Acme", Domain: "jane@acme.com"}, map[string]string{"Domain": CodeInvalidDomain}},
+		{"invalid website", Company{Name: "Acme", Website: "acme.com"}, map[string]string{"Website": CodeInvalidURL}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.company.Validate()
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %v", errs, tt.want)
+			}
+			for k, v := range tt.want {
+				if errs[k] != v {
+					t.Errorf("Validate()[%s] = %q, want %q", k, errs[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestCompanyDomain(t *testing.T) {
+	tests := map[string]string{
+		"Acme.COM":      "acme.com",
+		"@acme.com":     "acme.com",
+		"bücher.de":     "xn--bcher-kva.de",
+		"localhost":     "",
+		"acme.com/path": "",
+		"":              "",
+	}
+	for in, want := range tests {
+		if got := CompanyDomain(in); got != want {
+			t.Errorf("CompanyDomain(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,41 +1,98 @@
 package model
 
 import (
-	"fmt"
-	"net/mail"
 	"strings"
 	"time"
 )
 
-// Contact represents a person in the contact list.
+// Contact represents a person in the contact list. FirstName and LastName
+// hold the given and family names; the remaining name parts are optional.
 type Contact struct {
-	ID        string
-	FirstName string
-	LastName  string
-	Email     string
-	Phone     string
+	ID         string
+	Prefix     string
+	FirstName  string
+	MiddleName string
+	LastName   string
+	Suffix     string
+	Nickname   string
+
+	// Phonetic readings of the given and family names, e.g. kana for a
+	// Japanese name. When set they are used for sorting.
+	PhoneticFirstName string
+	PhoneticLastName  string
+
+	Email string
+	Phone string
+
+	// CompanyID links the contact to a Company, where Title is their role.
+	// Company is the company's name, filled in by the store on reads.
+	CompanyID string
+	Title     string
+	Company   string
+
+	// Custom holds values of administrator-defined fields by FieldDef.Key,
+	// in the normalized form FieldDef.Normalize returns.
+	Custom map[string]string
+
+	// Birthday and Anniversary are dates in PartialDate.String form, so
+	// the year is optional. Dates holds any other dates worth
+	// remembering.
+	Birthday    string
+	Anniversary string
+	Dates       []ContactDate
+
+	// PhotoID names the contact's uploaded photo, whose renditions are
+	// stored as blobs. It is empty if the contact has no photo, and
+	// changes whenever the photo does.
+	PhotoID string
+
+	// LastContacted is the time of the contact's latest interaction,
+	// filled in by the store on reads. It is zero if there is none.
+	LastContacted time.Time
+
+	// NoteMatch is an excerpt of the contact's note a list search found
+	// them by, filled in by the store when no other field matched.
+	NoteMatch string
+
+	// Starred marks a contact the user listing contacts starred, filled
+	// in by the store's List.
+	Starred bool
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
-// FullName returns the contact's full name.
+// FullName returns the contact's full name in Western order.
 func (c Contact) FullName() string {
-	return strings.TrimSpace(c.FirstName + " " + c.LastName)
+	return c.DisplayName(NameOrderWestern)
 }
 
-// Validate checks required fields and returns any validation errors.
+// Validation error codes. Validate reports one code per invalid field;
+// templates translate them using the "validation.<Field>.<code>" message.
+const (
+	CodeRequired     = "required"
+	CodeInvalidEmail = "invalid_email"
+	CodeDuplicate    = "duplicate"
+	CodeDomain       = "domain_not_allowed"
+
+	// Codes for uploaded photos.
+	CodeTooLarge         = "too_large"
+	CodeUnsupportedImage = "unsupported_image"
+)
+
+// Validate checks required fields and returns a map of field name to
+// validation error code. A contact needs some name to display (given,
+// family or nickname) but not every part.
 func (c Contact) Validate() map[string]string {
 	errs := make(map[string]string)
-	if strings.TrimSpace(c.FirstName) == "" {
-		errs["FirstName"] = "First name is required"
-	}
-	if strings.TrimSpace(c.LastName) == "" {
-		errs["LastName"] = "Last name is required"
+	if !c.HasName() {
+		errs["FirstName"] = CodeRequired
 	}
 	if strings.TrimSpace(c.Email) == "" {
-		errs["Email"] = "Email is required"
-	} else if _, err := mail.ParseAddress(c.Email); err != nil {
-		errs["Email"] = fmt.Sprintf("Invalid email address: %s", c.Email)
+		errs["Email"] = CodeRequired
+	} else if _, err := ParseEmail(c.Email); err != nil {
+		errs["Email"] = CodeInvalidEmail
 	}
+	c.validateDates(errs)
 	return errs
 }
@@ -14,6 +14,8 @@ func TestContact_FullName(t *testing.T) {
 		{"first only", Contact{FirstName: "Jane"}, "Jane"},
 		{"last only", Contact{LastName: "Doe"}, "Doe"},
 		{"empty", Contact{}, ""},
+		{"all parts", Contact{Prefix: "Dr.", FirstName: "Jane", MiddleName: "Q.", LastName: "Doe", Suffix: "Jr."}, "Dr. Jane Q. Doe Jr."},
+		{"nickname only", Contact{Nickname: "JJ"}, "JJ"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -37,14 +39,24 @@ func TestContact_Validate(t *testing.T) {
 			wantClean: true,
 		},
 		{
-			name:     "missing first name",
-			contact:  Contact{LastName: "Doe", Email: "jane@example.com"},
-			wantErrs: []string{"FirstName"},
+			name:      "family name only",
+			contact:   Contact{LastName: "Doe", Email: "jane@example.com"},
+			wantClean: true,
 		},
 		{
-			name:     "missing last name",
-			contact:  Contact{FirstName: "Jane", Email: "jane@example.com"},
-			wantErrs: []string{"LastName"},
+			name:      "given name only",
+			contact:   Contact{FirstName: "Jane", Email: "jane@example.com"},
+			wantClean: true,
+		},
+		{
+			name:      "nickname only",
+			contact:   Contact{Nickname: "JJ", Email: "jane@example.com"},
+			wantClean: true,
+		},
+		{
+			name:     "no displayable name",
+			contact:  Contact{Prefix: "Dr.", MiddleName: "Q.", Suffix: "Jr.", Email: "jane@example.com"},
+			wantErrs: []string{"FirstName"},
 		},
 		{
 			name:     "missing email",
@@ -59,7 +71,7 @@ func TestContact_Validate(t *testing.T) {
 		{
 			name:     "all missing",
 			contact:  Contact{},
-			wantErrs: []string{"FirstName", "LastName", "Email"},
+			wantErrs: []string{"FirstName", "Email"},
 		},
 	}
 	for _, tt := range tests {
@@ -79,3 +91,16 @@ func TestContact_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestContact_Validate_Codes(t *testing.T) {
+	errs := Contact{Email: "not-an-email"}.Validate()
+	want := map[string]string{
+		"FirstName": CodeRequired,
+		"Email":     CodeInvalidEmail,
+	}
+	for field, code := range want {
+		if errs[field] != code {
+			t.Errorf("errs[%q] = %q, want %q", field, errs[field], code)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidDate is returned for dates ParsePartialDate can't read.
+var ErrInvalidDate = errors.New("invalid date")
+
+// PartialDate is a calendar date whose year may be unknown, such as a
+// birthday given without the year of birth.
+type PartialDate struct {
+	Year  int // 0 if unknown
+	Month time.Month
+	Day   int
+}
+
+// ParsePartialDate reads "2006-01-02", or "--01-02" or "01-02" for a date
+// without a year, the ISO 8601 and vCard forms.
+func ParsePartialDate(s string) (PartialDate, error) {
+	s = strings.TrimSpace(s)
+	// Dates without a year are read in leap year 2000 so February 29
+	// parses.
+	year := true
+	switch {
+	case strings.HasPrefix(s, "--"):
+		s, year = "2000"+s[1:], false
+	case len(s) == len("01-02"):
+		s, year = "2000-"+s, false
+	}
+	t, err := time.Parse(DateLayout, s)
+	if err != nil || year && t.Year() == 0 {
+		return PartialDate{}, ErrInvalidDate
+	}
+	d := PartialDate{Month: t.Month(), Day: t.Day()}
+	if year {
+		d.Year = t.Year()
+	}
+	return d, nil
+}
+
+// HasYear reports whether the year is known.
+func (d PartialDate) HasYear() bool {
+	return d.Year != 0
+}
+
+// String returns the date in the form ParsePartialDate reads, with "--" in
+// place of an unknown year.
+func (d PartialDate) String() string {
+	if !d.HasYear() {
+		return fmt.Sprintf("--%02d-%02d", d.Month, d.Day)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// In returns the date's anniversary in year, at midnight in loc. February
+// 29 falls on February 28 in common years.
+func (d PartialDate) In(year int, loc *time.Location) time.Time {
+	day := d.Day
+	if d.Month == time.February && day == 29 && !isLeap(year) {
+		day = 28
+	}
+	return time.Date(year, d.Month, day, 0, 0, 0, 0, loc)
+}
+
+// Next returns the first anniversary of the date on or after the day of
+// from, in from's location.
+func (d PartialDate) Next(from time.Time) time.Time {
+	today := StartOfDay(from)
+	next := d.In(today.Year(), from.Location())
+	if next.Before(today) {
+		next = d.In(today.Year()+1, from.Location())
+	}
+	return next
+}
+
+// YearsAt returns how many years have passed since the date by its
+// anniversary in year, or 0 if the year is unknown.
+func (d PartialDate) YearsAt(year int) int {
+	if !d.HasYear() || year < d.Year {
+		return 0
+	}
+	return year - d.Year
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// Occasion kinds.
+const (
+	OccasionBirthday    = "birthday"
+	OccasionAnniversary = "anniversary"
+	OccasionCustom      = "custom"
+)
+
+// ContactDate is a labelled date a contact wants remembered, such as the
+// day they joined. Date is stored in PartialDate.String form.
+type ContactDate struct {
+	Label string
+	Date  string
+}
+
+// Occasion is one of a contact's recurring dates.
+type Occasion struct {
+	Kind  string
+	Label string // for custom dates
+	Date  PartialDate
+}
+
+// Occasions returns the contact's birthday, anniversary and custom dates,
+// skipping any that aren't valid and custom dates without a label.
+func (c Contact) Occasions() []Occasion {
+	var out []Occasion
+	add := func(kind, label, s string) {
+		if d, err := ParsePartialDate(s); err == nil {
+			out = append(out, Occasion{Kind: kind, Label: label, Date: d})
+		}
+	}
+	add(OccasionBirthday, "", c.Birthday)
+	add(OccasionAnniversary, "", c.Anniversary)
+	for _, d := range c.Dates {
+		if d.Label != "" {
+			add(OccasionCustom, d.Label, d.Date)
+		}
+	}
+	return out
+}
+
+// validateDates reports invalid dates, keyed "Birthday", "Anniversary" and
+// "Dates.<index>". Custom dates need a label.
+func (c Contact) validateDates(errs map[string]string) {
+	for field, s := range map[string]string{"Birthday": c.Birthday, "Anniversary": c.Anniversary} {
+		if _, err := ParsePartialDate(s); s != "" && err != nil {
+			errs[field] = CodeInvalidDate
+		}
+	}
+	for i, d := range c.Dates {
+		key := fmt.Sprintf("Dates.%d", i)
+		switch {
+		case strings.TrimSpace(d.Label) == "":
+			errs[key] = CodeRequired
+		case d.Date == "":
+			errs[key] = CodeRequired
+		default:
+			if _, err := ParsePartialDate(d.Date); err != nil {
+				errs[key] = CodeInvalidDate
+			}
+		}
+	}
+}
+
+// NormalizeDates rewrites valid dates in the form PartialDate.String
+// returns, leaving invalid ones as entered.
+func (c *Contact) NormalizeDates() {
+	norm := func(s string) string {
+		if d, err := ParsePartialDate(s); err == nil {
+			return d.String()
+		}
+		return s
+	}
+	c.Birthday = norm(c.Birthday)
+	c.Anniversary = norm(c.Anniversary)
+	for i := range c.Dates {
+		c.Dates[i].Label = strings.TrimSpace(c.Dates[i].Label)
+		c.Dates[i].Date = norm(c.Dates[i].Date)
+	}
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePartialDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want PartialDate
+		str  string
+	}{
+		{"1985-04-12", PartialDate{1985, time.April, 12}, "1985-04-12"},
+		{" --04-12 ", PartialDate{0, time.April, 12}, "--04-12"},
+		{"04-12", PartialDate{0, time.April, 12}, "--04-12"},
+		{"--02-29", PartialDate{0, time.February, 29}, "--02-29"},
+		{"2000-02-29", PartialDate{2000, time.February, 29}, "2000-02-29"},
+	}
+	for _, tt := range tests {
+		got, err := ParsePartialDate(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParsePartialDate(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+			continue
+		}
+		if got.String() != tt.str {
+			t.Errorf("String() = %q, want %q", got.String(), tt.str)
+		}
+	}
+
+	for _, in := range []string{"", "1985-13-01", "--02-30", "2001-02-29", "04/12", "--4-12", "--04-12x", "0000-04-12"} {
+		if _, err := ParsePartialDate(in); err == nil {
+			t.Errorf("ParsePartialDate(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestPartialDate_Next(t *testing.T) {
+	loc := time.FixedZone("UTC+9", 9*3600)
+	from := time.Date(2026, 4, 12, 23, 0, 0, 0, loc)
+
+	tests := []struct {
+		d    PartialDate
+		want time.Time
+	}{
+		{PartialDate{1985, time.April, 12}, time.Date(2026, 4, 12, 0, 0, 0, 0, loc)},
+		{PartialDate{0, time.April, 11}, time.Date(2027, 4, 11, 0, 0, 0, 0, loc)},
+		{PartialDate{0, time.December, 31}, time.Date(2026, 12, 31, 0, 0, 0, 0, loc)},
+		// February 29 falls on the 28th in common years.
+		{PartialDate{0, time.February, 29}, time.Date(2027, 2, 28, 0, 0, 0, 0, loc)},
+		{PartialDate{1996, time.February, 29}, time.Date(2027, 2, 28, 0, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		if got := tt.d.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%v.Next = %v, want %v", tt.d, got, tt.want)
+		}
+	}
+
+	if got := (PartialDate{Year: 1985, Month: time.April, Day: 12}).YearsAt(2026); got != 41 {
+		t.Errorf("YearsAt = %d, want 41", got)
+	}
+	if got := (PartialDate{Month: time.April, Day: 12}).YearsAt(2026); got != 0 {
+		t.Errorf("YearsAt without a year = %d, want 0", got)
+	}
+}
+
+func TestContact_Dates(t *testing.T) {
+	c := Contact{
+		FirstName:   "Alice",
+		Email:       "alice@example.com",
+		Birthday:    "04-12",
+		Anniversary: "2010-02-30",
+		Dates: []ContactDate{
+			{Label: " Joined ", Date: "2019-09-01"},
+			{Label: "", Date: "2020-01-01"},
+			{Label: "Moved", Date: "soon"},
+		},
+	}
+	c.NormalizeDates()
+	if c.Birthday != "--04-12" || c.Dates[0].Label != "Joined" {
+		t.Errorf("NormalizeDates left %q, %q", c.Birthday, c.Dates[0].Label)
+	}
+	if c.Anniversary != "2010-02-30" {
+		t.Errorf("invalid date rewritten to %q", c.Anniversary)
+	}
+
+	errs := c.Validate()
+	want := map[string]string{"Anniversary": CodeInvalidDate, "Dates.1": CodeRequired, "Dates.2": CodeInvalidDate}
+	for k, v := range want {
+		if errs[k] != v {
+			t.Errorf("errs[%q] = %q, want %q", k, errs[k], v)
+		}
+	}
+	if _, ok := errs["Birthday"]; ok {
+		t.Error("unexpected Birthday error")
+	}
+
+	occ := c.Occasions()
+	if len(occ) != 2 || occ[0].Kind != OccasionBirthday || occ[1].Label != "Joined" {
+		t.Errorf("Occasions() = %+v", occ)
+	}
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Draft is a contact form autosaved before it was submitted. Owner is the
+// user ID of whoever filled it in and ContactID the contact being edited,
+// or "" for a new contact; Contact holds the form's values, without the
+// photo, which isn't kept in drafts.
+type Draft struct {
+	Owner     string
+	ContactID string
+	Contact   Contact
+	SavedAt   time.Time
+}
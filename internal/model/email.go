@@ -0,0 +1,172 @@
+package model
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// ErrInvalidEmail is returned by ParseEmail for input that is not a single
+// email address.
+var ErrInvalidEmail = errors.New("invalid email address")
+
+// ParseEmail extracts the bare address from s, which may also be written
+// with a display name ("Jane Doe <jane@example.com>"). The local part and
+// domain keep the user's spelling; internationalized domains may be given
+// in Unicode or punycode form.
+func ParseEmail(s string) (string, error) {
+	a, err := mail.ParseAddress(strings.TrimSpace(s))
+	if err != nil {
+		return "", ErrInvalidEmail
+	}
+	local, domain, ok := splitEmail(a.Address)
+	if !ok {
+		return "", ErrInvalidEmail
+	}
+	if _, err := asciiDomain(domain); err != nil {
+		return "", ErrInvalidEmail
+	}
+	return local + "@" + domain, nil
+}
+
+// CanonicalEmail returns the key two addresses are compared by when
+// checking for duplicates. The domain is lowercased and converted to
+// punycode and the local part is lowercased. For providers that deliver
+// "+tag" sub-addresses to the same mailbox the tag is dropped, and for
+// those that ignore dots in the local part, such as Gmail, the dots are
+// too; elsewhere both may name separate mailboxes. Input that does not
+// parse is only trimmed and lowercased.
+func CanonicalEmail(s string) string {
+	addr, err := ParseEmail(s)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(s))
+	}
+	local, domain, _ := splitEmail(addr)
+	domain, _ = asciiDomain(domain)
+	local = strings.ToLower(local)
+	if alias, ok := providerAliases[domain]; ok {
+		domain = alias
+	}
+	if i := strings.IndexByte(local, '+'); i > 0 && subAddressed[domain] {
+		local = local[:i]
+	}
+	if dotless[domain] {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
+
+// EmailDomain returns the lowercased punycode domain of s, or "" if s is
+// not a valid address.
+func EmailDomain(s string) string {
+	addr, err := ParseEmail(s)
+	if err != nil {
+		return ""
+	}
+	_, domain, _ := splitEmail(addr)
+	domain, _ = asciiDomain(domain)
+	return domain
+}
+
+// providerAliases maps domains that deliver to the same mailboxes as
+// another domain.
+var providerAliases = map[string]string{
+	"googlemail.com": "gmail.com",
+}
+
+// subAddressed lists domains whose mail servers deliver "user+tag" to
+// user's mailbox.
+var subAddressed = map[string]bool{
+	"gmail.com":      true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"icloud.com":     true,
+	"me.com":         true,
+	"fastmail.com":   true,
+	"proton.me":      true,
+	"protonmail.com": true,
+}
+
+// dotless lists domains whose mail servers ignore dots in the local part.
+var dotless = map[string]bool{
+	"gmail.com": true,
+}
+
+func splitEmail(addr string) (local, domain string, ok bool) {
+	i := strings.LastIndexByte(addr, '@')
+	if i <= 0 || i == len(addr)-1 {
+		return "", "", false
+	}
+	return addr[:i], addr[i+1:], true
+}
+
+func asciiDomain(domain string) (string, error) {
+	return idna.Lookup.ToASCII(strings.TrimSuffix(domain, "."))
+}
+
+// EmailPolicy restricts which domains contact addresses may use. A domain
+// entry also matches its subdomains. An empty allow list allows every
+// domain not on the deny list.
+type EmailPolicy struct {
+	Allow []string
+	Deny  []string
+}
+
+// NewEmailPolicy returns a policy for the given domains, normalized to
+// lowercase punycode. Invalid domains are reported as an error.
+func NewEmailPolicy(allow, deny []string) (EmailPolicy, error) {
+	var p EmailPolicy
+	var err error
+	if p.Allow, err = normalizeDomains(allow); err != nil {
+		return EmailPolicy{}, err
+	}
+	if p.Deny, err = normalizeDomains(deny); err != nil {
+		return EmailPolicy{}, err
+	}
+	return p, nil
+}
+
+func normalizeDomains(domains []string) ([]string, error) {
+	var out []string
+	for _, d := range domains {
+		d = strings.TrimPrefix(strings.TrimSpace(d), "@")
+		if d == "" {
+			continue
+		}
+		ascii, err := asciiDomain(d)
+		if err != nil {
+			return nil, errors.New("invalid email domain " + d)
+		}
+		out = append(out, ascii)
+	}
+	return out, nil
+}
+
+// Allows reports whether the policy accepts the address s.
+func (p EmailPolicy) Allows(s string) bool {
+	domain := EmailDomain(s)
+	if domain == "" {
+		return false
+	}
+	for _, d := range p.Deny {
+		if matchDomain(domain, d) {
+			return false
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, d := range p.Allow {
+		if matchDomain(domain, d) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchDomain(domain, pattern string) bool {
+	return domain == pattern || strings.HasSuffix(domain, "."+pattern)
+}
@@ -0,0 +1,96 @@
+package model
+
+import "testing"
+
+func TestParseEmail(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"jane@example.com", "jane@example.com", false},
+		{"  Jane.Doe@Example.com ", "Jane.Doe@Example.com", false},
+		{"Jane Doe <jane@example.com>", "jane@example.com", false},
+		{`"Doe, Jane" <jane@example.com>`, "jane@example.com", false},
+		{"josé@bücher.de", "josé@bücher.de", false},
+		{"jane@xn--bcher-kva.de", "jane@xn--bcher-kva.de", false},
+		{"not-an-email", "", true},
+		{"a@example.com, b@example.com", "", true},
+		{"jane@exa mple.com", "", true},
+		{"jane@-bad-.com", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseEmail(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEmail(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseEmail(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalEmail(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"Jane@Example.com", "jane@example.com", true},
+		{"jane+news@gmail.com", "jane@gmail.com", true},
+		{"Jane+News@Outlook.com", "jane@outlook.com", true},
+		{"jane+news@googlemail.com", "j.ane@gmail.com", true},
+		{"alice+sales@corp.com", "alice+support@corp.com", false},
+		{"alice+sales@corp.com", "alice@corp.com", false},
+		{"Jane Doe <jane@example.com>", "jane@example.com", true},
+		{"j.a.n.e@gmail.com", "jane@gmail.com", true},
+		{"jane@googlemail.com", "jane@gmail.com", true},
+		{"jane@bücher.de", "jane@xn--bcher-kva.de", true},
+		{"jane@BÜCHER.de", "jane@bücher.de", true},
+		{"j.ane@example.com", "jane@example.com", false},
+		{"jane@example.com", "jane@example.org", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"|"+tt.b, func(t *testing.T) {
+			ca, cb := CanonicalEmail(tt.a), CanonicalEmail(tt.b)
+			if (ca == cb) != tt.same {
+				t.Errorf("CanonicalEmail(%q) = %q, CanonicalEmail(%q) = %q, want same=%v", tt.a, ca, tt.b, cb, tt.same)
+			}
+		})
+	}
+}
+
+func TestEmailPolicy(t *testing.T) {
+	p, err := NewEmailPolicy([]string{"example.com", "bücher.de"}, []string{"spam.example.com"})
+	if err != nil {
+		t.Fatalf("NewEmailPolicy: %v", err)
+	}
+
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"jane@example.com", true},
+		{"jane@mail.example.com", true},
+		{"jane@spam.example.com", false},
+		{"jane@xn--bcher-kva.de", true},
+		{"jane@example.org", false},
+		{"jane@notexample.com", false},
+		{"invalid", false},
+	}
+	for _, tt := range tests {
+		if got := p.Allows(tt.email); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+
+	var open EmailPolicy
+	if !open.Allows("anyone@anywhere.test") {
+		t.Error("zero policy should allow every domain")
+	}
+
+	if _, err := NewEmailPolicy([]string{"bad domain"}, nil); err == nil {
+		t.Error("expected error for invalid domain")
+	}
+}
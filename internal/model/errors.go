@@ -5,4 +5,13 @@ import "errors"
 var (
 	ErrNotFound       = errors.New("not found")
 	ErrDuplicateEmail = errors.New("email already exists")
+
+	ErrDuplicateDomain = errors.New("company domain already exists")
+	ErrUnknownCompany  = errors.New("company does not exist")
+
+	ErrDuplicateRelationship = errors.New("relationship already exists")
+
+	ErrQuotaExceeded = errors.New("attachment quota exceeded")
+
+	ErrDuplicateSearch = errors.New("saved search name already exists")
 )
@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+// ContactView records a contact's page being opened.
+type ContactView struct {
+	Contact Contact
+	At      time.Time
+}
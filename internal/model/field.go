@@ -0,0 +1,209 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the kind of value a custom field holds.
+type FieldType string
+
+const (
+	FieldText    FieldType = "text"
+	FieldNumber  FieldType = "number"
+	FieldDate    FieldType = "date"
+	FieldSelect  FieldType = "select"
+	FieldBoolean FieldType = "boolean"
+	FieldURL     FieldType = "url"
+)
+
+// Custom field validation codes, translated as "validation.Custom.<code>".
+const (
+	CodeInvalidNumber = "invalid_number"
+	CodeInvalidDate   = "invalid_date"
+	CodeInvalidURL    = "invalid_url"
+	CodeInvalidOption = "invalid_option"
+)
+
+// DateLayout is the format date fields are stored in, matching the value
+// of an HTML date input.
+const DateLayout = "2006-01-02"
+
+// FieldDef describes a custom field defined by an administrator. Values
+// are stored in Contact.Custom under Key.
+type FieldDef struct {
+	Key      string            `json:"key"`
+	Label    string            `json:"label"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Type     FieldType         `json:"type"`
+	Options  []string          `json:"options,omitempty"`
+	Required bool              `json:"required,omitempty"`
+
+	// List shows the field as a column in the contacts table.
+	List bool `json:"list,omitempty"`
+}
+
+// LabelFor returns the field's label in locale, falling back to Label.
+func (f FieldDef) LabelFor(locale string) string {
+	if l, ok := f.Labels[locale]; ok {
+		return l
+	}
+	return f.Label
+}
+
+// InputName is the field's form input name, which validation errors for
+// the field are also reported under.
+func (f FieldDef) InputName() string {
+	return "custom." + f.Key
+}
+
+// Normalize checks v and returns it in the form it is stored in, or a
+// validation code. An empty value is valid unless the field is required.
+func (f FieldDef) Normalize(v string) (string, string) {
+	v = strings.TrimSpace(v)
+	if f.Type == FieldBoolean {
+		switch strings.ToLower(v) {
+		case "on", "true", "1", "yes":
+			v = "true"
+		default:
+			v = ""
+		}
+	}
+	if v == "" {
+		if f.Required {
+			return "", CodeRequired
+		}
+		return "", ""
+	}
+
+	switch f.Type {
+	case FieldNumber:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return v, CodeInvalidNumber
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), ""
+	case FieldDate:
+		if _, err := time.Parse(DateLayout, v); err != nil {
+			return v, CodeInvalidDate
+		}
+	case FieldURL:
+		if !isWebURL(v) {
+			return v, CodeInvalidURL
+		}
+	case FieldSelect:
+		if !slices.Contains(f.Options, v) {
+			return v, CodeInvalidOption
+		}
+	}
+	return v, ""
+}
+
+// isWebURL reports whether s is an absolute http or https URL.
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// Searchable reports whether the field's values are matched by a search.
+func (f FieldDef) Searchable() bool {
+	return f.Type != FieldBoolean
+}
+
+// Compare orders two stored values of the field: numerically for numbers
+// and lexically otherwise, which is chronological for dates. Text-like
+// fields are compared by the caller's collation instead.
+func (f FieldDef) Compare(a, b string) int {
+	if f.Type == FieldNumber {
+		x, _ := strconv.ParseFloat(a, 64)
+		y, _ := strconv.ParseFloat(b, 64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// Collated reports whether values of the field are natural-language text
+// that should be ordered by collation.
+func (f FieldDef) Collated() bool {
+	switch f.Type {
+	case FieldText, FieldSelect:
+		return true
+	}
+	return false
+}
+
+// CustomValue pairs a field definition with a contact's value for it.
+type CustomValue struct {
+	Field FieldDef
+	Value string
+}
+
+// Field returns the contact's value for f.
+func (c Contact) Field(f FieldDef) CustomValue {
+	return CustomValue{Field: f, Value: c.Custom[f.Key]}
+}
+
+// ValidateFields normalizes custom field values against defs. Values for
+// unknown keys are dropped. It returns the normalized values and a map of
+// input name to validation code.
+func ValidateFields(defs []FieldDef, values map[string]string) (map[string]string, map[string]string) {
+	out := make(map[string]string)
+	errs := make(map[string]string)
+	for _, f := range defs {
+		v, code := f.Normalize(values[f.Key])
+		if code != "" {
+			errs[f.InputName()] = code
+		}
+		if v != "" {
+			out[f.Key] = v
+		}
+	}
+	return out, errs
+}
+
+var fieldKey = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ParseFieldDefs decodes a JSON array of field definitions and checks that
+// keys are unique identifiers, types are known and select fields have
+// options.
+func ParseFieldDefs(data []byte) ([]FieldDef, error) {
+	var defs []FieldDef
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("parsing custom fields: %w", err)
+	}
+	seen := make(map[string]bool)
+	for _, f := range defs {
+		if !fieldKey.MatchString(f.Key) {
+			return nil, fmt.Errorf("custom field key %q must be lowercase letters, digits and underscores", f.Key)
+		}
+		if seen[f.Key] {
+			return nil, fmt.Errorf("duplicate custom field key %q", f.Key)
+		}
+		seen[f.Key] = true
+		switch f.Type {
+		case FieldText, FieldNumber, FieldDate, FieldBoolean, FieldURL:
+		case FieldSelect:
+			if len(f.Options) == 0 {
+				return nil, fmt.Errorf("custom field %q: select needs options", f.Key)
+			}
+		default:
+			return nil, fmt.Errorf("custom field %q: unknown type %q", f.Key, f.Type)
+		}
+		if f.Label == "" {
+			return nil, fmt.Errorf("custom field %q: missing label", f.Key)
+		}
+	}
+	return defs, nil
+}
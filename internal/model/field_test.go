@@ -0,0 +1,87 @@
+package model
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestFieldDef_Normalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    FieldDef
+		in       string
+		want     string
+		wantCode string
+	}{
+		{"text trimmed", FieldDef{Type: FieldText}, "  EMEA-42 ", "EMEA-42", ""},
+		{"optional empty", FieldDef{Type: FieldNumber}, "", "", ""},
+		{"required empty", FieldDef{Type: FieldText, Required: true}, " ", "", CodeRequired},
+		{"number", FieldDef{Type: FieldNumber}, "12.50", "12.5", ""},
+		{"invalid number", FieldDef{Type: FieldNumber}, "1,000", "1,000", CodeInvalidNumber},
+		{"date", FieldDef{Type: FieldDate}, "2026-02-28", "2026-02-28", ""},
+		{"invalid date", FieldDef{Type: FieldDate}, "2026-02-30", "2026-02-30", CodeInvalidDate},
+		{"boolean checked", FieldDef{Type: FieldBoolean}, "on", "true", ""},
+		{"boolean unchecked", FieldDef{Type: FieldBoolean}, "", "", ""},
+		{"url", FieldDef{Type: FieldURL}, "https://example.com/a", "https://example.com/a", ""},
+		{"javascript url", FieldDef{Type: FieldURL}, "javascript:alert(1)", "javascript:alert(1)", CodeInvalidURL},
+		{"relative url", FieldDef{Type: FieldURL}, "/path", "/path", CodeInvalidURL},
+		{"select", FieldDef{Type: FieldSelect, Options: []string{"EMEA", "APAC"}}, "APAC", "APAC", ""},
+		{"invalid option", FieldDef{Type: FieldSelect, Options: []string{"EMEA", "APAC"}}, "Mars", "Mars", CodeInvalidOption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, code := tt.field.Normalize(tt.in)
+			if got != tt.want || code != tt.wantCode {
+				t.Errorf("Normalize(%q) = %q, %q; want %q, %q", tt.in, got, code, tt.want, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	defs := []FieldDef{
+		{Key: "account_id", Label: "Account ID", Type: FieldText, Required: true},
+		{Key: "seats", Label: "Seats", Type: FieldNumber},
+	}
+
+	values, errs := ValidateFields(defs, map[string]string{"seats": "007", "unknown": "x"})
+	if want := map[string]string{"seats": "7"}; !maps.Equal(values, want) {
+		t.Errorf("values = %v, want %v", values, want)
+	}
+	if want := map[string]string{"custom.account_id": CodeRequired}; !maps.Equal(errs, want) {
+		t.Errorf("errs = %v, want %v", errs, want)
+	}
+}
+
+func TestParseFieldDefs(t *testing.T) {
+	defs, err := ParseFieldDefs([]byte(`[
+		{"key": "region", "label": "Region", "labels": {"es": "Región"}, "type": "select", "options": ["EMEA", "APAC"], "list": true},
+		{"key": "vip", "label": "VIP", "type": "boolean"}
+	]`))
+	if err != nil {
+		t.Fatalf("ParseFieldDefs: %v", err)
+	}
+	if len(defs) != 2 || !defs[0].List || defs[1].Type != FieldBoolean {
+		t.Errorf("unexpected definitions: %+v", defs)
+	}
+	if got := defs[0].LabelFor("es"); got != "Región" {
+		t.Errorf("LabelFor(es) = %q", got)
+	}
+	if got := defs[0].LabelFor("fr"); got != "Region" {
+		t.Errorf("LabelFor(fr) = %q", got)
+	}
+
+	invalid := []string{
+		`{}`,
+		`[{"key": "Region", "label": "Region", "type": "text"}]`,
+		`[{"key": "a", "label": "A", "type": "text"}, {"key": "a", "label": "A", "type": "text"}]`,
+		`[{"key": "a", "label": "A", "type": "color"}]`,
+		`[{"key": "a", "label": "A", "type": "select"}]`,
+		`[{"key": "a", "type": "text"}]`,
+	}
+	for _, in := range invalid {
+		if _, err := ParseFieldDefs([]byte(in)); err == nil {
+			t.Errorf("expected error for %s", in)
+		}
+	}
+}
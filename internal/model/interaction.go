@@ -0,0 +1,59 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Interaction is a touchpoint with one or more contacts: a call, meeting,
+// email or note logged at a point in time.
+type Interaction struct {
+	ID         string
+	Type       string
+	At         time.Time
+	Duration   time.Duration
+	ContactIDs []string
+	Body       string
+	CreatedAt  time.Time
+}
+
+// InteractionTypes lists the supported interaction types in the order
+// forms offer them.
+var InteractionTypes = []string{"call", "meeting", "email", "note"}
+
+// Interaction validation codes.
+const (
+	CodeInFuture        = "in_future"
+	CodeInvalidDuration = "invalid_duration"
+)
+
+// Validate checks the interaction's type, time, duration and participants
+// and returns a map of field name to validation error code. Notes need a
+// body; other types may be logged without one.
+func (i Interaction) Validate() map[string]string {
+	errs := make(map[string]string)
+	if !slices.Contains(InteractionTypes, i.Type) {
+		errs["Type"] = CodeInvalidType
+	}
+	switch {
+	case i.At.IsZero():
+		errs["At"] = CodeRequired
+	case i.At.After(time.Now()):
+		errs["At"] = CodeInFuture
+	}
+	if i.Duration < 0 {
+		errs["Duration"] = CodeInvalidDuration
+	}
+	if len(i.ContactIDs) == 0 {
+		errs["ContactIDs"] = CodeRequired
+	}
+	if i.Type == "note" && i.Body == "" {
+		errs["Body"] = CodeRequired
+	}
+	return errs
+}
+
+// Involves reports whether contactID took part in the interaction.
+func (i Interaction) Involves(contactID string) bool {
+	return slices.Contains(i.ContactIDs, contactID)
+}
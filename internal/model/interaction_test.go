@@ -0,0 +1,36 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInteraction_Validate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name string
+		i    Interaction
+		want map[string]string
+	}{
+		{"valid", Interaction{Type: "call", At: past, Duration: 5 * time.Minute, ContactIDs: []string{"1"}}, map[string]string{}},
+		{"unknown type", Interaction{Type: "fax", At: past, ContactIDs: []string{"1"}}, map[string]string{"Type": CodeInvalidType}},
+		{"missing time", Interaction{Type: "call", ContactIDs: []string{"1"}}, map[string]string{"At": CodeRequired}},
+		{"future", Interaction{Type: "call", At: time.Now().Add(time.Hour), ContactIDs: []string{"1"}}, map[string]string{"At": CodeInFuture}},
+		{"negative duration", Interaction{Type: "call", At: past, Duration: -time.Minute, ContactIDs: []string{"1"}}, map[string]string{"Duration": CodeInvalidDuration}},
+		{"no participants", Interaction{Type: "email", At: past}, map[string]string{"ContactIDs": CodeRequired}},
+		{"empty note", Interaction{Type: "note", At: past, ContactIDs: []string{"1"}}, map[string]string{"Body": CodeRequired}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.i.Validate()
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %v", errs, tt.want)
+			}
+			for k, v := range tt.want {
+				if errs[k] != v {
+					t.Errorf("Validate()[%s] = %q, want %q", k, errs[k], v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// NameOrder controls how a contact's name parts are arranged for display.
+type NameOrder int
+
+const (
+	// NameOrderWestern shows the given name first: "Dr. Jane Q. Doe Jr.".
+	NameOrderWestern NameOrder = iota
+	// NameOrderFamilyFirst shows the family name first, as is usual in
+	// Chinese, Japanese, Korean and Hungarian: "Doe Jane Q.".
+	NameOrderFamilyFirst
+)
+
+// ParseNameOrder parses "western" or "family-first".
+func ParseNameOrder(s string) (NameOrder, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "western":
+		return NameOrderWestern, nil
+	case "family-first":
+		return NameOrderFamilyFirst, nil
+	}
+	return NameOrderWestern, fmt.Errorf("unknown name order %q", s)
+}
+
+// Name holds the parts of a personal name. Contact stores the same parts
+// as flat fields; Name is what ParseName produces.
+type Name struct {
+	Prefix   string
+	Given    string
+	Middle   string
+	Family   string
+	Suffix   string
+	Nickname string
+}
+
+// SetName replaces the contact's name parts with n. Phonetic readings are
+// left alone since they can't be derived from the written name.
+func (c *Contact) SetName(n Name) {
+	c.Prefix = n.Prefix
+	c.FirstName = n.Given
+	c.MiddleName = n.Middle
+	c.LastName = n.Family
+	c.Suffix = n.Suffix
+	c.Nickname = n.Nickname
+}
+
+// HasName reports whether the contact has any name that can be displayed.
+func (c Contact) HasName() bool {
+	return strings.TrimSpace(c.FirstName) != "" ||
+		strings.TrimSpace(c.LastName) != "" ||
+		strings.TrimSpace(c.Nickname) != ""
+}
+
+// DisplayName arranges the contact's name parts in the given order. A
+// contact with only a nickname is displayed by it.
+func (c Contact) DisplayName(order NameOrder) string {
+	var parts []string
+	switch order {
+	case NameOrderFamilyFirst:
+		parts = []string{c.LastName, c.FirstName, c.MiddleName}
+	default:
+		parts = []string{c.Prefix, c.FirstName, c.MiddleName, c.LastName, c.Suffix}
+	}
+	if name := joinFields(parts...); name != "" {
+		return name
+	}
+	return strings.TrimSpace(c.Nickname)
+}
+
+// SortKey returns the family and given parts contacts are ordered by,
+// preferring phonetic readings when they are set. A contact without a
+// family name sorts by its given name, or failing that its nickname.
+func (c Contact) SortKey() (family, given string) {
+	family = firstNonEmpty(c.PhoneticLastName, c.LastName)
+	given = firstNonEmpty(c.PhoneticFirstName, c.FirstName)
+	if family == "" {
+		family, given = firstNonEmpty(given, c.Nickname), ""
+	}
+	return family, given
+}
+
+func joinFields(parts ...string) string {
+	var b strings.Builder
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+var (
+	namePrefixes = setOf("mr", "mrs", "ms", "miss", "mx", "dr", "prof", "rev", "fr", "sir", "dame", "hon", "capt", "col", "gen", "lt", "sgt")
+	nameSuffixes = setOf("jr", "sr", "ii", "iii", "iv", "v", "phd", "md", "dds", "esq", "cpa", "mba", "rn", "obe", "mbe")
+	// Lowercase particles that belong to the family name that follows,
+	// as in "Ludwig van Beethoven" or "María de la Cruz".
+	familyParticles = setOf("van", "von", "der", "den", "de", "del", "della", "di", "da", "dos", "das", "du", "la", "le", "ter", "ten", "bin", "binti", "al", "el", "st", "st.")
+)
+
+func setOf(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}
+
+// normalizeAffix lowercases a prefix or suffix candidate and drops dots,
+// so "Ph.D." and "phd" compare equal.
+func normalizeAffix(s string) string {
+	return strings.ToLower(strings.Trim(strings.ReplaceAll(s, ".", ""), ","))
+}
+
+// ParseName splits a free-form personal name such as
+// `Dr. Jane "JJ" Q. Doe Jr.` into its parts. "Family, Given Middle" is
+// always read family-first; without a comma the words are read in order.
+func ParseName(s string, order NameOrder) Name {
+	var n Name
+	s = strings.TrimSpace(s)
+
+	// Nicknames are written in quotes or parentheses.
+	s, n.Nickname = extractNickname(s)
+
+	// "Doe, Jane Q." and "Jane Doe, Jr." both use a comma; only the first
+	// is a family-first name.
+	if before, after, found := strings.Cut(s, ","); found {
+		trailing := strings.Fields(after)
+		if allAffixes(trailing, nameSuffixes) {
+			s = before
+			n.Suffix = strings.Join(trailing, " ")
+		} else {
+			family := strings.Fields(before)
+			return parseWords(append(family, trailing...), NameOrderFamilyFirst, n, len(family))
+		}
+	}
+
+	return parseWords(strings.Fields(s), order, n, 0)
+}
+
+// parseWords assigns words to name parts. familyLen, when non-zero, is
+// the number of leading words known to form the family name.
+func parseWords(words []string, order NameOrder, n Name, familyLen int) Name {
+	// Prefixes lead in either order; suffixes trail.
+	for len(words) > 1 && namePrefixes[normalizeAffix(words[0])] && familyLen == 0 {
+		n.Prefix = joinFields(n.Prefix, words[0])
+		words = words[1:]
+	}
+	var suffixes []string
+	for len(words) > 1 && nameSuffixes[normalizeAffix(words[len(words)-1])] {
+		suffixes = append([]string{strings.TrimSuffix(words[len(words)-1], ",")}, suffixes...)
+		words = words[:len(words)-1]
+	}
+	for i, w := range words {
+		words[i] = strings.TrimSuffix(w, ",")
+	}
+	n.Suffix = joinFields(strings.Join(suffixes, " "), n.Suffix)
+
+	switch {
+	case len(words) == 0:
+		return n
+	case len(words) == 1:
+		n.Given = words[0]
+		return n
+	}
+
+	if order == NameOrderFamilyFirst {
+		if familyLen == 0 {
+			familyLen = 1
+		}
+		if familyLen >= len(words) {
+			n.Family = strings.Join(words, " ")
+			return n
+		}
+		n.Family = strings.Join(words[:familyLen], " ")
+		rest := words[familyLen:]
+		for len(rest) > 1 && namePrefixes[normalizeAffix(rest[0])] {
+			n.Prefix = joinFields(n.Prefix, rest[0])
+			rest = rest[1:]
+		}
+		n.Given = rest[0]
+		n.Middle = strings.Join(rest[1:], " ")
+		return n
+	}
+
+	// Western order: the family name is the last word plus any lowercase
+	// particles directly before it.
+	start := len(words) - 1
+	for start > 1 && isParticle(words[start-1]) {
+		start--
+	}
+	n.Given = words[0]
+	n.Middle = strings.Join(words[1:start], " ")
+	n.Family = strings.Join(words[start:], " ")
+	return n
+}
+
+func isParticle(word string) bool {
+	r := []rune(word)
+	return len(r) > 0 && unicode.IsLower(r[0]) && familyParticles[strings.ToLower(word)]
+}
+
+func allAffixes(words []string, set map[string]bool) bool {
+	for _, w := range words {
+		if !set[normalizeAffix(w)] {
+			return false
+		}
+	}
+	return true
+}
+
+func extractNickname(s string) (rest, nickname string) {
+	for _, pair := range [][2]string{{`"`, `"`}, {"“", "”"}, {"(", ")"}} {
+		start := strings.Index(s, pair[0])
+		if start < 0 {
+			continue
+		}
+		end := strings.Index(s[start+len(pair[0]):], pair[1])
+		if end < 0 {
+			continue
+		}
+		end += start + len(pair[0])
+		nickname = strings.TrimSpace(s[start+len(pair[0]) : end])
+		if nickname == "" {
+			continue
+		}
+		return strings.TrimSpace(s[:start] + " " + s[end+len(pair[1]):]), nickname
+	}
+	return s, ""
+}
+
+// SingleCase reports whether s has letters written in case and they are
+// all capitals or all lowercase, like "JOHN SMITH" or "john smith".
+// A single letter, such as a middle initial, is not counted.
+func SingleCase(s string) bool {
+	upper, lower := 0, 0
+	for _, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	return upper+lower > 1 && (upper == 0 || lower == 0)
+}
+
+// TitleCase capitalizes the words of a name written in a single case, so
+// "O'BRIEN-SMITH" becomes "O'Brien-Smith". Family-name particles such as
+// "van" or "de" stay lowercase when another word follows them. Names in
+// mixed case are returned unchanged.
+func TitleCase(s string) string {
+	if !SingleCase(s) {
+		return s
+	}
+	words := strings.Fields(s)
+	for i, w := range words {
+		lower := strings.ToLower(w)
+		if i < len(words)-1 && familyParticles[lower] {
+			words[i] = lower
+			continue
+		}
+		r := []rune(lower)
+		start := true
+		for j, c := range r {
+			if start && unicode.IsLetter(c) {
+				r[j] = unicode.ToUpper(c)
+			}
+			start = c == '-' || c == '\'' || c == '’'
+		}
+		words[i] = string(r)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,110 @@
+package model
+
+import "testing"
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		in    string
+		order NameOrder
+		want  Name
+	}{
+		{"Dr. Jane Q. Doe Jr.", NameOrderWestern, Name{Prefix: "Dr.", Given: "Jane", Middle: "Q.", Family: "Doe", Suffix: "Jr."}},
+		{"  Jane   Doe ", NameOrderWestern, Name{Given: "Jane", Family: "Doe"}},
+		{"Cher", NameOrderWestern, Name{Given: "Cher"}},
+		{"", NameOrderWestern, Name{}},
+		{`Robert "Bob" Smith`, NameOrderWestern, Name{Given: "Robert", Family: "Smith", Nickname: "Bob"}},
+		{"Elizabeth (Liz) Ann Taylor", NameOrderWestern, Name{Given: "Elizabeth", Middle: "Ann", Family: "Taylor", Nickname: "Liz"}},
+		{"Ludwig van Beethoven", NameOrderWestern, Name{Given: "Ludwig", Family: "van Beethoven"}},
+		{"María José de la Cruz", NameOrderWestern, Name{Given: "María", Middle: "José", Family: "de la Cruz"}},
+		{"Conan O'Brien", NameOrderWestern, Name{Given: "Conan", Family: "O'Brien"}},
+		{"Prof. Dr. Anna Schmidt", NameOrderWestern, Name{Prefix: "Prof. Dr.", Given: "Anna", Family: "Schmidt"}},
+		{"Martin Luther King, Jr.", NameOrderWestern, Name{Given: "Martin", Middle: "Luther", Family: "King", Suffix: "Jr."}},
+		{"John Smith III PhD", NameOrderWestern, Name{Given: "John", Family: "Smith", Suffix: "III PhD"}},
+		{"Doe, Jane Q.", NameOrderWestern, Name{Given: "Jane", Middle: "Q.", Family: "Doe"}},
+		{"van Gogh, Vincent", NameOrderWestern, Name{Given: "Vincent", Family: "van Gogh"}},
+		{"Doe, Dr. Jane, Jr.", NameOrderWestern, Name{Prefix: "Dr.", Given: "Jane", Family: "Doe", Suffix: "Jr."}},
+		{"Yamada Taro", NameOrderFamilyFirst, Name{Given: "Taro", Family: "Yamada"}},
+		{"Kim Min Jun", NameOrderFamilyFirst, Name{Given: "Min", Middle: "Jun", Family: "Kim"}},
+	}
+	for _, tt := range tests {
+		if got := ParseName(tt.in, tt.order); got != tt.want {
+			t.Errorf("ParseName(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestContact_DisplayName(t *testing.T) {
+	c := Contact{Prefix: "Dr.", FirstName: "Jane", MiddleName: "Q.", LastName: "Doe", Suffix: "Jr."}
+	if got := c.DisplayName(NameOrderWestern); got != "Dr. Jane Q. Doe Jr." {
+		t.Errorf("western: got %q", got)
+	}
+	if got := c.DisplayName(NameOrderFamilyFirst); got != "Doe Jane Q." {
+		t.Errorf("family first: got %q", got)
+	}
+	if got := (Contact{Nickname: "Bob"}).DisplayName(NameOrderFamilyFirst); got != "Bob" {
+		t.Errorf("nickname fallback: got %q", got)
+	}
+}
+
+func TestContact_SetName(t *testing.T) {
+	c := Contact{PhoneticLastName: "やまだ"}
+	c.SetName(ParseName("Dr. Jane Q. Doe Jr.", NameOrderWestern))
+	if c.FullName() != "Dr. Jane Q. Doe Jr." {
+		t.Errorf("unexpected full name %q", c.FullName())
+	}
+	if c.PhoneticLastName != "やまだ" {
+		t.Error("SetName must keep phonetic readings")
+	}
+}
+
+func TestContact_SortKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		contact     Contact
+		family, giv string
+	}{
+		{"written", Contact{FirstName: "Jane", LastName: "Doe"}, "Doe", "Jane"},
+		{"phonetic", Contact{FirstName: "太郎", LastName: "山田", PhoneticFirstName: "たろう", PhoneticLastName: "やまだ"}, "やまだ", "たろう"},
+		{"given only", Contact{FirstName: "Cher"}, "Cher", ""},
+		{"nickname only", Contact{Nickname: "Bob"}, "Bob", ""},
+	}
+	for _, tt := range tests {
+		family, given := tt.contact.SortKey()
+		if family != tt.family || given != tt.giv {
+			t.Errorf("%s: SortKey() = (%q, %q), want (%q, %q)", tt.name, family, given, tt.family, tt.giv)
+		}
+	}
+}
+
+func TestParseNameOrder(t *testing.T) {
+	if o, err := ParseNameOrder("family-first"); err != nil || o != NameOrderFamilyFirst {
+		t.Errorf("got %v, %v", o, err)
+	}
+	if o, err := ParseNameOrder(""); err != nil || o != NameOrderWestern {
+		t.Errorf("got %v, %v", o, err)
+	}
+	if _, err := ParseNameOrder("eastern"); err == nil {
+		t.Error("expected error for unknown order")
+	}
+}
+
+func TestTitleCase(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"JOHN", "John"},
+		{"o'brien-smith", "O'Brien-Smith"},
+		{"VAN DER BERG", "van der Berg"},
+		{"DE", "De"},
+		{"ÉMILE  ZOLA", "Émile Zola"},
+		{"McDonald", "McDonald"},
+		{"J", "J"},
+		{"李", "李"},
+	}
+	for _, tt := range tests {
+		if got := TitleCase(tt.in); got != tt.want {
+			t.Errorf("TitleCase(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if SingleCase("Q") || SingleCase("Ann") || !SingleCase("ANN") || !SingleCase("ann lee") {
+		t.Error("unexpected SingleCase")
+	}
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxNoteLength bounds the length of a note, in characters.
+const MaxNoteLength = 20000
+
+// CodeTooLong is reported for text over its length limit.
+const CodeTooLong = "too_long"
+
+// Note is free-form context about a contact, written in Markdown. At most
+// one of a contact's notes is pinned, to the top of the contact's page.
+type Note struct {
+	ID        string
+	ContactID string
+	Body      string
+	Pinned    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Validate checks the note's body and returns a map of field name to
+// validation error code.
+func (n Note) Validate() map[string]string {
+	errs := make(map[string]string)
+	switch {
+	case strings.TrimSpace(n.Body) == "":
+		errs["Body"] = CodeRequired
+	case utf8.RuneCountInString(n.Body) > MaxNoteLength:
+		errs["Body"] = CodeTooLong
+	}
+	return errs
+}
+
+// Excerpt returns about width characters of text around the first
+// case-insensitive occurrence of term, with runs of whitespace collapsed
+// and an ellipsis where text was cut. Without a match it returns the
+// start of text.
+func Excerpt(text, term string, width int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	needle := []rune(strings.TrimSpace(term))
+
+	at := 0
+	for i := 0; len(needle) > 0 && i+len(needle) <= len(runes); i++ {
+		if strings.EqualFold(string(runes[i:i+len(needle)]), string(needle)) {
+			at = max(i-(width-len(needle))/2, 0)
+			break
+		}
+	}
+	end := min(at+width, len(runes))
+	at = max(min(at, end-width), 0)
+
+	excerpt := strings.TrimSpace(string(runes[at:end]))
+	if at > 0 {
+		excerpt = "…" + excerpt
+	}
+	if end < len(runes) {
+		excerpt += "…"
+	}
+	return excerpt
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNote_Validate(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"Met at **GopherCon**.", ""},
+		{"  \n ", CodeRequired},
+		{strings.Repeat("é", MaxNoteLength), ""},
+		{strings.Repeat("é", MaxNoteLength+1), CodeTooLong},
+	}
+	for _, tt := range tests {
+		if got := (Note{ContactID: "1", Body: tt.body}).Validate()["Body"]; got != tt.want {
+			t.Errorf("Validate(%.20q…) Body = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	text := "Loves  climbing\nand\tcoffee. Has two kids and a dog called Biscuit, and is moving to Lisbon next spring."
+	tests := []struct {
+		term  string
+		width int
+		want  string
+	}{
+		{"biscuit", 20, "…alled Biscuit, and i…"},
+		{"LOVES", 20, "Loves climbing and c…"},
+		{"spring", 20, "…Lisbon next spring."},
+		{"missing", 14, "Loves climbing…"},
+		{"dog", 500, "Loves climbing and coffee. Has two kids and a dog called Biscuit, and is moving to Lisbon next spring."},
+	}
+	for _, tt := range tests {
+		if got := Excerpt(text, tt.term, tt.width); got != tt.want {
+			t.Errorf("Excerpt(%q, %d) = %q, want %q", tt.term, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Phone numbers have between minPhoneDigits and maxPhoneDigits digits;
+// E.164 allows at most 15.
+const (
+	minPhoneDigits = 7
+	maxPhoneDigits = 15
+)
+
+// phoneNumber matches a number written with digits and the usual
+// separators, an optional leading "+" or "00", and an optional extension
+// after "x", "ext" or "#".
+var phoneNumber = regexp.MustCompile(`(?i)^(\+|00)?([\d\s().\-/]+?)\s*(?:(?:ext\.?|x|#)\s*(\d{1,6}))?$`)
+
+// NormalizePhone returns phone in a consistent format, or false if it
+// isn't a plausible phone number. International numbers, written with a
+// leading "+" or "00", become "+" and their digits as in E.164. National
+// numbers of 7 or 10 digits are grouped as 555-0101 and 555-555-0101, and
+// other national numbers are left as digits. An extension is kept as
+// " x" and its digits.
+func NormalizePhone(phone string) (string, bool) {
+	m := phoneNumber.FindStringSubmatch(strings.TrimSpace(phone))
+	if m == nil {
+		return "", false
+	}
+	var b strings.Builder
+	for _, r := range m[2] {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	d := b.String()
+	if len(d) < minPhoneDigits || len(d) > maxPhoneDigits {
+		return "", false
+	}
+
+	var out string
+	switch {
+	case m[1] != "":
+		out = "+" + d
+	case len(d) == 7:
+		out = d[:3] + "-" + d[3:]
+	case len(d) == 10:
+		out = d[:3] + "-" + d[3:6] + "-" + d[6:]
+	default:
+		out = d
+	}
+	if m[3] != "" {
+		out += " x" + m[3]
+	}
+	return out, true
+}
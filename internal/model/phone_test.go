@@ -0,0 +1,30 @@
+package model
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"555-0101", "555-0101", true},
+		{"5550101", "555-0101", true},
+		{" (555) 555.0101 ", "555-555-0101", true},
+		{"555 555 0101 ext. 12", "555-555-0101 x12", true},
+		{"555-555-0101x7", "555-555-0101 x7", true},
+		{"+44 20 7946 0958", "+442079460958", true},
+		{"0044 (20) 7946-0958 #3", "+442079460958 x3", true},
+		{"01234 567890", "01234567890", true},
+		{"555-CALL-NOW", "", false},
+		{"12345", "", false},
+		{"+1 2345 6789 0123 4567", "", false},
+		{"555--0101+", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizePhone(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizePhone(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
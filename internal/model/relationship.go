@@ -0,0 +1,77 @@
+package model
+
+import "time"
+
+// Relationship records that To is Type to From, e.g. From's manager or
+// spouse. One-way relationships are only seen from From; bidirectional
+// ones are also seen from To with the inverse type.
+type Relationship struct {
+	ID            string
+	FromID        string
+	ToID          string
+	Type          string
+	Bidirectional bool
+	CreatedAt     time.Time
+}
+
+// relationInverses maps each relationship type to its inverse.
+var relationInverses = map[string]string{
+	"manager":   "report",
+	"report":    "manager",
+	"parent":    "child",
+	"child":     "parent",
+	"mentor":    "mentee",
+	"mentee":    "mentor",
+	"spouse":    "spouse",
+	"partner":   "partner",
+	"sibling":   "sibling",
+	"friend":    "friend",
+	"colleague": "colleague",
+}
+
+// RelationTypes lists the supported relationship types in the order forms
+// offer them.
+var RelationTypes = []string{
+	"manager", "report", "colleague", "mentor", "mentee",
+	"spouse", "partner", "parent", "child", "sibling", "friend",
+}
+
+// Relationship validation codes.
+const (
+	CodeInvalidType    = "invalid_type"
+	CodeSelf           = "self"
+	CodeUnknownContact = "unknown_contact"
+)
+
+// Validate checks the relationship's endpoints and type and returns a map
+// of field name to validation error code.
+func (r Relationship) Validate() map[string]string {
+	errs := make(map[string]string)
+	if _, ok := relationInverses[r.Type]; !ok {
+		errs["Type"] = CodeInvalidType
+	}
+	switch {
+	case r.ToID == "":
+		errs["ToID"] = CodeRequired
+	case r.ToID == r.FromID:
+		errs["ToID"] = CodeSelf
+	}
+	return errs
+}
+
+// Involves reports whether contactID is either end of the relationship.
+func (r Relationship) Involves(contactID string) bool {
+	return r.FromID == contactID || r.ToID == contactID
+}
+
+// From returns the other contact and the relationship type as seen from
+// contactID, and false if the relationship isn't visible from there.
+func (r Relationship) From(contactID string) (otherID, typ string, ok bool) {
+	switch {
+	case r.FromID == contactID:
+		return r.ToID, r.Type, true
+	case r.ToID == contactID && r.Bidirectional:
+		return r.FromID, relationInverses[r.Type], true
+	}
+	return "", "", false
+}
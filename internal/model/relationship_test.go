@@ -0,0 +1,52 @@
+package model
+
+import "testing"
+
+func TestRelationship_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		rel  Relationship
+		want map[string]string
+	}{
+		{"valid", Relationship{FromID: "1", ToID: "2", Type: "manager"}, map[string]string{}},
+		{"unknown type", Relationship{FromID: "1", ToID: "2", Type: "nemesis"}, map[string]string{"Type": CodeInvalidType}},
+		{"missing contact", Relationship{FromID: "1", Type: "friend"}, map[string]string{"ToID": CodeRequired}},
+		{"self", Relationship{FromID: "1", ToID: "1", Type: "friend"}, map[string]string{"ToID": CodeSelf}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.rel.Validate()
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %v", errs, tt.want)
+			}
+			for k, v := range tt.want {
+				if errs[k] != v {
+					t.Errorf("Validate()[%s] = %q, want %q", k, errs[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestRelationship_From(t *testing.T) {
+	oneWay := Relationship{FromID: "bob", ToID: "alice", Type: "manager"}
+	both := Relationship{FromID: "bob", ToID: "alice", Type: "manager", Bidirectional: true}
+
+	if other, typ, ok := oneWay.From("bob"); !ok || other != "alice" || typ != "manager" {
+		t.Errorf("From(bob) = %q, %q, %v", other, typ, ok)
+	}
+	if _, _, ok := oneWay.From("alice"); ok {
+		t.Error("one-way relationship should not be visible from alice")
+	}
+	if other, typ, ok := both.From("alice"); !ok || other != "bob" || typ != "report" {
+		t.Errorf("From(alice) = %q, %q, %v; want bob, report", other, typ, ok)
+	}
+	if _, _, ok := both.From("carol"); ok {
+		t.Error("relationship should not be visible from an uninvolved contact")
+	}
+	for _, typ := range RelationTypes {
+		if relationInverses[relationInverses[typ]] != typ {
+			t.Errorf("inverse of inverse of %s is not %s", typ, typ)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// Reminder is a follow-up with a contact due at a point in time. Once due
+// the scheduler delivers it as a notification; snoozing moves Due and
+// allows it to be delivered again.
+type Reminder struct {
+	ID        string
+	ContactID string
+	Note      string
+	Due       time.Time
+
+	// NotifiedAt is when the reminder was delivered for its current due
+	// time, and CompletedAt when it was marked done. Both are zero until
+	// then.
+	NotifiedAt  time.Time
+	CompletedAt time.Time
+	CreatedAt   time.Time
+}
+
+// Validate checks the reminder's contact and due time and returns a map
+// of field name to validation error code.
+func (r Reminder) Validate() map[string]string {
+	errs := make(map[string]string)
+	if r.ContactID == "" {
+		errs["ContactID"] = CodeRequired
+	}
+	if r.Due.IsZero() {
+		errs["Due"] = CodeRequired
+	}
+	return errs
+}
+
+// Completed reports whether the reminder was marked done.
+func (r Reminder) Completed() bool {
+	return !r.CompletedAt.IsZero()
+}
+
+// Overdue reports whether the open reminder was due before the day
+// containing now, in now's location.
+func (r Reminder) Overdue(now time.Time) bool {
+	return !r.Completed() && r.Due.Before(StartOfDay(now))
+}
+
+// DueToday reports whether the open reminder is due on the day containing
+// now, in now's location.
+func (r Reminder) DueToday(now time.Time) bool {
+	start := StartOfDay(now)
+	return !r.Completed() && !r.Due.Before(start) && r.Due.Before(start.AddDate(0, 0, 1))
+}
+
+// Snooze moves the reminder's due time to until and makes it deliverable
+// again.
+func (r *Reminder) Snooze(until time.Time) {
+	r.Due = until
+	r.NotifiedAt = time.Time{}
+}
+
+// StartOfDay returns midnight at the start of t's day in t's location.
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// Notification is an in-app message, such as a delivered reminder.
+type Notification struct {
+	ID         string
+	ContactID  string
+	ReminderID string
+	Message    string
+	CreatedAt  time.Time
+	Read       bool
+}
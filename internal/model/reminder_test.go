@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReminder_Due(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*3600)
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, loc)
+
+	tests := []struct {
+		name           string
+		due            time.Time
+		overdue, today bool
+	}{
+		{"yesterday", time.Date(2026, 3, 9, 23, 0, 0, 0, loc), true, false},
+		{"earlier today", time.Date(2026, 3, 10, 9, 0, 0, 0, loc), false, true},
+		{"later today", time.Date(2026, 3, 10, 23, 59, 0, 0, loc), false, true},
+		{"tomorrow", time.Date(2026, 3, 11, 0, 0, 0, 0, loc), false, false},
+		// 02:00 UTC on the 11th is still the 10th five hours west.
+		{"other zone", time.Date(2026, 3, 11, 2, 0, 0, 0, time.UTC), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Reminder{ContactID: "1", Due: tt.due}
+			if got := r.Overdue(now); got != tt.overdue {
+				t.Errorf("Overdue = %v, want %v", got, tt.overdue)
+			}
+			if got := r.DueToday(now); got != tt.today {
+				t.Errorf("DueToday = %v, want %v", got, tt.today)
+			}
+			r.CompletedAt = now
+			if r.Overdue(now) || r.DueToday(now) {
+				t.Error("completed reminder should be neither overdue nor due")
+			}
+		})
+	}
+}
+
+func TestReminder_Snooze(t *testing.T) {
+	now := time.Now()
+	r := Reminder{ContactID: "1", Due: now.Add(-time.Hour), NotifiedAt: now}
+	r.Snooze(now.Add(24 * time.Hour))
+	if !r.NotifiedAt.IsZero() || !r.Due.Equal(now.Add(24*time.Hour)) {
+		t.Errorf("Snooze left %+v", r)
+	}
+
+	if errs := (Reminder{}).Validate(); errs["ContactID"] != CodeRequired || errs["Due"] != CodeRequired {
+		t.Errorf("Validate() = %v", errs)
+	}
+}
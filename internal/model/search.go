@@ -0,0 +1,38 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxSearchNameLength bounds the name of a saved search, in characters.
+const MaxSearchNameLength = 60
+
+// SavedSearch is a named contacts list: its search text, filters and
+// sort, kept as the list's encoded URL query (e.g.
+// "contacted=over-30d&q=acme&sort=name") so opening it later, or from a
+// shared link, matches the contacts as they are then.
+type SavedSearch struct {
+	ID string
+
+	// Owner is the ID of the user who saved the search; only they see it
+	// in their sidebar.
+	Owner     string
+	Name      string
+	Query     string
+	CreatedAt time.Time
+}
+
+// Validate checks the saved search's name and returns a map of field name
+// to validation error code.
+func (s SavedSearch) Validate() map[string]string {
+	errs := make(map[string]string)
+	switch name := strings.TrimSpace(s.Name); {
+	case name == "":
+		errs["Name"] = CodeRequired
+	case utf8.RuneCountInString(name) > MaxSearchNameLength:
+		errs["Name"] = CodeTooLong
+	}
+	return errs
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSavedSearch_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Lapsed customers", ""},
+		{"   ", CodeRequired},
+		{strings.Repeat("ü", MaxSearchNameLength), ""},
+		{strings.Repeat("ü", MaxSearchNameLength+1), CodeTooLong},
+	}
+	for _, tt := range tests {
+		if got := (SavedSearch{Name: tt.name, Query: "q=acme"}).Validate()["Name"]; got != tt.want {
+			t.Errorf("Validate(%.20q…) Name = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
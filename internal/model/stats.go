@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// WeekCount is the number of contacts created in the week starting at
+// Start.
+type WeekCount struct {
+	Start time.Time
+	Count int
+}
+
+// DomainCount is the number of contacts with an email address at Domain.
+type DomainCount struct {
+	Domain string
+	Count  int
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// TrashedContact is a deleted contact, kept until it is restored or
+// deleted for good. Attachments are its attachments when it was deleted,
+// whose blobs outlive it until it is purged.
+type TrashedContact struct {
+	Contact     Contact
+	DeletedAt   time.Time
+	Attachments []Attachment
+}
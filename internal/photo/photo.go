@@ -0,0 +1,129 @@
+// Package photo turns uploaded images into square JPEG thumbnails using
+// only the standard library's image packages. Re-encoding drops EXIF and
+// any other metadata the upload carried.
+package photo
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+// MaxBytes is the largest upload Process accepts.
+const MaxBytes = 10 << 20
+
+// MaxPixels bounds the decoded size of an upload, so a small file can't
+// claim dimensions that would exhaust memory when decoded. Processing
+// holds a few full-size RGBA copies, about 80 MB each at this size, which
+// is still far more detail than the largest output needs.
+const MaxPixels = 20_000_000
+
+// ContentType is the media type of the images Process returns.
+const ContentType = "image/jpeg"
+
+var (
+	// ErrTooLarge is returned for uploads over MaxBytes or MaxPixels.
+	ErrTooLarge = errors.New("photo too large")
+
+	// ErrUnsupported is returned for uploads that aren't JPEG, PNG or GIF
+	// images, judged by their content rather than their name.
+	ErrUnsupported = errors.New("unsupported image")
+)
+
+// Size is a rendition of a photo, Pixels wide and high.
+type Size struct {
+	Name   string
+	Pixels int
+}
+
+// Sizes are the renditions Process produces: a thumbnail for lists and
+// avatars and a larger one for the contact page, both large enough for
+// high-density screens.
+var Sizes = []Size{
+	{Name: "thumb", Pixels: 96},
+	{Name: "medium", Pixels: 480},
+}
+
+// SizeNamed returns the size with the name.
+func SizeNamed(name string) (Size, bool) {
+	for _, s := range Sizes {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Size{}, false
+}
+
+// decoders by sniffed content type. Only these formats are accepted.
+var decoders = map[string]func(io.Reader) (image.Image, error){
+	"image/jpeg": jpeg.Decode,
+	"image/png":  png.Decode,
+	"image/gif":  gif.Decode,
+}
+
+var configDecoders = map[string]func(io.Reader) (image.Config, error){
+	"image/jpeg": jpeg.DecodeConfig,
+	"image/png":  png.DecodeConfig,
+	"image/gif":  gif.DecodeConfig,
+}
+
+// Process reads an uploaded image and returns its renditions as JPEGs,
+// keyed by size name. The image is turned upright according to its EXIF
+// orientation, cropped to a centered square and scaled down; smaller
+// images are not scaled up. Transparent areas become white.
+func Process(r io.Reader) (map[string][]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	kind := http.DetectContentType(data)
+	decode, ok := decoders[kind]
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	cfg, err := configDecoders[kind](bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+	src, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	// Flatten onto white in RGBA, which the transforms below work on.
+	b := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, b.Min, draw.Over)
+
+	if kind == "image/jpeg" {
+		flat = orient(flat, exifOrientation(data))
+	}
+	square := cropSquare(flat)
+
+	out := make(map[string][]byte, len(Sizes))
+	for _, s := range Sizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resize(square, s.Pixels), &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+		out[s.Name] = buf.Bytes()
+	}
+	return out, nil
+}
@@ -0,0 +1,189 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+var (
+	red  = color.RGBA{255, 0, 0, 255}
+	blue = color.RGBA{0, 0, 255, 255}
+)
+
+// halves returns a w×h image, red on the left half and blue on the right.
+func halves(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, red)
+			} else {
+				img.Set(x, y, blue)
+			}
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withOrientation inserts an EXIF APP1 segment with the orientation right
+// after a JPEG's start-of-image marker.
+func withOrientation(jpg []byte, o uint16, order binary.ByteOrder) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)       // one entry
+	order.PutUint16(tiff[10:], 0x0112) // orientation
+	order.PutUint16(tiff[12:], 3)      // SHORT
+	order.PutUint32(tiff[14:], 1)      // count
+	order.PutUint16(tiff[18:], o)      // value
+	seg := append([]byte("Exif\x00\x00"), tiff...)
+
+	out := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(out[4:], uint16(len(seg)+2))
+	out = append(out, seg...)
+	return append(out, jpg[2:]...)
+}
+
+func TestProcess(t *testing.T) {
+	out, err := Process(bytes.NewReader(encodePNG(t, halves(600, 300))))
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	for _, tt := range []struct {
+		name string
+		side int
+	}{{"thumb", 96}, {"medium", 300}} {
+		img, err := jpeg.Decode(bytes.NewReader(out[tt.name]))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if b := img.Bounds(); b.Dx() != tt.side || b.Dy() != tt.side {
+			t.Errorf("%s is %v, want %d square", tt.name, b, tt.side)
+		}
+		// The centered square straddles both halves.
+		l, _, _, _ := img.At(1, tt.side/2).RGBA()
+		_, _, r, _ := img.At(tt.side-2, tt.side/2).RGBA()
+		if l < 0xc000 || r < 0xc000 {
+			t.Errorf("%s: expected red on the left and blue on the right", tt.name)
+		}
+	}
+}
+
+func TestProcess_StripsEXIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, halves(40, 20), nil); err != nil {
+		t.Fatal(err)
+	}
+	src := withOrientation(buf.Bytes(), 6, binary.BigEndian)
+	if exifOrientation(src) != 6 {
+		t.Fatal("test image has no orientation")
+	}
+
+	out, err := Process(bytes.NewReader(src))
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	for name, data := range out {
+		if bytes.Contains(data, []byte("Exif")) {
+			t.Errorf("%s still carries EXIF data", name)
+		}
+	}
+}
+
+func TestProcess_Rejects(t *testing.T) {
+	huge := encodePNG(t, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	// Claim 6000×4000, a 24-megapixel camera's size, in the header and
+	// fix up its checksum.
+	binary.BigEndian.PutUint32(huge[16:], 6000)
+	binary.BigEndian.PutUint32(huge[20:], 4000)
+	binary.BigEndian.PutUint32(huge[29:], crc32.ChecksumIEEE(huge[12:29]))
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("just some text, named photo.jpg"), ErrUnsupported},
+		{"html", []byte("<html><body>not an image</body></html>"), ErrUnsupported},
+		{"truncated png", encodePNG(t, halves(10, 10))[:40], ErrUnsupported},
+		{"too many bytes", append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, MaxBytes)...), ErrTooLarge},
+		{"too many pixels", huge, ErrTooLarge},
+	}
+	for _, tt := range tests {
+		if _, err := Process(bytes.NewReader(tt.data)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestExifOrientation(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, halves(4, 2), nil); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.Bytes()
+
+	if got := exifOrientation(plain); got != 1 {
+		t.Errorf("without EXIF: %d, want 1", got)
+	}
+	if got := exifOrientation(withOrientation(plain, 8, binary.LittleEndian)); got != 8 {
+		t.Errorf("little-endian: %d, want 8", got)
+	}
+	if got := exifOrientation(withOrientation(plain, 3, binary.BigEndian)); got != 3 {
+		t.Errorf("big-endian: %d, want 3", got)
+	}
+	if got := exifOrientation(withOrientation(plain, 42, binary.BigEndian)); got != 1 {
+		t.Errorf("out of range: %d, want 1", got)
+	}
+	if got := exifOrientation([]byte(strings.Repeat("\xff", 10))); got != 1 {
+		t.Errorf("garbage: %d, want 1", got)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 2×1: red then blue.
+	src := halves(2, 1)
+	tests := []struct {
+		o          int
+		w, h       int
+		redX, redY int
+	}{
+		{1, 2, 1, 0, 0},
+		{2, 2, 1, 1, 0},
+		{3, 2, 1, 1, 0},
+		{6, 1, 2, 0, 0}, // clockwise: the left edge becomes the top
+		{8, 1, 2, 0, 1}, // counter-clockwise: the left edge becomes the bottom
+	}
+	for _, tt := range tests {
+		got := orient(src, tt.o)
+		if b := got.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("orientation %d: size %v, want %dx%d", tt.o, b, tt.w, tt.h)
+			continue
+		}
+		if got.RGBAAt(tt.redX, tt.redY) != red {
+			t.Errorf("orientation %d: expected red at (%d,%d)", tt.o, tt.redX, tt.redY)
+		}
+	}
+}
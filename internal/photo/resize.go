@@ -0,0 +1,94 @@
+package photo
+
+import "image"
+
+// orient turns an image stored with EXIF orientation o upright, applying
+// the transform each orientation calls for. Orientations 5-8 swap width
+// and height.
+func orient(src *image.RGBA, o int) *image.RGBA {
+	if o < 2 || o > 8 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if o >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch o {
+			case 2: // flip horizontally
+				sx, sy = w-1-x, y
+			case 3: // rotate 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // flip vertically
+				sx, sy = x, h-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // rotate 90° clockwise
+				sx, sy = y, h-1-x
+			case 7: // transverse
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotate 90° counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			si, di := src.PixOffset(sx, sy), dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// cropSquare returns the largest centered square of img.
+func cropSquare(img *image.RGBA) *image.RGBA {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	return img.SubImage(image.Rect(x0, y0, x0+side, y0+side)).(*image.RGBA)
+}
+
+// resize scales a square image down to size pixels square by averaging
+// the source pixels each destination pixel covers. Images already no
+// larger than size are returned as they are.
+func resize(src *image.RGBA, size int) *image.RGBA {
+	b := src.Bounds()
+	n := b.Dx()
+	if n <= size {
+		return src
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0, y1 := span(y, n, size)
+		for x := 0; x < size; x++ {
+			x0, x1 := span(x, n, size)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.PixOffset(b.Min.X+x0, b.Min.Y+sy)
+				for sx := x0; sx < x1; sx++ {
+					p := src.Pix[row+(sx-x0)*4:]
+					sum[0] += int(p[0])
+					sum[1] += int(p[1])
+					sum[2] += int(p[2])
+					sum[3] += int(p[3])
+				}
+			}
+			count := (x1 - x0) * (y1 - y0)
+			d := dst.Pix[dst.PixOffset(x, y):]
+			for c := range sum {
+				d[c] = uint8((sum[c] + count/2) / count)
+			}
+		}
+	}
+	return dst
+}
+
+// span returns the source pixels [lo, hi) destination pixel i of size
+// covers in a source n pixels across.
+func span(i, n, size int) (lo, hi int) {
+	lo = i * n / size
+	hi = max((i+1)*n/size, lo+1)
+	return lo, hi
+}
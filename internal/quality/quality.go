@@ -0,0 +1,253 @@
+// Package quality checks contacts for data-quality issues, such as
+// malformed phone numbers or names typed in capitals, and fixes those
+// that can be fixed without asking.
+package quality
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// Checks, in the order reports list them.
+const (
+	MissingPhone   = "missing_phone"
+	MalformedPhone = "malformed_phone"
+	NameCase       = "name_case"
+	DeadDomain     = "dead_domain"
+)
+
+// Checks are the checks an Analyzer can run.
+var Checks = []string{MissingPhone, MalformedPhone, NameCase, DeadDomain}
+
+// DNS lookups of email domains are made at most lookupConcurrency at a
+// time, each given lookupTimeout.
+const (
+	lookupConcurrency = 8
+	lookupTimeout     = 3 * time.Second
+)
+
+// Resolver looks up the DNS records that show an email domain exists;
+// *net.Resolver implements it.
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// Options configures an Analyzer.
+type Options struct {
+	// Checks are the checks to run; all of them when empty.
+	Checks []string
+
+	// DeadDomains are email domains known to no longer receive mail.
+	// Subdomains match too.
+	DeadDomains []string
+
+	// Resolver, if set, is asked about the other email domains, and those
+	// with neither MX nor address records are reported as dead.
+	Resolver Resolver
+}
+
+// Analyzer runs checks over contacts. The zero Analyzer runs every check
+// without a list of dead domains or DNS lookups.
+type Analyzer struct {
+	checks   []string
+	dead     model.EmailPolicy
+	resolver Resolver
+}
+
+// New returns an Analyzer for the options, reporting unknown checks and
+// invalid domains as an error.
+func New(opts Options) (Analyzer, error) {
+	a := Analyzer{resolver: opts.Resolver}
+	for _, name := range opts.Checks {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(Checks, name) {
+			return Analyzer{}, fmt.Errorf("unknown data quality check %q", name)
+		}
+		a.checks = append(a.checks, name)
+	}
+	var err error
+	if a.dead, err = model.NewEmailPolicy(nil, opts.DeadDomains); err != nil {
+		return Analyzer{}, err
+	}
+	return a, nil
+}
+
+// Enabled returns the checks the analyzer runs, in report order.
+func (a Analyzer) Enabled() []string {
+	if len(a.checks) == 0 {
+		return Checks
+	}
+	return slices.DeleteFunc(slices.Clone(Checks), func(c string) bool {
+		return !slices.Contains(a.checks, c)
+	})
+}
+
+// Issue is a contact failing a check. Value is the part of the contact at
+// fault and Suggestion what Fix would change it to, or "" if the issue
+// has no automatic fix.
+type Issue struct {
+	Check      string
+	Contact    model.Contact
+	Value      string
+	Suggestion string
+}
+
+// Group is a check and the contacts failing it.
+type Group struct {
+	Check  string
+	Issues []Issue
+}
+
+// Fixable returns the issues that can be fixed automatically.
+func (g Group) Fixable() []Issue {
+	var out []Issue
+	for _, i := range g.Issues {
+		if i.Suggestion != "" {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// Analyze runs the enabled checks over contacts, returning a group for
+// each check, in report order, with the contacts in the order given.
+func (a Analyzer) Analyze(ctx context.Context, contacts []model.Contact) []Group {
+	dead := a.deadDomains(ctx, contacts)
+	var groups []Group
+	for _, check := range a.Enabled() {
+		g := Group{Check: check}
+		for _, c := range contacts {
+			if issue, ok := inspect(c, check, dead); ok {
+				g.Issues = append(g.Issues, issue)
+			}
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// inspect runs one check over c, given the dead email domains.
+func inspect(c model.Contact, check string, dead map[string]bool) (Issue, bool) {
+	issue := Issue{Check: check, Contact: c}
+	switch check {
+	case MissingPhone:
+		return issue, strings.TrimSpace(c.Phone) == ""
+	case MalformedPhone:
+		if strings.TrimSpace(c.Phone) == "" {
+			return issue, false
+		}
+		issue.Value = c.Phone
+		phone, ok := model.NormalizePhone(c.Phone)
+		if ok && phone == c.Phone {
+			return issue, false
+		}
+		issue.Suggestion = phone
+		return issue, true
+	case NameCase:
+		fixed, ok := Fix(c, NameCase)
+		if !ok {
+			return issue, false
+		}
+		issue.Value, issue.Suggestion = c.FullName(), fixed.FullName()
+		return issue, true
+	case DeadDomain:
+		issue.Value = c.Email
+		return issue, dead[model.EmailDomain(c.Email)]
+	}
+	return issue, false
+}
+
+// Fix returns c with the issue the check finds fixed, or false if the
+// check finds no issue or the issue has no automatic fix. The result
+// still has to be validated and saved like any other change.
+func Fix(c model.Contact, check string) (model.Contact, bool) {
+	switch check {
+	case MalformedPhone:
+		phone, ok := model.NormalizePhone(c.Phone)
+		if !ok || phone == c.Phone {
+			return c, false
+		}
+		c.Phone = phone
+		return c, true
+	case NameCase:
+		fixed := false
+		for _, part := range []*string{&c.FirstName, &c.MiddleName, &c.LastName} {
+			if model.SingleCase(*part) {
+				*part = model.TitleCase(*part)
+				fixed = true
+			}
+		}
+		return c, fixed
+	}
+	return c, false
+}
+
+// deadDomains returns the email domains of contacts that are on the dead
+// list or, with a resolver, don't exist in DNS.
+func (a Analyzer) deadDomains(ctx context.Context, contacts []model.Contact) map[string]bool {
+	dead := make(map[string]bool)
+	if !slices.Contains(a.Enabled(), DeadDomain) {
+		return dead
+	}
+	var lookup []string
+	for _, c := range contacts {
+		domain := model.EmailDomain(c.Email)
+		if domain == "" {
+			continue
+		}
+		if _, seen := dead[domain]; seen {
+			continue
+		}
+		dead[domain] = !a.dead.Allows(c.Email)
+		if !dead[domain] && a.resolver != nil {
+			lookup = append(lookup, domain)
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, lookupConcurrency)
+	for _, domain := range lookup {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if !a.exists(ctx, domain) {
+				mu.Lock()
+				dead[domain] = true
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return dead
+}
+
+// exists reports whether domain has MX or address records. Only a
+// definite "no such host" counts as missing, so lookups that fail for
+// other reasons don't report a domain as dead.
+func (a Analyzer) exists(ctx context.Context, domain string) bool {
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	if _, err := a.resolver.LookupMX(ctx, domain); !notFound(err) {
+		return true
+	}
+	_, err := a.resolver.LookupHost(ctx, domain)
+	return !notFound(err)
+}
+
+func notFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
@@ -0,0 +1,130 @@
+package quality
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+var contacts = []model.Contact{
+	{ID: "1", FirstName: "Alice", LastName: "Johnson", Email: "alice@example.com", Phone: "555-0101"},
+	{ID: "2", FirstName: "BOB", LastName: "SMITH", Email: "bob@oldmail.test", Phone: "(555) 555.0102"},
+	{ID: "3", FirstName: "carol", MiddleName: "J", LastName: "Williams", Email: "carol@gone.example", Phone: ""},
+	{ID: "4", FirstName: "David", LastName: "Brown", Email: "david@mail.oldmail.test", Phone: "call me"},
+}
+
+// fakeResolver knows the domains in hosts; others don't exist, except
+// those in failing, which fail to resolve.
+type fakeResolver struct {
+	hosts   map[string]bool
+	failing map[string]bool
+}
+
+func (f fakeResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	if f.failing[name] {
+		return nil, &net.DNSError{Err: "timeout", Name: name, IsTimeout: true}
+	}
+	if f.hosts[name] {
+		return []*net.MX{{Host: "mx." + name}}, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (f fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if _, err := f.LookupMX(ctx, host); err != nil {
+		return nil, err
+	}
+	return []string{"192.0.2.1"}, nil
+}
+
+// ids returns the IDs of each group's contacts, keyed by check.
+func ids(groups []Group) map[string][]string {
+	out := make(map[string][]string)
+	for _, g := range groups {
+		out[g.Check] = []string{}
+		for _, i := range g.Issues {
+			out[g.Check] = append(out[g.Check], i.Contact.ID)
+		}
+	}
+	return out
+}
+
+func TestAnalyze(t *testing.T) {
+	a, err := New(Options{DeadDomains: []string{"oldmail.test"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	groups := a.Analyze(context.Background(), contacts)
+	want := map[string][]string{
+		MissingPhone:   {"3"},
+		MalformedPhone: {"2", "4"},
+		NameCase:       {"2", "3"},
+		DeadDomain:     {"2", "4"},
+	}
+	if got := ids(groups); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	phones := groups[1]
+	if i := phones.Issues[0]; i.Value != "(555) 555.0102" || i.Suggestion != "555-555-0102" {
+		t.Errorf("unexpected phone issue %+v", i)
+	}
+	if fixable := phones.Fixable(); len(fixable) != 1 || fixable[0].Contact.ID != "2" {
+		t.Errorf("expected only Bob's phone fixable, got %+v", fixable)
+	}
+	if i := groups[2].Issues[1]; i.Value != "carol J Williams" || i.Suggestion != "Carol J Williams" {
+		t.Errorf("unexpected name issue %+v", i)
+	}
+}
+
+func TestAnalyze_Resolver(t *testing.T) {
+	a, _ := New(Options{
+		Checks:   []string{DeadDomain},
+		Resolver: fakeResolver{hosts: map[string]bool{"example.com": true}, failing: map[string]bool{"mail.oldmail.test": true}},
+	})
+	groups := a.Analyze(context.Background(), contacts)
+	want := map[string][]string{DeadDomain: {"2", "3"}}
+	if got := ids(groups); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	a, err := New(Options{Checks: []string{" name_case", "missing_phone", ""}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := a.Enabled(); !reflect.DeepEqual(got, []string{MissingPhone, NameCase}) {
+		t.Errorf("Enabled = %v", got)
+	}
+	if _, err := New(Options{Checks: []string{"spelling"}}); err == nil {
+		t.Error("expected an error for an unknown check")
+	}
+	if _, err := New(Options{DeadDomains: []string{"not a domain"}}); err == nil {
+		t.Error("expected an error for an invalid domain")
+	}
+	if got := (Analyzer{}).Enabled(); !reflect.DeepEqual(got, Checks) {
+		t.Errorf("expected the zero Analyzer to run every check, got %v", got)
+	}
+}
+
+func TestFix(t *testing.T) {
+	c, ok := Fix(contacts[1], NameCase)
+	if !ok || c.FirstName != "Bob" || c.LastName != "Smith" {
+		t.Errorf("unexpected name fix %+v", c)
+	}
+	if c, ok = Fix(contacts[1], MalformedPhone); !ok || c.Phone != "555-555-0102" {
+		t.Errorf("unexpected phone fix %+v", c)
+	}
+	for _, check := range []string{NameCase, MalformedPhone, MissingPhone, DeadDomain} {
+		if _, ok := Fix(contacts[0], check); ok {
+			t.Errorf("%s: expected nothing to fix for Alice", check)
+		}
+	}
+	if _, ok := Fix(contacts[3], MalformedPhone); ok {
+		t.Error("expected no fix for an implausible number")
+	}
+}
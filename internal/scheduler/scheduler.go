@@ -0,0 +1,122 @@
+// Package scheduler runs background jobs for the server's lifetime. It
+// currently delivers due follow-up reminders as in-app notifications.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// DefaultInterval is how often due reminders are checked for.
+const DefaultInterval = time.Minute
+
+// Deliverer turns due reminders into notifications. store.ReminderStore
+// implements it.
+type Deliverer interface {
+	DeliverDueReminders(ctx context.Context, now time.Time) ([]model.Notification, error)
+}
+
+// Scheduler periodically delivers due reminders. Delivery state lives in
+// the store, so reminders that fell due while the server was down are
+// delivered on the first check after it starts.
+type Scheduler struct {
+	store    Deliverer
+	interval time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Option configures a Scheduler.
+type Option func(*Scheduler)
+
+// WithInterval sets how often due reminders are checked for.
+func WithInterval(d time.Duration) Option {
+	return func(s *Scheduler) {
+		if d > 0 {
+			s.interval = d
+		}
+	}
+}
+
+// WithClock replaces the current time, for tests.
+func WithClock(now func() time.Time) Option {
+	return func(s *Scheduler) {
+		s.now = now
+	}
+}
+
+// New creates a Scheduler delivering reminders from store.
+func New(store Deliverer, opts ...Option) *Scheduler {
+	s := &Scheduler{store: store, interval: DefaultInterval, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start checks for due reminders immediately and then every interval,
+// until ctx is cancelled or Stop is called. Starting a running scheduler
+// does nothing.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done != nil {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.run(ctx, s.done)
+	slog.Info("scheduler started", "interval", s.interval)
+}
+
+// Stop stops the scheduler and waits for a check in progress to finish.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+	if done == nil {
+		return
+	}
+
+	cancel()
+	<-done
+	slog.Info("scheduler stopped")
+}
+
+func (s *Scheduler) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.Deliver(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Deliver delivers the reminders due now and returns how many were
+// delivered. Errors are logged; the next check retries.
+func (s *Scheduler) Deliver(ctx context.Context) int {
+	delivered, err := s.store.DeliverDueReminders(ctx, s.now())
+	if err != nil {
+		slog.Error("delivering reminders", "error", err)
+		return 0
+	}
+	for _, n := range delivered {
+		slog.Info("reminder delivered", "reminder", n.ReminderID, "contact", n.ContactID)
+	}
+	return len(delivered)
+}
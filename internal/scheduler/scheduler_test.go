@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/store"
+)
+
+func TestDeliver(t *testing.T) {
+	s := store.NewMemory()
+	ctx := context.Background()
+	c, _ := s.Create(ctx, model.Contact{FirstName: "Bob", Email: "bob@example.com"})
+
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	for _, due := range []time.Time{now.Add(-time.Hour), now, now.Add(time.Minute)} {
+		if _, err := s.AddReminder(ctx, model.Reminder{ContactID: c.ID, Note: "Follow up", Due: due}); err != nil {
+			t.Fatalf("AddReminder: %v", err)
+		}
+	}
+
+	sched := New(s, WithClock(func() time.Time { return now }))
+	if n := sched.Deliver(ctx); n != 2 {
+		t.Errorf("first check delivered %d, want 2", n)
+	}
+	if n := sched.Deliver(ctx); n != 0 {
+		t.Errorf("second check delivered %d, want 0", n)
+	}
+
+	now = now.Add(time.Minute)
+	if n := sched.Deliver(ctx); n != 1 {
+		t.Errorf("check after a minute delivered %d, want 1", n)
+	}
+	if unread, _ := s.UnreadNotifications(ctx); unread != 3 {
+		t.Errorf("unread = %d, want 3", unread)
+	}
+}
+
+// countingStore records how often reminders were checked for.
+type countingStore struct {
+	mu     sync.Mutex
+	checks int
+}
+
+func (c *countingStore) DeliverDueReminders(context.Context, time.Time) ([]model.Notification, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks++
+	return nil, nil
+}
+
+func (c *countingStore) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.checks
+}
+
+func TestStartStop(t *testing.T) {
+	cs := &countingStore{}
+	sched := New(cs, WithInterval(5*time.Millisecond))
+
+	sched.Stop() // stopping before starting is a no-op
+	sched.Start(context.Background())
+	sched.Start(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	for cs.count() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	sched.Stop()
+	stopped := cs.count()
+	if stopped < 3 {
+		t.Fatalf("expected repeated checks, got %d", stopped)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if cs.count() != stopped {
+		t.Error("scheduler kept running after Stop")
+	}
+}
+
+func TestStart_ContextCancel(t *testing.T) {
+	cs := &countingStore{}
+	sched := New(cs, WithInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sched.Start(ctx)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		sched.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after the context was cancelled")
+	}
+	if cs.count() != 1 {
+		t.Errorf("expected one immediate check, got %d", cs.count())
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 // Config holds server configuration.
@@ -10,6 +11,59 @@ type Config struct {
 	Host string
 	Port int
 	Seed bool
+
+	// Collation is the BCP 47 locale contact names are sorted by.
+	// CollationCase and CollationAccents make the order tell apart names
+	// that differ only in case or accents, which otherwise sort as equal.
+	Collation        string
+	CollationCase    bool
+	CollationAccents bool
+
+	// NameOrder is "western" or "family-first" and controls how names are
+	// displayed and how pasted full names are split.
+	NameOrder string
+
+	// EmailAllow and EmailDeny are comma-separated domains contact email
+	// addresses must, or must not, belong to. Subdomains match too.
+	EmailAllow string
+	EmailDeny  string
+
+	// QualityChecks are the comma-separated data-quality checks to run,
+	// all of them when empty. DeadDomains are comma-separated email
+	// domains known to no longer receive mail, and QualityDNS also reports
+	// domains that don't exist in DNS.
+	QualityChecks string
+	DeadDomains   string
+	QualityDNS    bool
+
+	// Fields is the path of a JSON file defining custom contact fields.
+	Fields string
+
+	// ReminderInterval is how often due reminders are delivered.
+	ReminderInterval time.Duration
+
+	// CalendarSecret is the unguessable name the iCalendar feed of
+	// contacts' dates is served under, at /calendar/<secret>.ics. The feed
+	// is disabled when it is empty.
+	CalendarSecret string
+
+	// BlobDir is the directory uploaded contact photos and attachments
+	// are stored in. When empty, a temporary directory is created and
+	// removed when the server stops, since the in-memory store forgets the
+	// contacts the files belong to. A directory set here is kept.
+	BlobDir string
+
+	// AttachmentMaxMB is the largest attachment accepted and
+	// AttachmentQuotaMB the total size of one contact's attachments, in
+	// megabytes.
+	AttachmentMaxMB   int
+	AttachmentQuotaMB int
+
+	// Dev serves templates and static files from disk, re-parses templates
+	// when they change and live-reloads open browsers.
+	Dev         bool
+	TemplateDir string
+	StaticDir   string
 }
 
 // DefaultConfig returns the default server configuration.
@@ -18,6 +72,17 @@ func DefaultConfig() Config {
 		Host: "",
 		Port: 8080,
 		Seed: true,
+
+		Collation: "en",
+		NameOrder: "western",
+
+		ReminderInterval: time.Minute,
+
+		AttachmentMaxMB:   25,
+		AttachmentQuotaMB: 100,
+
+		TemplateDir: "internal/tmpl/templates",
+		StaticDir:   "internal/handler/static",
 	}
 }
 
@@ -36,6 +101,66 @@ func FromEnv() Config {
 	if seed := os.Getenv("HTMXAPP_SEED"); seed == "false" || seed == "0" {
 		cfg.Seed = false
 	}
+	if collation := os.Getenv("HTMXAPP_COLLATION"); collation != "" {
+		cfg.Collation = collation
+	}
+	if c := os.Getenv("HTMXAPP_COLLATION_CASE"); c == "true" || c == "1" {
+		cfg.CollationCase = true
+	}
+	if a := os.Getenv("HTMXAPP_COLLATION_ACCENTS"); a == "true" || a == "1" {
+		cfg.CollationAccents = true
+	}
+	if order := os.Getenv("HTMXAPP_NAME_ORDER"); order != "" {
+		cfg.NameOrder = order
+	}
+	if allow := os.Getenv("HTMXAPP_EMAIL_ALLOW"); allow != "" {
+		cfg.EmailAllow = allow
+	}
+	if deny := os.Getenv("HTMXAPP_EMAIL_DENY"); deny != "" {
+		cfg.EmailDeny = deny
+	}
+	if checks := os.Getenv("HTMXAPP_QUALITY_CHECKS"); checks != "" {
+		cfg.QualityChecks = checks
+	}
+	if dead := os.Getenv("HTMXAPP_DEAD_DOMAINS"); dead != "" {
+		cfg.DeadDomains = dead
+	}
+	if dns := os.Getenv("HTMXAPP_QUALITY_DNS"); dns == "true" || dns == "1" {
+		cfg.QualityDNS = true
+	}
+	if fields := os.Getenv("HTMXAPP_FIELDS"); fields != "" {
+		cfg.Fields = fields
+	}
+	if interval := os.Getenv("HTMXAPP_REMINDER_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil && d > 0 {
+			cfg.ReminderInterval = d
+		}
+	}
+	if secret := os.Getenv("HTMXAPP_CALENDAR_SECRET"); secret != "" {
+		cfg.CalendarSecret = secret
+	}
+	if dir := os.Getenv("HTMXAPP_BLOB_DIR"); dir != "" {
+		cfg.BlobDir = dir
+	}
+	if mb := os.Getenv("HTMXAPP_ATTACHMENT_MAX_MB"); mb != "" {
+		if n, err := strconv.Atoi(mb); err == nil && n > 0 {
+			cfg.AttachmentMaxMB = n
+		}
+	}
+	if mb := os.Getenv("HTMXAPP_ATTACHMENT_QUOTA_MB"); mb != "" {
+		if n, err := strconv.Atoi(mb); err == nil && n > 0 {
+			cfg.AttachmentQuotaMB = n
+		}
+	}
+	if dev := os.Getenv("HTMXAPP_DEV"); dev == "true" || dev == "1" {
+		cfg.Dev = true
+	}
+	if dir := os.Getenv("HTMXAPP_TEMPLATE_DIR"); dir != "" {
+		cfg.TemplateDir = dir
+	}
+	if dir := os.Getenv("HTMXAPP_STATIC_DIR"); dir != "" {
+		cfg.StaticDir = dir
+	}
 
 	return cfg
 }
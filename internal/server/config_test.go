@@ -1,6 +1,9 @@
 package server
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestDefaultConfig(t *testing.T) {
 	cfg := DefaultConfig()
@@ -10,6 +13,9 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.Addr() != ":8080" {
 		t.Errorf("expected :8080, got %s", cfg.Addr())
 	}
+	if cfg.BlobDir != "" {
+		t.Errorf("expected a temporary blob directory, got %s", cfg.BlobDir)
+	}
 }
 
 func TestConfig_Addr(t *testing.T) {
@@ -23,6 +29,20 @@ func TestFromEnv(t *testing.T) {
 	t.Setenv("HTMXAPP_HOST", "0.0.0.0")
 	t.Setenv("HTMXAPP_PORT", "9090")
 	t.Setenv("HTMXAPP_SEED", "false")
+	t.Setenv("HTMXAPP_COLLATION", "sv")
+	t.Setenv("HTMXAPP_COLLATION_CASE", "true")
+	t.Setenv("HTMXAPP_COLLATION_ACCENTS", "1")
+	t.Setenv("HTMXAPP_NAME_ORDER", "family-first")
+	t.Setenv("HTMXAPP_EMAIL_DENY", "spam.test")
+	t.Setenv("HTMXAPP_FIELDS", "/etc/htmxapp/fields.json")
+	t.Setenv("HTMXAPP_REMINDER_INTERVAL", "30s")
+	t.Setenv("HTMXAPP_CALENDAR_SECRET", "s3cret")
+	t.Setenv("HTMXAPP_BLOB_DIR", "/var/lib/htmxapp/blobs")
+	t.Setenv("HTMXAPP_ATTACHMENT_MAX_MB", "5")
+	t.Setenv("HTMXAPP_ATTACHMENT_QUOTA_MB", "50")
+	t.Setenv("HTMXAPP_QUALITY_CHECKS", "name_case,dead_domain")
+	t.Setenv("HTMXAPP_DEAD_DOMAINS", "oldmail.test")
+	t.Setenv("HTMXAPP_QUALITY_DNS", "true")
 
 	cfg := FromEnv()
 	if cfg.Host != "0.0.0.0" {
@@ -34,4 +54,50 @@ func TestFromEnv(t *testing.T) {
 	if cfg.Seed {
 		t.Error("expected seed=false")
 	}
+	if cfg.Collation != "sv" {
+		t.Errorf("expected sv collation, got %s", cfg.Collation)
+	}
+	if !cfg.CollationCase || !cfg.CollationAccents {
+		t.Error("expected case- and accent-sensitive collation")
+	}
+	if cfg.NameOrder != "family-first" {
+		t.Errorf("expected family-first name order, got %s", cfg.NameOrder)
+	}
+	if cfg.EmailDeny != "spam.test" {
+		t.Errorf("expected spam.test deny list, got %s", cfg.EmailDeny)
+	}
+	if cfg.Fields != "/etc/htmxapp/fields.json" {
+		t.Errorf("expected fields path, got %s", cfg.Fields)
+	}
+	if cfg.ReminderInterval != 30*time.Second {
+		t.Errorf("expected 30s reminder interval, got %s", cfg.ReminderInterval)
+	}
+	if cfg.CalendarSecret != "s3cret" {
+		t.Errorf("expected calendar secret, got %s", cfg.CalendarSecret)
+	}
+	if cfg.BlobDir != "/var/lib/htmxapp/blobs" {
+		t.Errorf("expected blob directory, got %s", cfg.BlobDir)
+	}
+	if cfg.AttachmentMaxMB != 5 || cfg.AttachmentQuotaMB != 50 {
+		t.Errorf("expected 5 MB attachments within 50 MB, got %d and %d", cfg.AttachmentMaxMB, cfg.AttachmentQuotaMB)
+	}
+	if cfg.QualityChecks != "name_case,dead_domain" || cfg.DeadDomains != "oldmail.test" || !cfg.QualityDNS {
+		t.Errorf("unexpected data quality settings %q, %q, %v", cfg.QualityChecks, cfg.DeadDomains, cfg.QualityDNS)
+	}
+}
+
+func TestFromEnv_Dev(t *testing.T) {
+	t.Setenv("HTMXAPP_DEV", "1")
+	t.Setenv("HTMXAPP_TEMPLATE_DIR", "/src/templates")
+
+	cfg := FromEnv()
+	if !cfg.Dev {
+		t.Error("expected dev=true")
+	}
+	if cfg.TemplateDir != "/src/templates" {
+		t.Errorf("expected /src/templates, got %s", cfg.TemplateDir)
+	}
+	if cfg.StaticDir != DefaultConfig().StaticDir {
+		t.Errorf("expected default static dir, got %s", cfg.StaticDir)
+	}
 }
@@ -5,35 +5,138 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/devaloi/htmxapp/internal/blob"
+	"github.com/devaloi/htmxapp/internal/devreload"
 	"github.com/devaloi/htmxapp/internal/handler"
+	"github.com/devaloi/htmxapp/internal/model"
+	"github.com/devaloi/htmxapp/internal/quality"
+	"github.com/devaloi/htmxapp/internal/scheduler"
 	"github.com/devaloi/htmxapp/internal/store"
 	"github.com/devaloi/htmxapp/internal/tmpl"
 )
 
 // Run starts the HTTP server with graceful shutdown.
 func Run(cfg Config) error {
-	renderer, err := tmpl.New()
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	nameOrder, err := model.ParseNameOrder(cfg.NameOrder)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	emails, err := model.NewEmailPolicy(strings.Split(cfg.EmailAllow, ","), strings.Split(cfg.EmailDeny, ","))
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	qualityOpts := quality.Options{
+		Checks:      strings.Split(cfg.QualityChecks, ","),
+		DeadDomains: strings.Split(cfg.DeadDomains, ","),
+	}
+	if cfg.QualityDNS {
+		qualityOpts.Resolver = net.DefaultResolver
+	}
+	analyzer, err := quality.New(qualityOpts)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	var fields []model.FieldDef
+	if cfg.Fields != "" {
+		data, err := os.ReadFile(cfg.Fields)
+		if err != nil {
+			return fmt.Errorf("reading custom fields: %w", err)
+		}
+		if fields, err = model.ParseFieldDefs(data); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+
+	blobDir := cfg.BlobDir
+	if blobDir == "" {
+		if blobDir, err = os.MkdirTemp("", "htmxapp-blobs-*"); err != nil {
+			return fmt.Errorf("creating blob directory: %w", err)
+		}
+		defer os.RemoveAll(blobDir)
+	}
+	blobs, err := blob.NewFS(blobDir)
+	if err != nil {
+		return err
+	}
+
+	opts := []handler.Option{
+		handler.WithNameOrder(nameOrder),
+		handler.WithEmailPolicy(emails),
+		handler.WithQuality(analyzer),
+		handler.WithFields(fields),
+		handler.WithCalendarSecret(cfg.CalendarSecret),
+		handler.WithBlobs(blobs),
+		handler.WithAttachmentLimits(int64(cfg.AttachmentMaxMB)<<20, int64(cfg.AttachmentQuotaMB)<<20),
+	}
+	tmplOpts := tmpl.Options{NameOrder: nameOrder, Fields: fields}
+	if cfg.Dev {
+		tmplOpts.FS = os.DirFS(cfg.TemplateDir)
+		tmplOpts.LiveReload = true
+		opts = append(opts, handler.WithStaticFS(os.DirFS(cfg.StaticDir)))
+	}
+
+	renderer, err := tmpl.NewWithOptions(tmplOpts)
 	if err != nil {
 		return fmt.Errorf("initializing templates: %w", err)
 	}
 
-	memStore := store.NewMemory()
+	collation, err := store.NewCollation(store.CollationOptions{
+		Locale:          cfg.Collation,
+		CaseSensitive:   cfg.CollationCase,
+		AccentSensitive: cfg.CollationAccents,
+	})
+	if err != nil {
+		return fmt.Errorf("initializing collation: %w", err)
+	}
+
+	memStore := store.NewMemory(store.WithCollation(collation), store.WithFields(fields))
 	if cfg.Seed {
 		memStore.Seed()
 		slog.Info("seeded sample contacts")
 	}
 
-	h := handler.New(memStore, renderer)
+	sched := scheduler.New(memStore, scheduler.WithInterval(cfg.ReminderInterval))
+	sched.Start(ctx)
+	defer sched.Stop()
+
+	h := handler.New(memStore, renderer, opts...)
 	routes := h.Routes()
 
+	var reload *devreload.Broker
+	if cfg.Dev {
+		reload = devreload.NewBroker()
+		mux := http.NewServeMux()
+		mux.Handle("GET /_dev/reload", reload)
+		mux.Handle("/", routes)
+		routes = mux
+
+		go devreload.Watch(ctx, 500*time.Millisecond, []string{cfg.TemplateDir, cfg.StaticDir}, func() {
+			if err := renderer.Reload(); err != nil {
+				slog.Error("reloading templates", "error", err)
+				return
+			}
+			slog.Info("templates reloaded")
+			reload.Notify()
+		})
+		slog.Info("development mode", "templates", cfg.TemplateDir, "static", cfg.StaticDir)
+	}
+
 	// Apply middleware stack: RequestID → Recovery → Logging → routes
-	stack := handler.RequestIDMiddleware(handler.Recovery(handler.Logging(routes)))
+	stack := handler.RequestIDMiddleware(handler.Recovery(handler.Logging(handler.UserMiddleware(routes))))
 
 	srv := &http.Server{
 		Addr:         cfg.Addr(),
@@ -42,6 +145,9 @@ func Run(cfg Config) error {
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
+	if reload != nil {
+		srv.RegisterOnShutdown(reload.Close)
+	}
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -62,10 +168,10 @@ func Run(cfg Config) error {
 		return fmt.Errorf("server error: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := srv.Shutdown(shutdownCtx); err != nil {
 		return fmt.Errorf("shutdown: %w", err)
 	}
 
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// ListAttachments returns the contact's attachments, newest first.
+func (m *Memory) ListAttachments(_ context.Context, contactID string) ([]model.Attachment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []model.Attachment
+	for _, a := range m.attachments {
+		if a.ContactID == contactID {
+			result = append(result, a)
+		}
+	}
+	slices.SortFunc(result, func(a, b model.Attachment) int {
+		if lessID(b.ID, a.ID) {
+			return -1
+		}
+		return 1
+	})
+	return result, nil
+}
+
+// GetAttachment returns an attachment by ID.
+func (m *Memory) GetAttachment(_ context.Context, id string) (model.Attachment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	a, ok := m.attachments[id]
+	if !ok {
+		return model.Attachment{}, model.ErrNotFound
+	}
+	return a, nil
+}
+
+// AddAttachment records an attachment for an existing contact whose
+// attachments, with this one, fit in quota bytes.
+func (m *Memory) AddAttachment(_ context.Context, a model.Attachment, quota int64) (model.Attachment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data[a.ContactID]; !ok {
+		return model.Attachment{}, model.ErrNotFound
+	}
+	used := a.Size
+	for _, other := range m.attachments {
+		if other.ContactID == a.ContactID {
+			used += other.Size
+		}
+	}
+	if used > quota {
+		return model.Attachment{}, model.ErrQuotaExceeded
+	}
+
+	m.attachmentCounter++
+	a.ID = fmt.Sprintf("%d", m.attachmentCounter)
+	a.CreatedAt = time.Now()
+	m.attachments[a.ID] = a
+	return a, nil
+}
+
+// DeleteAttachment removes an attachment by ID.
+func (m *Memory) DeleteAttachment(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.attachments[id]; !ok {
+		return model.ErrNotFound
+	}
+	delete(m.attachments, id)
+	return nil
+}
+
+// AttachmentBlobInUse reports whether any attachment has the checksum,
+// including those of contacts in the trash.
+func (m *Memory) AttachmentBlobInUse(_ context.Context, checksum string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, a := range m.attachments {
+		if a.Checksum == checksum {
+			return true, nil
+		}
+	}
+	for _, e := range m.trash {
+		for _, a := range e.attachments {
+			if a.Checksum == checksum {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// removeAttachments deletes a deleted contact's attachments, returning
+// them.
+func (m *Memory) removeAttachments(contactID string) []model.Attachment {
+	var removed []model.Attachment
+	for id, a := range m.attachments {
+		if a.ContactID == contactID {
+			removed = append(removed, a)
+			delete(m.attachments, id)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestMemory_Attachments(t *testing.T) {
+	s := newTestStore(t) // Alice=1, Bob=2, Carol=3
+	ctx := context.Background()
+
+	nda, _ := s.AddAttachment(ctx, model.Attachment{ContactID: "1", Name: "nda.pdf", Size: 10, Checksum: "aa"}, 30)
+	card, _ := s.AddAttachment(ctx, model.Attachment{ContactID: "1", Name: "card.jpg", Size: 20, Checksum: "bb"}, 30)
+	bob, _ := s.AddAttachment(ctx, model.Attachment{ContactID: "2", Name: "copy.pdf", Size: 10, Checksum: "aa"}, 30)
+	if _, err := s.AddAttachment(ctx, model.Attachment{ContactID: "1", Name: "more.pdf", Size: 1, Checksum: "cc"}, 30); !errors.Is(err, model.ErrQuotaExceeded) {
+		t.Errorf("expected ErrQuotaExceeded over Alice's quota, got %v", err)
+	}
+	if _, err := s.AddAttachment(ctx, model.Attachment{ContactID: "999", Checksum: "cc"}, 30); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown contact, got %v", err)
+	}
+
+	list, _ := s.ListAttachments(ctx, "1")
+	if len(list) != 2 || list[0].ID != card.ID || list[1].ID != nda.ID {
+		t.Errorf("expected Alice's attachments newest first, got %+v", list)
+	}
+
+	if err := s.DeleteAttachment(ctx, nda.ID); err != nil {
+		t.Fatalf("DeleteAttachment: %v", err)
+	}
+	if err := s.DeleteAttachment(ctx, nda.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+	if inUse, _ := s.AttachmentBlobInUse(ctx, "aa"); !inUse {
+		t.Error("expected Bob's copy to keep the shared blob in use")
+	}
+
+	// Deleting a contact keeps its attachments' blobs in use until it is
+	// purged from the trash.
+	s.Delete(ctx, "2")
+	if _, err := s.GetAttachment(ctx, bob.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected Bob's attachment gone with him, got %v", err)
+	}
+	if inUse, _ := s.AttachmentBlobInUse(ctx, "aa"); !inUse {
+		t.Error("expected Bob's copy in the trash to keep the blob in use")
+	}
+	s.PurgeContact(ctx, "2")
+	if inUse, _ := s.AttachmentBlobInUse(ctx, "aa"); inUse {
+		t.Error("expected the blob unused once Bob is purged")
+	}
+	if _, err := s.GetAttachment(ctx, card.ID); err != nil {
+		t.Errorf("Alice's attachment went missing: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// DefaultCollationLocale is used when no collation locale is configured.
+const DefaultCollationLocale = "en"
+
+// CollationOptions configures how contact names are ordered.
+type CollationOptions struct {
+	// Locale is a BCP 47 tag selecting the tailoring of the Unicode
+	// Collation Algorithm, e.g. "sv" sorts Å after Z while "en" sorts it
+	// with A.
+	Locale string
+
+	// CaseSensitive and AccentSensitive turn off the default loose
+	// matching, where "émile" and "Emile" sort as equal.
+	CaseSensitive   bool
+	AccentSensitive bool
+}
+
+// Collation orders contacts by family name, then given name, using the
+// Unicode Collation Algorithm. Every store implementation and export sorts
+// through a Collation so the order is the same everywhere.
+type Collation struct {
+	locale string
+
+	mu       sync.Mutex // collate.Collator and its buffer aren't safe for concurrent use
+	collator *collate.Collator
+	buf      collate.Buffer
+}
+
+// NewCollation creates a Collation for opts.Locale, which defaults to
+// DefaultCollationLocale.
+func NewCollation(opts CollationOptions) (*Collation, error) {
+	locale := opts.Locale
+	if locale == "" {
+		locale = DefaultCollationLocale
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return nil, fmt.Errorf("collation locale %q: %w", locale, err)
+	}
+
+	var copts []collate.Option
+	if !opts.CaseSensitive {
+		copts = append(copts, collate.IgnoreCase)
+	}
+	if !opts.AccentSensitive {
+		copts = append(copts, collate.IgnoreDiacritics)
+	}
+	return &Collation{locale: tag.String(), collator: collate.New(tag, copts...)}, nil
+}
+
+// MustCollation is like NewCollation but panics on an invalid locale. It
+// is meant for package-level defaults with a known-good locale.
+func MustCollation(opts CollationOptions) *Collation {
+	c, err := NewCollation(opts)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Locale returns the collation's canonical BCP 47 locale tag.
+func (c *Collation) Locale() string {
+	return c.locale
+}
+
+// Compare returns -1, 0 or 1 depending on whether a sorts before, with or
+// after b.
+func (c *Collation) Compare(a, b string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.collator.CompareString(a, b)
+}
+
+// Sort orders contacts in place by their sort keys (family name, then
+// given name, preferring phonetic readings). Contacts whose names collate
+// equally keep a stable order by ID.
+func (c *Collation) Sort(contacts []model.Contact) {
+	type keyed struct {
+		last, first []byte
+	}
+	keys := make([]keyed, len(contacts))
+
+	c.mu.Lock()
+	for i, ct := range contacts {
+		family, given := ct.SortKey()
+		keys[i] = keyed{
+			last:  bytes.Clone(c.collator.KeyFromString(&c.buf, family)),
+			first: bytes.Clone(c.collator.KeyFromString(&c.buf, given)),
+		}
+		c.buf.Reset()
+	}
+	c.mu.Unlock()
+
+	idx := make([]int, len(contacts))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		ka, kb := keys[idx[a]], keys[idx[b]]
+		if cmp := bytes.Compare(ka.last, kb.last); cmp != 0 {
+			return cmp < 0
+		}
+		if cmp := bytes.Compare(ka.first, kb.first); cmp != 0 {
+			return cmp < 0
+		}
+		return lessID(contacts[idx[a]].ID, contacts[idx[b]].ID)
+	})
+
+	sorted := make([]model.Contact, len(contacts))
+	for i, j := range idx {
+		sorted[i] = contacts[j]
+	}
+	copy(contacts, sorted)
+}
+
+// lessID orders numeric IDs numerically and anything else lexically.
+func lessID(a, b string) bool {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return na < nb
+	}
+	return a < b
+}
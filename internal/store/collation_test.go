@@ -0,0 +1,112 @@
+package store
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func lastNames(contacts []model.Contact) []string {
+	names := make([]string, len(contacts))
+	for i, c := range contacts {
+		names[i] = c.LastName
+	}
+	return names
+}
+
+func TestCollation_Sort(t *testing.T) {
+	contacts := []model.Contact{
+		{ID: "1", LastName: "Zimmer"},
+		{ID: "2", LastName: "Ångström"},
+		{ID: "3", LastName: "de la Cruz"},
+		{ID: "4", LastName: "Davis"},
+		{ID: "5", LastName: "adams"},
+		{ID: "6", LastName: "Éclair"},
+		{ID: "7", LastName: "Baker"},
+	}
+
+	tests := []struct {
+		locale string
+		want   []string
+	}{
+		// Byte-wise order would put lowercase and accented names last.
+		{"en", []string{"adams", "Ångström", "Baker", "Davis", "de la Cruz", "Éclair", "Zimmer"}},
+		// Swedish sorts Å as its own letter after Z.
+		{"sv", []string{"adams", "Baker", "Davis", "de la Cruz", "Éclair", "Zimmer", "Ångström"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			c, err := NewCollation(CollationOptions{Locale: tt.locale})
+			if err != nil {
+				t.Fatalf("NewCollation: %v", err)
+			}
+			got := slices.Clone(contacts)
+			c.Sort(got)
+			if names := lastNames(got); !slices.Equal(names, tt.want) {
+				t.Errorf("got %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollation_SortByFirstNameThenID(t *testing.T) {
+	c := MustCollation(CollationOptions{})
+	contacts := []model.Contact{
+		{ID: "10", FirstName: "Émile", LastName: "Roux"},
+		{ID: "2", FirstName: "emile", LastName: "roux"},
+		{ID: "3", FirstName: "Anaïs", LastName: "Roux"},
+	}
+	c.Sort(contacts)
+
+	var ids []string
+	for _, ct := range contacts {
+		ids = append(ids, ct.ID)
+	}
+	if want := []string{"3", "2", "10"}; !slices.Equal(ids, want) {
+		t.Errorf("got IDs %v, want %v", ids, want)
+	}
+}
+
+func TestCollation_Compare(t *testing.T) {
+	insensitive := MustCollation(CollationOptions{})
+	if insensitive.Compare("Émile", "emile") != 0 {
+		t.Error("expected case- and accent-insensitive comparison by default")
+	}
+
+	strict := MustCollation(CollationOptions{CaseSensitive: true, AccentSensitive: true})
+	if strict.Compare("Émile", "emile") == 0 {
+		t.Error("expected sensitive comparison to distinguish Émile and emile")
+	}
+	if strict.Compare("Adams", "Baker") >= 0 {
+		t.Error("expected Adams before Baker")
+	}
+}
+
+func TestNewCollation_InvalidLocale(t *testing.T) {
+	if _, err := NewCollation(CollationOptions{Locale: "not a locale!"}); err == nil {
+		t.Error("expected error for invalid locale")
+	}
+	c := MustCollation(CollationOptions{Locale: "de-DE"})
+	if c.Locale() != "de-DE" {
+		t.Errorf("expected de-DE, got %s", c.Locale())
+	}
+}
+
+func TestCollation_SortUsesPhoneticAndNickname(t *testing.T) {
+	c := MustCollation(CollationOptions{Locale: "ja"})
+	contacts := []model.Contact{
+		{ID: "1", LastName: "渡辺", PhoneticLastName: "わたなべ"},
+		{ID: "2", LastName: "伊藤", PhoneticLastName: "いとう"},
+		{ID: "3", Nickname: "うさぎ"},
+	}
+	c.Sort(contacts)
+
+	var ids []string
+	for _, ct := range contacts {
+		ids = append(ids, ct.ID)
+	}
+	if want := []string{"2", "3", "1"}; !slices.Equal(ids, want) {
+		t.Errorf("got IDs %v, want %v", ids, want)
+	}
+}
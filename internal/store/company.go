@@ -0,0 +1,151 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// ListCompanies returns companies whose name or domain matches search,
+// ordered by name according to the store's collation.
+func (m *Memory) ListCompanies(_ context.Context, search string) ([]model.Company, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	q := strings.ToLower(strings.TrimSpace(search))
+	result := make([]model.Company, 0, len(m.companies))
+	for _, c := range m.companies {
+		if q == "" || strings.Contains(strings.ToLower(c.Name), q) || strings.Contains(strings.ToLower(c.Domain), q) {
+			result = append(result, m.withContacts(c))
+		}
+	}
+
+	slices.SortStableFunc(result, func(a, b model.Company) int {
+		if cmp := m.collation.Compare(a.Name, b.Name); cmp != 0 {
+			return cmp
+		}
+		if lessID(a.ID, b.ID) {
+			return -1
+		}
+		return 1
+	})
+	return result, nil
+}
+
+// GetCompany returns a company by ID.
+func (m *Memory) GetCompany(_ context.Context, id string) (model.Company, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, ok := m.companies[id]
+	if !ok {
+		return model.Company{}, model.ErrNotFound
+	}
+	return m.withContacts(c), nil
+}
+
+// CompanyByDomain returns the company owning domain or its closest parent.
+func (m *Memory) CompanyByDomain(_ context.Context, domain string) (model.Company, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	d := model.CompanyDomain(domain)
+	for d != "" {
+		if id, ok := m.domains[d]; ok {
+			return m.withContacts(m.companies[id]), nil
+		}
+		_, parent, ok := strings.Cut(d, ".")
+		if !ok || !strings.Contains(parent, ".") {
+			break
+		}
+		d = parent
+	}
+	return model.Company{}, model.ErrNotFound
+}
+
+// withContacts fills in the number of contacts linked to c.
+func (m *Memory) withContacts(c model.Company) model.Company {
+	c.Contacts = 0
+	for _, ct := range m.data {
+		if ct.CompanyID == c.ID {
+			c.Contacts++
+		}
+	}
+	return c
+}
+
+// CreateCompany adds a new company to the store.
+func (m *Memory) CreateCompany(_ context.Context, c model.Company) (model.Company, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	domain := model.CompanyDomain(c.Domain)
+	if _, ok := m.domains[domain]; domain != "" && ok {
+		return model.Company{}, model.ErrDuplicateDomain
+	}
+
+	m.companyCounter++
+	now := time.Now()
+	c.ID = fmt.Sprintf("%d", m.companyCounter)
+	c.Contacts = 0
+	c.CreatedAt = now
+	c.UpdatedAt = now
+
+	m.companies[c.ID] = c
+	if domain != "" {
+		m.domains[domain] = c.ID
+	}
+	return c, nil
+}
+
+// UpdateCompany modifies an existing company.
+func (m *Memory) UpdateCompany(_ context.Context, c model.Company) (model.Company, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.companies[c.ID]
+	if !ok {
+		return model.Company{}, model.ErrNotFound
+	}
+
+	domain := model.CompanyDomain(c.Domain)
+	if id, ok := m.domains[domain]; domain != "" && ok && id != c.ID {
+		return model.Company{}, model.ErrDuplicateDomain
+	}
+
+	delete(m.domains, model.CompanyDomain(existing.Domain))
+	c.CreatedAt = existing.CreatedAt
+	c.UpdatedAt = time.Now()
+
+	m.companies[c.ID] = c
+	if domain != "" {
+		m.domains[domain] = c.ID
+	}
+	return m.withContacts(c), nil
+}
+
+// DeleteCompany removes a company by ID. Its contacts are kept but no
+// longer linked to a company.
+func (m *Memory) DeleteCompany(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.companies[id]
+	if !ok {
+		return model.ErrNotFound
+	}
+
+	for cid, ct := range m.data {
+		if ct.CompanyID == id {
+			ct.CompanyID = ""
+			m.data[cid] = ct
+		}
+	}
+	delete(m.domains, model.CompanyDomain(c.Domain))
+	delete(m.companies, id)
+	return nil
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestMemory_Companies(t *testing.T) {
+	s := NewMemory()
+	ctx := context.Background()
+
+	acme, err := s.CreateCompany(ctx, model.Company{Name: "Acme", Domain: "Acme.com"})
+	if err != nil {
+		t.Fatalf("CreateCompany: %v", err)
+	}
+	if _, err := s.CreateCompany(ctx, model.Company{Name: "Acme Europe", Domain: "acme.com"}); !errors.Is(err, model.ErrDuplicateDomain) {
+		t.Errorf("expected ErrDuplicateDomain, got %v", err)
+	}
+	if _, err := s.CreateCompany(ctx, model.Company{Name: "Zeta"}); err != nil {
+		t.Fatalf("CreateCompany without domain: %v", err)
+	}
+	if _, err := s.CreateCompany(ctx, model.Company{Name: "Ángel Ltd"}); err != nil {
+		t.Fatalf("CreateCompany: %v", err)
+	}
+
+	companies, _ := s.ListCompanies(ctx, "")
+	var names []string
+	for _, c := range companies {
+		names = append(names, c.Name)
+	}
+	if want := []string{"Acme", "Ángel Ltd", "Zeta"}; !slices.Equal(names, want) {
+		t.Errorf("ListCompanies = %v, want %v", names, want)
+	}
+
+	for _, domain := range []string{"acme.com", "eu.sales.acme.com"} {
+		got, err := s.CompanyByDomain(ctx, domain)
+		if err != nil || got.ID != acme.ID {
+			t.Errorf("CompanyByDomain(%q) = %v, %v; want Acme", domain, got.Name, err)
+		}
+	}
+	if _, err := s.CompanyByDomain(ctx, "com"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for bare TLD, got %v", err)
+	}
+
+	acme.Domain = "acme.org"
+	if _, err := s.UpdateCompany(ctx, acme); err != nil {
+		t.Fatalf("UpdateCompany: %v", err)
+	}
+	if _, err := s.CompanyByDomain(ctx, "acme.com"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected old domain released, got %v", err)
+	}
+}
+
+func TestMemory_ContactCompany(t *testing.T) {
+	s := NewMemory()
+	ctx := context.Background()
+
+	acme, _ := s.CreateCompany(ctx, model.Company{Name: "Acme", Domain: "acme.com"})
+	jane, err := s.Create(ctx, model.Contact{FirstName: "Jane", Email: "jane@acme.com", CompanyID: acme.ID, Title: "CTO"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if jane.Company != "Acme" {
+		t.Errorf("expected company name filled in, got %q", jane.Company)
+	}
+	if _, err := s.Create(ctx, model.Contact{FirstName: "Bob", Email: "bob@example.com", CompanyID: "999"}); !errors.Is(err, model.ErrUnknownCompany) {
+		t.Errorf("expected ErrUnknownCompany, got %v", err)
+	}
+	_, _ = s.Create(ctx, model.Contact{FirstName: "Bob", Email: "bob@example.com"})
+
+	for _, q := range []Query{{Search: "acme"}, {Search: "cto"}, {CompanyID: acme.ID}} {
+		contacts, _ := s.List(ctx, q)
+		if len(contacts) != 1 || contacts[0].ID != jane.ID {
+			t.Errorf("List(%+v) = %v, want only Jane", q, contacts)
+		}
+	}
+	if c, _ := s.GetCompany(ctx, acme.ID); c.Contacts != 1 {
+		t.Errorf("expected 1 linked contact, got %d", c.Contacts)
+	}
+
+	if err := s.DeleteCompany(ctx, acme.ID); err != nil {
+		t.Fatalf("DeleteCompany: %v", err)
+	}
+	got, _ := s.Get(ctx, jane.ID)
+	if got.CompanyID != "" || got.Company != "" || got.Title != "CTO" {
+		t.Errorf("expected contact unlinked but title kept, got %+v", got)
+	}
+	if err := s.DeleteCompany(ctx, acme.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
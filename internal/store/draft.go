@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// SaveDraft records the draft, replacing its owner's previous one for the
+// contact.
+func (m *Memory) SaveDraft(_ context.Context, d model.Draft) (model.Draft, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if d.ContactID != "" {
+		if _, ok := m.data[d.ContactID]; !ok {
+			return model.Draft{}, model.ErrNotFound
+		}
+	}
+	d.Contact.ID = d.ContactID
+	d.SavedAt = time.Now()
+	if m.drafts[d.Owner] == nil {
+		m.drafts[d.Owner] = make(map[string]model.Draft)
+	}
+	m.drafts[d.Owner][d.ContactID] = d
+	return d, nil
+}
+
+// GetDraft returns owner's draft of a contact, or of a new contact for "".
+func (m *Memory) GetDraft(_ context.Context, owner, contactID string) (model.Draft, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	d, ok := m.drafts[owner][contactID]
+	if !ok {
+		return model.Draft{}, model.ErrNotFound
+	}
+	return d, nil
+}
+
+// DeleteDraft discards owner's draft of a contact, or of a new contact
+// for "".
+func (m *Memory) DeleteDraft(_ context.Context, owner, contactID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.drafts[owner], contactID)
+	return nil
+}
+
+// removeDrafts discards every user's draft of a deleted contact.
+func (m *Memory) removeDrafts(contactID string) {
+	for _, drafts := range m.drafts {
+		delete(drafts, contactID)
+	}
+}
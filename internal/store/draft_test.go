@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestMemory_Drafts(t *testing.T) {
+	s := newTestStore(t) // Alice=1, Bob=2, Carol=3
+	ctx := context.Background()
+
+	d, err := s.SaveDraft(ctx, model.Draft{ContactID: "2", Contact: model.Contact{FirstName: "Robert"}})
+	if err != nil {
+		t.Fatalf("SaveDraft: %v", err)
+	}
+	if d.SavedAt.IsZero() || d.Contact.ID != "2" {
+		t.Errorf("expected the draft stamped and tied to Bob, got %+v", d)
+	}
+	s.SaveDraft(ctx, model.Draft{ContactID: "2", Contact: model.Contact{FirstName: "Bobby"}})
+	s.SaveDraft(ctx, model.Draft{Contact: model.Contact{FirstName: "Dana"}})
+	if _, err := s.SaveDraft(ctx, model.Draft{ContactID: "999"}); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if d, _ := s.GetDraft(ctx, "", "2"); d.Contact.FirstName != "Bobby" {
+		t.Errorf("expected the latest draft, got %+v", d)
+	}
+	if d, _ := s.GetDraft(ctx, "", ""); d.Contact.FirstName != "Dana" {
+		t.Errorf("expected the new contact's draft, got %+v", d)
+	}
+
+	s.DeleteDraft(ctx, "", "")
+	if err := s.DeleteDraft(ctx, "", ""); err != nil {
+		t.Errorf("deleting a missing draft: %v", err)
+	}
+	if _, err := s.GetDraft(ctx, "", ""); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected the draft deleted, got %v", err)
+	}
+	s.Delete(ctx, "2")
+	if _, err := s.GetDraft(ctx, "", "2"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected deleting Bob to delete his draft, got %v", err)
+	}
+}
+
+func TestMemory_DraftsPerOwner(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	s.SaveDraft(ctx, model.Draft{Owner: "a", ContactID: "1", Contact: model.Contact{FirstName: "Alicia"}})
+	s.SaveDraft(ctx, model.Draft{Owner: "b", ContactID: "1", Contact: model.Contact{FirstName: "Ali"}})
+	if d, _ := s.GetDraft(ctx, "a", "1"); d.Contact.FirstName != "Alicia" {
+		t.Errorf("expected a's own draft, got %+v", d)
+	}
+	if _, err := s.GetDraft(ctx, "c", "1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected no draft for c, got %v", err)
+	}
+
+	s.DeleteDraft(ctx, "b", "1")
+	if _, err := s.GetDraft(ctx, "a", "1"); err != nil {
+		t.Errorf("expected b's discard to keep a's draft, got %v", err)
+	}
+	s.Delete(ctx, "1")
+	if _, err := s.GetDraft(ctx, "a", "1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected deleting Alice to delete every draft of her, got %v", err)
+	}
+}
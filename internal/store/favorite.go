@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// maxRecentViews is the number of recently viewed contacts kept per user.
+const maxRecentViews = 20
+
+// recentView is an entry of the recently viewed list.
+type recentView struct {
+	id string
+	at time.Time
+}
+
+// StarContact stars or unstars a contact for user.
+func (m *Memory) StarContact(_ context.Context, user, id string, starred bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data[id]; !ok {
+		return model.ErrNotFound
+	}
+	if !starred {
+		delete(m.starred[user], id)
+		return nil
+	}
+	if m.starred[user] == nil {
+		m.starred[user] = make(map[string]bool)
+	}
+	m.starred[user][id] = true
+	return nil
+}
+
+// IsStarred reports whether user starred the contact.
+func (m *Memory) IsStarred(_ context.Context, user, id string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.data[id]; !ok {
+		return false, model.ErrNotFound
+	}
+	return m.starred[user][id], nil
+}
+
+// RecordView moves the contact to the front of user's recently viewed
+// list.
+func (m *Memory) RecordView(_ context.Context, user, id string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data[id]; !ok {
+		return model.ErrNotFound
+	}
+	views := slices.DeleteFunc(m.views[user], func(v recentView) bool { return v.id == id })
+	views = slices.Insert(views, 0, recentView{id: id, at: at})
+	if len(views) > maxRecentViews {
+		views = views[:maxRecentViews]
+	}
+	m.views[user] = views
+	return nil
+}
+
+// RecentlyViewed returns up to limit contacts user viewed, most recently
+// viewed first.
+func (m *Memory) RecentlyViewed(_ context.Context, user string, limit int) ([]model.ContactView, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	views := m.views[user]
+	result := make([]model.ContactView, 0, min(limit, len(views)))
+	for _, v := range views[:min(limit, len(views))] {
+		result = append(result, model.ContactView{Contact: m.withDerived(m.data[v.id]), At: v.at})
+	}
+	return result, nil
+}
+
+// removeFavorites unstars a deleted contact and drops it from the recently
+// viewed lists, returning the users who had starred it.
+func (m *Memory) removeFavorites(id string) []string {
+	var starredBy []string
+	for user, starred := range m.starred {
+		if starred[id] {
+			starredBy = append(starredBy, user)
+			delete(starred, id)
+		}
+	}
+	for user, views := range m.views {
+		m.views[user] = slices.DeleteFunc(views, func(v recentView) bool { return v.id == id })
+	}
+	return starredBy
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestMemory_Starred(t *testing.T) {
+	s := newTestStore(t) // Alice=1, Bob=2, Carol=3
+	ctx := context.Background()
+
+	s.StarContact(ctx, "a", "3", true)
+	s.StarContact(ctx, "a", "1", true)
+	s.StarContact(ctx, "a", "1", true)
+	if err := s.StarContact(ctx, "a", "999", true); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	list, _ := s.List(ctx, Query{User: "a", Starred: true})
+	if len(list) != 2 || list[0].ID != "1" || list[1].ID != "3" || !list[0].Starred {
+		t.Fatalf("expected Alice and Carol starred, got %+v", list)
+	}
+	if starred, _ := s.IsStarred(ctx, "a", "2"); starred {
+		t.Error("Bob isn't starred")
+	}
+
+	// Updating a contact keeps its star.
+	c, _ := s.Get(ctx, "1")
+	c.Starred = false
+	s.Update(ctx, c)
+	if starred, _ := s.IsStarred(ctx, "a", "1"); !starred {
+		t.Error("expected Update to keep the star")
+	}
+
+	s.StarContact(ctx, "a", "1", false)
+	s.Delete(ctx, "3")
+	if list, _ := s.List(ctx, Query{User: "a", Starred: true}); len(list) != 0 {
+		t.Errorf("expected no starred contacts, got %+v", list)
+	}
+}
+
+func TestMemory_FavoritesPerUser(t *testing.T) {
+	s := newTestStore(t) // Alice=1, Bob=2, Carol=3
+	ctx := context.Background()
+	at := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	s.StarContact(ctx, "a", "1", true)
+	s.StarContact(ctx, "b", "2", true)
+	s.RecordView(ctx, "a", "3", at)
+
+	for user, want := range map[string]string{"a": "1", "b": "2"} {
+		list, _ := s.List(ctx, Query{User: user, Starred: true})
+		if len(list) != 1 || list[0].ID != want {
+			t.Errorf("user %s: expected only contact %s starred, got %+v", user, want, list)
+		}
+	}
+	all, _ := s.List(ctx, Query{User: "b"})
+	for _, c := range all {
+		if c.Starred != (c.ID == "2") {
+			t.Errorf("user b: contact %s Starred = %v", c.ID, c.Starred)
+		}
+	}
+	if views, _ := s.RecentlyViewed(ctx, "b", 10); len(views) != 0 {
+		t.Errorf("expected no views for b, got %+v", views)
+	}
+	if views, _ := s.RecentlyViewed(ctx, "a", 10); len(views) != 1 || views[0].Contact.ID != "3" {
+		t.Errorf("expected a's view of Carol, got %+v", views)
+	}
+
+	s.Delete(ctx, "1")
+	s.Delete(ctx, "3")
+	if list, _ := s.List(ctx, Query{User: "a", Starred: true}); len(list) != 0 {
+		t.Errorf("expected deleted contacts unstarred, got %+v", list)
+	}
+	if views, _ := s.RecentlyViewed(ctx, "a", 10); len(views) != 0 {
+		t.Errorf("expected deleted contacts dropped from views, got %+v", views)
+	}
+}
+
+func TestMemory_RecentlyViewed(t *testing.T) {
+	s := newTestStore(t) // Alice=1, Bob=2, Carol=3
+	ctx := context.Background()
+	at := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	for i, id := range []string{"1", "2", "3", "1"} {
+		if err := s.RecordView(ctx, "a", id, at.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("RecordView: %v", err)
+		}
+	}
+	views, _ := s.RecentlyViewed(ctx, "a", 2)
+	if len(views) != 2 || views[0].Contact.ID != "1" || views[1].Contact.ID != "3" || !views[0].At.Equal(at.Add(3*time.Minute)) {
+		t.Fatalf("expected Alice then Carol, got %+v", views)
+	}
+
+	s.Delete(ctx, "1")
+	if views, _ := s.RecentlyViewed(ctx, "a", 10); len(views) != 2 || views[0].Contact.ID != "3" {
+		t.Errorf("expected the deleted contact dropped, got %+v", views)
+	}
+
+	for i := range maxRecentViews + 5 {
+		c, _ := s.Create(ctx, model.Contact{FirstName: "Extra", Email: fmt.Sprintf("extra%d@example.com", i)})
+		s.RecordView(ctx, "a", c.ID, at)
+	}
+	if views, _ := s.RecentlyViewed(ctx, "a", 100); len(views) != maxRecentViews {
+		t.Errorf("expected %d views kept, got %d", maxRecentViews, len(views))
+	}
+}
@@ -0,0 +1,378 @@
+package store
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// Fields a filter Condition can test. Custom fields are FieldCustom plus
+// the field key, e.g. "custom.region", and are compared as text.
+const (
+	FieldName          = "name"
+	FieldEmail         = "email"
+	FieldEmailDomain   = "email_domain"
+	FieldPhone         = "phone"
+	FieldCompany       = "company"
+	FieldTitle         = "title"
+	FieldCreated       = "created"
+	FieldLastContacted = "last_contacted"
+	FieldCustom        = "custom."
+)
+
+// FilterFields are the built-in fields a Condition can test, in the order
+// the filter builder offers them.
+var FilterFields = []string{
+	FieldName, FieldEmail, FieldEmailDomain, FieldPhone, FieldCompany,
+	FieldTitle, FieldCreated, FieldLastContacted,
+}
+
+// Filter operators. Text fields take TextOperators and date fields
+// DateOperators; OpEmpty and OpNotEmpty take no value.
+const (
+	OpIs          = "is"
+	OpIsNot       = "is_not"
+	OpContains    = "contains"
+	OpNotContains = "not_contains"
+	OpEmpty       = "empty"
+	OpNotEmpty    = "not_empty"
+
+	// OpWithinDays matches dates in the last Value days, and
+	// OpNotWithinDays dates before that or no date at all.
+	OpWithinDays    = "within_days"
+	OpNotWithinDays = "not_within_days"
+
+	// OpBefore and OpAfter match dates before or after the day Value, in
+	// model.DateLayout, in UTC.
+	OpBefore = "before"
+	OpAfter  = "after"
+)
+
+var (
+	TextOperators = []string{OpContains, OpNotContains, OpIs, OpIsNot, OpEmpty, OpNotEmpty}
+	DateOperators = []string{OpWithinDays, OpNotWithinDays, OpBefore, OpAfter, OpEmpty, OpNotEmpty}
+)
+
+// Filter match modes, the values of the match parameters.
+const (
+	MatchAll = "all"
+	MatchAny = "any"
+)
+
+// MaxFilterConditions bounds the number of conditions ParseFilter reads.
+const MaxFilterConditions = 20
+
+// maxFilterDays bounds the day counts of OpWithinDays and OpNotWithinDays.
+const maxFilterDays = 36500
+
+// Condition tests one field of a contact, e.g. created within_days 30.
+type Condition struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// Group is a set of conditions a contact must all match, or with Any,
+// match at least one of.
+type Group struct {
+	Any        bool
+	Conditions []Condition
+}
+
+// Filter selects contacts by groups of conditions, which must all match,
+// or with Any, at least one.
+type Filter struct {
+	Any    bool
+	Groups []Group
+}
+
+// IsDateField reports whether field holds a date, taking DateOperators.
+func IsDateField(field string) bool {
+	return field == FieldCreated || field == FieldLastContacted
+}
+
+// Operators returns the operators field accepts.
+func Operators(field string) []string {
+	if IsDateField(field) {
+		return DateOperators
+	}
+	return TextOperators
+}
+
+// TakesValue reports whether op compares the field with a value.
+func TakesValue(op string) bool {
+	return op != OpEmpty && op != OpNotEmpty
+}
+
+// Complete reports whether the condition has been filled in: it has a
+// field and operator, and a value if the operator takes one.
+func (c Condition) Complete() bool {
+	return c.Field != "" && c.Op != "" && (c.Value != "" || !TakesValue(c.Op))
+}
+
+// Validate checks a complete condition against the built-in fields and
+// the custom field definitions, returning a validation code or "".
+func (c Condition) Validate(fields []model.FieldDef) string {
+	key, custom := strings.CutPrefix(c.Field, FieldCustom)
+	known := slices.Contains(FilterFields, c.Field)
+	if custom {
+		known = slices.ContainsFunc(fields, func(f model.FieldDef) bool { return f.Key == key })
+	}
+	if !known || !slices.Contains(Operators(c.Field), c.Op) {
+		return model.CodeInvalidOption
+	}
+	switch c.Op {
+	case OpWithinDays, OpNotWithinDays:
+		if n, err := strconv.Atoi(c.Value); err != nil || n <= 0 || n > maxFilterDays {
+			return model.CodeInvalidNumber
+		}
+	case OpBefore, OpAfter:
+		if _, err := time.Parse(model.DateLayout, c.Value); err != nil {
+			return model.CodeInvalidDate
+		}
+	}
+	return ""
+}
+
+// Usable returns f without the conditions that are incomplete or don't
+// validate against fields, and without the groups left empty.
+func (f Filter) Usable(fields []model.FieldDef) Filter {
+	usable := Filter{Any: f.Any}
+	for _, g := range f.Groups {
+		kept := Group{Any: g.Any}
+		for _, c := range g.Conditions {
+			if c.Complete() && c.Validate(fields) == "" {
+				kept.Conditions = append(kept.Conditions, c)
+			}
+		}
+		if len(kept.Conditions) > 0 {
+			usable.Groups = append(usable.Groups, kept)
+		}
+	}
+	return usable
+}
+
+// IsZero reports whether f has no conditions.
+func (f Filter) IsZero() bool {
+	for _, g := range f.Groups {
+		if len(g.Conditions) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsFilterParam reports whether the URL parameter named key belongs to a
+// filter.
+func IsFilterParam(key string) bool {
+	return strings.HasPrefix(key, "f.")
+}
+
+// ParseFilter reads a filter from URL parameters: "f.match" and
+// "f.<group>.match" are MatchAll (the default) or MatchAny, and
+// "f.<group>.<condition>.field", ".op" and ".value" describe a condition.
+// Groups and conditions are read in index order and the indexes needn't
+// be contiguous, so removing a condition doesn't renumber the rest.
+// Conditions without a field, and those past MaxFilterConditions, are
+// dropped.
+func ParseFilter(v url.Values) Filter {
+	type index struct{ group, cond int }
+	conds := make(map[index]*Condition)
+	anyGroup := make(map[int]bool)
+	for key, vals := range v {
+		rest, ok := strings.CutPrefix(key, "f.")
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		parts := strings.Split(rest, ".")
+		g, err := strconv.Atoi(parts[0])
+		if err != nil || g < 0 {
+			continue
+		}
+		switch {
+		case len(parts) == 2 && parts[1] == "match":
+			anyGroup[g] = vals[0] == MatchAny
+		case len(parts) == 3:
+			c, err := strconv.Atoi(parts[1])
+			if err != nil || c < 0 {
+				continue
+			}
+			cond := conds[index{g, c}]
+			if cond == nil {
+				cond = &Condition{}
+				conds[index{g, c}] = cond
+			}
+			val := strings.TrimSpace(vals[0])
+			switch parts[2] {
+			case "field":
+				cond.Field = val
+			case "op":
+				cond.Op = val
+			case "value":
+				cond.Value = val
+			}
+		}
+	}
+
+	indexes := make([]index, 0, len(conds))
+	for i, c := range conds {
+		if c.Field != "" {
+			indexes = append(indexes, i)
+		}
+	}
+	slices.SortFunc(indexes, func(a, b index) int {
+		if a.group != b.group {
+			return a.group - b.group
+		}
+		return a.cond - b.cond
+	})
+	if len(indexes) > MaxFilterConditions {
+		indexes = indexes[:MaxFilterConditions]
+	}
+
+	f := Filter{Any: v.Get("f.match") == MatchAny}
+	last := -1
+	for _, i := range indexes {
+		if i.group != last {
+			f.Groups = append(f.Groups, Group{Any: anyGroup[i.group]})
+			last = i.group
+		}
+		g := &f.Groups[len(f.Groups)-1]
+		g.Conditions = append(g.Conditions, *conds[i])
+	}
+	return f
+}
+
+// Values encodes f as the URL parameters ParseFilter reads, numbering
+// groups and conditions from 0.
+func (f Filter) Values() url.Values {
+	v := make(url.Values)
+	if f.IsZero() {
+		return v
+	}
+	if f.Any {
+		v.Set("f.match", MatchAny)
+	}
+	for gi, g := range f.Groups {
+		if g.Any {
+			v.Set(fmt.Sprintf("f.%d.match", gi), MatchAny)
+		}
+		for ci, c := range g.Conditions {
+			prefix := fmt.Sprintf("f.%d.%d.", gi, ci)
+			v.Set(prefix+"field", c.Field)
+			v.Set(prefix+"op", c.Op)
+			if c.Value != "" {
+				v.Set(prefix+"value", c.Value)
+			}
+		}
+	}
+	return v
+}
+
+// matchesFilter reports whether c passes a usable filter at now.
+func matchesFilter(c model.Contact, f Filter, now time.Time) bool {
+	for _, g := range f.Groups {
+		if matchesGroup(c, g, now) == f.Any {
+			return f.Any
+		}
+	}
+	return !f.Any || len(f.Groups) == 0
+}
+
+func matchesGroup(c model.Contact, g Group, now time.Time) bool {
+	for _, cond := range g.Conditions {
+		if matchesCondition(c, cond, now) == g.Any {
+			return g.Any
+		}
+	}
+	return !g.Any
+}
+
+func matchesCondition(c model.Contact, cond Condition, now time.Time) bool {
+	if IsDateField(cond.Field) {
+		t := c.CreatedAt
+		if cond.Field == FieldLastContacted {
+			t = c.LastContacted
+		}
+		return matchesDate(t, cond, now)
+	}
+	return matchesText(filterText(c, cond.Field), cond)
+}
+
+// filterText returns the text a condition on field tests.
+func filterText(c model.Contact, field string) string {
+	switch field {
+	case FieldName:
+		return c.FullName()
+	case FieldEmail:
+		return c.Email
+	case FieldEmailDomain:
+		if i := strings.LastIndex(c.Email, "@"); i >= 0 {
+			return c.Email[i+1:]
+		}
+		return ""
+	case FieldPhone:
+		return c.Phone
+	case FieldCompany:
+		return c.Company
+	case FieldTitle:
+		return c.Title
+	}
+	return c.Custom[strings.TrimPrefix(field, FieldCustom)]
+}
+
+func matchesText(s string, cond Condition) bool {
+	s = strings.TrimSpace(s)
+	equal := func() bool {
+		if cond.Field == FieldEmailDomain {
+			if a, b := model.CompanyDomain(s), model.CompanyDomain(cond.Value); a != "" && b != "" {
+				return a == b
+			}
+		}
+		return strings.EqualFold(s, cond.Value)
+	}
+	contains := func() bool {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(cond.Value))
+	}
+	switch cond.Op {
+	case OpIs:
+		return equal()
+	case OpIsNot:
+		return !equal()
+	case OpContains:
+		return contains()
+	case OpNotContains:
+		return !contains()
+	case OpEmpty:
+		return s == ""
+	case OpNotEmpty:
+		return s != ""
+	}
+	return false
+}
+
+func matchesDate(t time.Time, cond Condition, now time.Time) bool {
+	switch cond.Op {
+	case OpEmpty:
+		return t.IsZero()
+	case OpNotEmpty:
+		return !t.IsZero()
+	case OpWithinDays, OpNotWithinDays:
+		days, _ := strconv.Atoi(cond.Value)
+		within := !t.IsZero() && !t.Before(now.AddDate(0, 0, -days))
+		return within == (cond.Op == OpWithinDays)
+	}
+	day, _ := time.Parse(model.DateLayout, cond.Value)
+	if t.IsZero() {
+		return false
+	}
+	if cond.Op == OpBefore {
+		return t.Before(day)
+	}
+	return !t.Before(day.AddDate(0, 0, 1))
+}
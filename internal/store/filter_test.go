@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestParseFilter(t *testing.T) {
+	v, _ := url.ParseQuery("q=x&f.match=any" +
+		"&f.3.0.field=phone&f.3.0.op=empty" +
+		"&f.0.match=any&f.0.5.field=email_domain&f.0.5.op=is&f.0.5.value=+example.com+" +
+		"&f.0.2.field=created&f.0.2.op=within_days&f.0.2.value=30" +
+		"&f.0.9.op=is&f.x.0.field=name&f.1.0.bogus=1")
+	want := Filter{Any: true, Groups: []Group{
+		{Any: true, Conditions: []Condition{
+			{Field: FieldCreated, Op: OpWithinDays, Value: "30"},
+			{Field: FieldEmailDomain, Op: OpIs, Value: "example.com"},
+		}},
+		{Conditions: []Condition{{Field: FieldPhone, Op: OpEmpty}}},
+	}}
+	got := ParseFilter(v)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseFilter:\n got %+v\nwant %+v", got, want)
+	}
+
+	enc := got.Values()
+	if enc.Get("f.0.1.field") != FieldEmailDomain || enc.Get("f.1.0.op") != OpEmpty || enc.Has("f.1.match") {
+		t.Errorf("Values renumbered unexpectedly: %v", enc)
+	}
+	if again := ParseFilter(enc); !reflect.DeepEqual(again, want) {
+		t.Errorf("round trip:\n got %+v\nwant %+v", again, want)
+	}
+	if len(Filter{}.Values()) != 0 {
+		t.Error("expected no parameters for an empty filter")
+	}
+}
+
+func TestParseFilter_Limit(t *testing.T) {
+	v := make(url.Values)
+	for i := range MaxFilterConditions + 5 {
+		v.Set("f.0."+strconv.Itoa(i)+".field", FieldName)
+	}
+	if got := ParseFilter(v); len(got.Groups[0].Conditions) != MaxFilterConditions {
+		t.Errorf("expected %d conditions, got %d", MaxFilterConditions, len(got.Groups[0].Conditions))
+	}
+}
+
+func TestCondition_Validate(t *testing.T) {
+	fields := []model.FieldDef{{Key: "region", Label: "Region", Type: model.FieldText}}
+	tests := []struct {
+		cond Condition
+		want string
+	}{
+		{Condition{FieldName, OpContains, "ann"}, ""},
+		{Condition{"custom.region", OpIs, "EMEA"}, ""},
+		{Condition{"custom.missing", OpIs, "x"}, model.CodeInvalidOption},
+		{Condition{"birthday", OpIs, "x"}, model.CodeInvalidOption},
+		{Condition{FieldName, OpWithinDays, "3"}, model.CodeInvalidOption},
+		{Condition{FieldCreated, OpContains, "3"}, model.CodeInvalidOption},
+		{Condition{FieldCreated, OpWithinDays, "30"}, ""},
+		{Condition{FieldCreated, OpWithinDays, "-1"}, model.CodeInvalidNumber},
+		{Condition{FieldCreated, OpWithinDays, "soon"}, model.CodeInvalidNumber},
+		{Condition{FieldLastContacted, OpBefore, "2026-02-30"}, model.CodeInvalidDate},
+		{Condition{FieldLastContacted, OpAfter, "2026-02-28"}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.cond.Validate(fields); got != tt.want {
+			t.Errorf("Validate(%+v) = %q, want %q", tt.cond, got, tt.want)
+		}
+	}
+
+	if (Condition{Field: FieldName, Op: OpContains}).Complete() {
+		t.Error("a condition without a value is incomplete")
+	}
+	if !(Condition{Field: FieldPhone, Op: OpEmpty}).Complete() {
+		t.Error("empty takes no value")
+	}
+}
+
+func TestMemory_ListFilter(t *testing.T) {
+	s := newTestStore(t) // Alice=1, Bob=2, Carol=3
+	ctx := context.Background()
+
+	bob := s.data["2"]
+	bob.Email = "bob@Beispiel.example"
+	bob.CreatedAt = time.Now().AddDate(0, 0, -60)
+	s.data["2"] = bob
+	s.LogInteraction(ctx, model.Interaction{Type: "call", At: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC), ContactIDs: []string{"1"}})
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"no phone", Filter{Groups: []Group{{Conditions: []Condition{{FieldPhone, OpEmpty, ""}}}}}, []string{"3"}},
+		{"domain", Filter{Groups: []Group{{Conditions: []Condition{{FieldEmailDomain, OpIs, "EXAMPLE.com"}}}}}, []string{"1", "3"}},
+		{"created recently", Filter{Groups: []Group{{Conditions: []Condition{{FieldCreated, OpWithinDays, "30"}}}}}, []string{"1", "3"}},
+		{"created before", Filter{Groups: []Group{{Conditions: []Condition{{FieldCreated, OpNotWithinDays, "30"}}}}}, []string{"2"}},
+		{"contacted after", Filter{Groups: []Group{{Conditions: []Condition{{FieldLastContacted, OpAfter, "2026-03-09"}}}}}, []string{"1"}},
+		{"contacted before the day", Filter{Groups: []Group{{Conditions: []Condition{{FieldLastContacted, OpBefore, "2026-03-10"}}}}}, nil},
+		{"never contacted", Filter{Groups: []Group{{Conditions: []Condition{{FieldLastContacted, OpEmpty, ""}}}}}, []string{"2", "3"}},
+		{"all of", Filter{Groups: []Group{{Conditions: []Condition{
+			{FieldCreated, OpWithinDays, "30"}, {FieldPhone, OpNotEmpty, ""},
+		}}}}, []string{"1"}},
+		{"any of", Filter{Groups: []Group{{Any: true, Conditions: []Condition{
+			{FieldName, OpContains, "smi"}, {FieldPhone, OpEmpty, ""},
+		}}}}, []string{"2", "3"}},
+		{"groups any", Filter{Any: true, Groups: []Group{
+			{Conditions: []Condition{{FieldName, OpIs, "alice johnson"}}},
+			{Conditions: []Condition{{FieldEmail, OpNotContains, "example.com"}}},
+		}}, []string{"1", "2"}},
+		{"invalid ignored", Filter{Any: true, Groups: []Group{
+			{Conditions: []Condition{{FieldPhone, OpEmpty, ""}}},
+			{Conditions: []Condition{{FieldCreated, OpWithinDays, "soon"}, {FieldName, OpIs, ""}}},
+		}}, []string{"3"}},
+	}
+	for _, tt := range tests {
+		list, err := s.List(ctx, Query{Filter: tt.filter})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var ids []string
+		for _, c := range list {
+			ids = append(ids, c.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+}
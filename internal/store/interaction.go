@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// ListInteractions returns the contact's interactions, newest first.
+func (m *Memory) ListInteractions(_ context.Context, contactID string) ([]model.Interaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []model.Interaction
+	for _, i := range m.interactions {
+		if i.Involves(contactID) {
+			i.ContactIDs = slices.Clone(i.ContactIDs)
+			result = append(result, i)
+		}
+	}
+	slices.SortFunc(result, func(a, b model.Interaction) int {
+		if cmp := b.At.Compare(a.At); cmp != 0 {
+			return cmp
+		}
+		if lessID(b.ID, a.ID) {
+			return -1
+		}
+		return 1
+	})
+	return result, nil
+}
+
+// LogInteraction records an interaction with existing contacts. Duplicate
+// participants are recorded once.
+func (m *Memory) LogInteraction(_ context.Context, i model.Interaction) (model.Interaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var ids []string
+	for _, id := range i.ContactIDs {
+		if _, ok := m.data[id]; !ok {
+			return model.Interaction{}, model.ErrNotFound
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	m.interactionCounter++
+	i.ID = fmt.Sprintf("%d", m.interactionCounter)
+	i.ContactIDs = ids
+	i.CreatedAt = time.Now()
+	m.interactions[i.ID] = i
+	m.updateLastContacted(ids...)
+
+	i.ContactIDs = slices.Clone(ids)
+	return i, nil
+}
+
+// DeleteInteraction removes an interaction by ID.
+func (m *Memory) DeleteInteraction(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i, ok := m.interactions[id]
+	if !ok {
+		return model.ErrNotFound
+	}
+	delete(m.interactions, id)
+	m.updateLastContacted(i.ContactIDs...)
+	return nil
+}
+
+// removeParticipant drops a deleted contact from its interactions,
+// deleting those left without participants. It returns the interactions
+// as they were.
+func (m *Memory) removeParticipant(contactID string) []model.Interaction {
+	var removed []model.Interaction
+	for id, i := range m.interactions {
+		if !i.Involves(contactID) {
+			continue
+		}
+		removed = append(removed, i)
+		i.ContactIDs = slices.DeleteFunc(slices.Clone(i.ContactIDs), func(c string) bool { return c == contactID })
+		if len(i.ContactIDs) == 0 {
+			delete(m.interactions, id)
+			continue
+		}
+		m.interactions[id] = i
+	}
+	delete(m.lastContacted, contactID)
+	return removed
+}
+
+// updateLastContacted recomputes the latest interaction time of each
+// contact.
+func (m *Memory) updateLastContacted(contactIDs ...string) {
+	for _, id := range contactIDs {
+		var last time.Time
+		for _, i := range m.interactions {
+			if i.At.After(last) && i.Involves(id) {
+				last = i.At
+			}
+		}
+		if last.IsZero() {
+			delete(m.lastContacted, id)
+		} else {
+			m.lastContacted[id] = last
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestMemory_Interactions(t *testing.T) {
+	s := newTestStore(t) // Alice=1, Bob=2, Carol=3
+	ctx := context.Background()
+	now := time.Now()
+
+	call, err := s.LogInteraction(ctx, model.Interaction{Type: "call", At: now.Add(-time.Hour), ContactIDs: []string{"1", "2", "1"}})
+	if err != nil {
+		t.Fatalf("LogInteraction: %v", err)
+	}
+	if len(call.ContactIDs) != 2 {
+		t.Errorf("expected duplicate participant dropped, got %v", call.ContactIDs)
+	}
+	if _, err := s.LogInteraction(ctx, model.Interaction{Type: "email", At: now.Add(-48 * time.Hour), ContactIDs: []string{"1"}}); err != nil {
+		t.Fatalf("LogInteraction: %v", err)
+	}
+	if _, err := s.LogInteraction(ctx, model.Interaction{Type: "call", At: now, ContactIDs: []string{"1", "999"}}); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown participant, got %v", err)
+	}
+
+	list, _ := s.ListInteractions(ctx, "1")
+	if len(list) != 2 || list[0].Type != "call" || list[1].Type != "email" {
+		t.Errorf("expected call then email, got %+v", list)
+	}
+
+	alice, _ := s.Get(ctx, "1")
+	if !alice.LastContacted.Equal(now.Add(-time.Hour)) {
+		t.Errorf("LastContacted = %v, want %v", alice.LastContacted, now.Add(-time.Hour))
+	}
+
+	if err := s.DeleteInteraction(ctx, call.ID); err != nil {
+		t.Fatalf("DeleteInteraction: %v", err)
+	}
+	alice, _ = s.Get(ctx, "1")
+	bob, _ := s.Get(ctx, "2")
+	if !alice.LastContacted.Equal(now.Add(-48*time.Hour)) || !bob.LastContacted.IsZero() {
+		t.Errorf("LastContacted not recomputed: alice %v, bob %v", alice.LastContacted, bob.LastContacted)
+	}
+}
+
+func TestMemory_DeleteContactInteractions(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	shared, _ := s.LogInteraction(ctx, model.Interaction{Type: "meeting", At: time.Now(), ContactIDs: []string{"1", "2"}})
+	alone, _ := s.LogInteraction(ctx, model.Interaction{Type: "note", At: time.Now(), ContactIDs: []string{"1"}, Body: "x"})
+
+	if err := s.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	list, _ := s.ListInteractions(ctx, "2")
+	if len(list) != 1 || list[0].ID != shared.ID || len(list[0].ContactIDs) != 1 {
+		t.Errorf("expected shared interaction kept without Alice, got %+v", list)
+	}
+	if err := s.DeleteInteraction(ctx, alone.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected interaction without participants deleted, got %v", err)
+	}
+}
+
+func TestMemory_ListContacted(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	_, _ = s.LogInteraction(ctx, model.Interaction{Type: "call", At: now.AddDate(0, 0, -2), ContactIDs: []string{"1"}})
+	_, _ = s.LogInteraction(ctx, model.Interaction{Type: "call", At: now.AddDate(0, 0, -60), ContactIDs: []string{"2"}})
+
+	tests := []struct {
+		name string
+		q    Query
+		want []string
+	}{
+		{"since", Query{ContactedSince: now.AddDate(0, 0, -30)}, []string{"Alice"}},
+		{"not since", Query{NotContactedSince: now.AddDate(0, 0, -30)}, []string{"Bob", "Carol"}},
+		{"never", Query{NeverContacted: true}, []string{"Carol"}},
+		{"sort", Query{Sort: SortLastContacted}, []string{"Bob", "Alice", "Carol"}},
+		{"sort desc", Query{Sort: SortLastContacted, Desc: true}, []string{"Alice", "Bob", "Carol"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := s.List(ctx, tt.q)
+			var names []string
+			for _, c := range got {
+				names = append(names, c.FirstName)
+			}
+			if len(names) != len(tt.want) {
+				t.Fatalf("List = %v, want %v", names, tt.want)
+			}
+			for i := range names {
+				if names[i] != tt.want[i] {
+					t.Fatalf("List = %v, want %v", names, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -3,7 +3,8 @@ package store
 import (
 	"context"
 	"fmt"
-	"sort"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -11,20 +12,93 @@ import (
 	"github.com/devaloi/htmxapp/internal/model"
 )
 
-// Memory is a thread-safe in-memory contact store.
+// Memory is a thread-safe in-memory contact and company store.
 type Memory struct {
-	mu      sync.RWMutex
-	data    map[string]model.Contact
-	emails  map[string]string // email -> id for uniqueness
-	counter int
+	mu        sync.RWMutex
+	data      map[string]model.Contact
+	emails    map[string]string // canonical email -> id for uniqueness
+	counter   int
+	collation *Collation
+	fields    []model.FieldDef
+
+	companies      map[string]model.Company
+	domains        map[string]string // punycode domain -> company id
+	companyCounter int
+
+	relationships map[string]model.Relationship
+	relCounter    int
+
+	interactions       map[string]model.Interaction
+	interactionCounter int
+	lastContacted      map[string]time.Time // contact id -> latest interaction
+
+	reminders           map[string]model.Reminder
+	reminderCounter     int
+	notifications       map[string]model.Notification
+	notificationCounter int
+
+	attachments       map[string]model.Attachment
+	attachmentCounter int
+
+	notes       map[string]model.Note
+	noteCounter int
+
+	searches      map[string]model.SavedSearch
+	searchCounter int
+
+	// starred, views and drafts are kept per user ID.
+	starred map[string]map[string]bool
+	views   map[string][]recentView           // most recent first
+	drafts  map[string]map[string]model.Draft // contact id, "" for a new contact -> draft
+
+	trash map[string]trashEntry // deleted contact id -> entry
+}
+
+// MemoryOption configures a Memory store.
+type MemoryOption func(*Memory)
+
+// WithCollation sets the collation List sorts by.
+func WithCollation(c *Collation) MemoryOption {
+	return func(m *Memory) {
+		m.collation = c
+	}
+}
+
+// WithFields sets the custom field definitions List searches and sorts by.
+func WithFields(defs []model.FieldDef) MemoryOption {
+	return func(m *Memory) {
+		m.fields = defs
+	}
 }
 
 // NewMemory creates a new in-memory store.
-func NewMemory() *Memory {
-	return &Memory{
-		data:   make(map[string]model.Contact),
-		emails: make(map[string]string),
+func NewMemory(opts ...MemoryOption) *Memory {
+	m := &Memory{
+		data:      make(map[string]model.Contact),
+		emails:    make(map[string]string),
+		companies: make(map[string]model.Company),
+		domains:   make(map[string]string),
+
+		relationships: make(map[string]model.Relationship),
+		interactions:  make(map[string]model.Interaction),
+		lastContacted: make(map[string]time.Time),
+		reminders:     make(map[string]model.Reminder),
+		notifications: make(map[string]model.Notification),
+		attachments:   make(map[string]model.Attachment),
+		notes:         make(map[string]model.Note),
+		searches:      make(map[string]model.SavedSearch),
+		starred:       make(map[string]map[string]bool),
+		views:         make(map[string][]recentView),
+		drafts:        make(map[string]map[string]model.Draft),
+		trash:         make(map[string]trashEntry),
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.collation == nil {
+		m.collation = MustCollation(CollationOptions{})
+	}
+	return m
 }
 
 func (m *Memory) nextID() string {
@@ -32,32 +106,88 @@ func (m *Memory) nextID() string {
 	return fmt.Sprintf("%d", m.counter)
 }
 
-// List returns contacts matching the search query, sorted by last name.
-func (m *Memory) List(_ context.Context, search string) ([]model.Contact, error) {
+// List returns contacts matching q's search and filters in q.Sort order. Name order
+// follows the store's collation and breaks ties for the other keys.
+func (m *Memory) List(_ context.Context, q Query) ([]model.Contact, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	q := strings.ToLower(strings.TrimSpace(search))
+	result := m.matching(q)
+	m.collation.Sort(result)
+	m.sortBy(result, q.Sort, q.Desc)
+	return result, nil
+}
+
+// CountMatching returns how many contacts match q, skipping List's sort.
+func (m *Memory) CountMatching(_ context.Context, q Query) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.matching(q)), nil
+}
+
+// matching returns the contacts matching q, unsorted. The caller holds
+// m.mu.
+func (m *Memory) matching(q Query) []model.Contact {
+	search := strings.ToLower(strings.TrimSpace(q.Search))
 	result := make([]model.Contact, 0, len(m.data))
+	var noteHits map[string]string
+	if search != "" {
+		noteHits = m.notesMatching(search)
+	}
+	filter, now := q.Filter.Usable(m.fields), time.Now()
 
 	for _, c := range m.data {
-		if q == "" || m.matches(c, q) {
+		if q.CompanyID != "" && c.CompanyID != q.CompanyID {
+			continue
+		}
+		if q.Starred && !m.starred[q.User][c.ID] {
+			continue
+		}
+		c = m.withDerived(c)
+		c.Starred = m.starred[q.User][c.ID]
+		if !contacted(c, q) || !matchesFilter(c, filter, now) {
+			continue
+		}
+		if search == "" || m.matches(c, search) {
+			result = append(result, c)
+		} else if body, ok := noteHits[c.ID]; ok {
+			c.NoteMatch = model.Excerpt(body, search, noteExcerptWidth)
 			result = append(result, c)
 		}
 	}
+	return result
+}
 
-	sort.Slice(result, func(i, j int) bool {
-		if result[i].LastName == result[j].LastName {
-			return result[i].FirstName < result[j].FirstName
-		}
-		return result[i].LastName < result[j].LastName
-	})
+// noteExcerptWidth is the length of the note excerpts List shows for
+// contacts found through their notes, in characters.
+const noteExcerptWidth = 80
 
-	return result, nil
+// contacted reports whether c passes q's last-contacted filters.
+func contacted(c model.Contact, q Query) bool {
+	last := c.LastContacted
+	switch {
+	case q.NeverContacted && !last.IsZero():
+		return false
+	case !q.ContactedSince.IsZero() && last.Before(q.ContactedSince):
+		return false
+	case !q.NotContactedSince.IsZero() && !last.Before(q.NotContactedSince):
+		return false
+	}
+	return true
 }
 
 func (m *Memory) matches(c model.Contact, q string) bool {
-	fields := []string{c.FirstName, c.LastName, c.Email, c.Phone}
+	fields := []string{
+		c.FullName(), c.DisplayName(model.NameOrderFamilyFirst), c.Nickname,
+		c.PhoneticFirstName, c.PhoneticLastName, c.Email, c.Phone,
+		c.Company, c.Title,
+	}
+	for _, f := range m.fields {
+		if f.Searchable() {
+			fields = append(fields, c.Custom[f.Key])
+		}
+	}
 	for _, f := range fields {
 		if strings.Contains(strings.ToLower(f), q) {
 			return true
@@ -66,6 +196,62 @@ func (m *Memory) matches(c model.Contact, q string) bool {
 	return false
 }
 
+// sortBy re-sorts name-ordered contacts by key, keeping contacts without
+// a value last.
+func (m *Memory) sortBy(contacts []model.Contact, key string, desc bool) {
+	var value func(model.Contact) string
+	compare := m.collation.Compare
+	switch {
+	case key == SortEmail:
+		value = func(c model.Contact) string { return c.Email }
+	case key == SortLastContacted:
+		value = func(c model.Contact) string {
+			if c.LastContacted.IsZero() {
+				return ""
+			}
+			return c.LastContacted.UTC().Format(sortableTime)
+		}
+		compare = strings.Compare
+	case strings.HasPrefix(key, SortCustom):
+		i := slices.IndexFunc(m.fields, func(f model.FieldDef) bool {
+			return SortCustom+f.Key == key
+		})
+		if i < 0 {
+			break
+		}
+		f := m.fields[i]
+		value = func(c model.Contact) string { return c.Custom[f.Key] }
+		if !f.Collated() {
+			compare = f.Compare
+		}
+	}
+
+	if value == nil {
+		if desc {
+			slices.Reverse(contacts)
+		}
+		return
+	}
+	slices.SortStableFunc(contacts, func(a, b model.Contact) int {
+		va, vb := value(a), value(b)
+		switch {
+		case va == "" && vb == "":
+			return 0
+		case va == "":
+			return 1
+		case vb == "":
+			return -1
+		}
+		if desc {
+			return compare(vb, va)
+		}
+		return compare(va, vb)
+	})
+}
+
+// sortableTime formats times so that they sort lexically.
+const sortableTime = "20060102150405.000000000"
+
 // Get returns a contact by ID.
 func (m *Memory) Get(_ context.Context, id string) (model.Contact, error) {
 	m.mu.RLock()
@@ -75,7 +261,15 @@ func (m *Memory) Get(_ context.Context, id string) (model.Contact, error) {
 	if !ok {
 		return model.Contact{}, model.ErrNotFound
 	}
-	return c, nil
+	return m.withDerived(c), nil
+}
+
+// withDerived fills in the name of the contact's company and when they
+// were last contacted.
+func (m *Memory) withDerived(c model.Contact) model.Contact {
+	c.Company = m.companies[c.CompanyID].Name
+	c.LastContacted = m.lastContacted[c.ID]
+	return c
 }
 
 // Create adds a new contact to the store.
@@ -83,21 +277,30 @@ func (m *Memory) Create(_ context.Context, c model.Contact) (model.Contact, erro
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	email := strings.ToLower(c.Email)
+	email := model.CanonicalEmail(c.Email)
 	if existingID, ok := m.emails[email]; ok {
 		if existingID != c.ID {
 			return model.Contact{}, model.ErrDuplicateEmail
 		}
 	}
+	if _, ok := m.companies[c.CompanyID]; c.CompanyID != "" && !ok {
+		return model.Contact{}, model.ErrUnknownCompany
+	}
 
 	now := time.Now()
 	c.ID = m.nextID()
+	c.Custom = maps.Clone(c.Custom)
+	c.Dates = slices.Clone(c.Dates)
+	c.Company = ""
+	c.LastContacted = time.Time{}
+	c.NoteMatch = ""
+	c.Starred = false
 	c.CreatedAt = now
 	c.UpdatedAt = now
 
 	m.data[c.ID] = c
 	m.emails[email] = c.ID
-	return c, nil
+	return m.withDerived(c), nil
 }
 
 // Update modifies an existing contact.
@@ -110,23 +313,34 @@ func (m *Memory) Update(_ context.Context, c model.Contact) (model.Contact, erro
 		return model.Contact{}, model.ErrNotFound
 	}
 
-	email := strings.ToLower(c.Email)
+	email := model.CanonicalEmail(c.Email)
 	if existingID, ok := m.emails[email]; ok && existingID != c.ID {
 		return model.Contact{}, model.ErrDuplicateEmail
 	}
+	if _, ok := m.companies[c.CompanyID]; c.CompanyID != "" && !ok {
+		return model.Contact{}, model.ErrUnknownCompany
+	}
 
 	// Remove old email mapping
-	delete(m.emails, strings.ToLower(existing.Email))
+	delete(m.emails, model.CanonicalEmail(existing.Email))
 
 	c.CreatedAt = existing.CreatedAt
 	c.UpdatedAt = time.Now()
+	c.Custom = maps.Clone(c.Custom)
+	c.Dates = slices.Clone(c.Dates)
+	c.Company = ""
+	c.LastContacted = time.Time{}
+	c.NoteMatch = ""
+	c.Starred = false
 
 	m.data[c.ID] = c
 	m.emails[email] = c.ID
-	return c, nil
+	return m.withDerived(c), nil
 }
 
-// Delete removes a contact by ID.
+// Delete moves a contact to the trash along with its relationships,
+// reminders, notifications, attachments and notes, and takes it out of
+// its interactions, favorites and recently viewed contacts.
 func (m *Memory) Delete(_ context.Context, id string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -136,8 +350,22 @@ func (m *Memory) Delete(_ context.Context, id string) error {
 		return model.ErrNotFound
 	}
 
-	delete(m.emails, strings.ToLower(c.Email))
+	e := trashEntry{contact: c, deletedAt: time.Now()}
+	delete(m.emails, model.CanonicalEmail(c.Email))
 	delete(m.data, id)
+	for rid, r := range m.relationships {
+		if r.Involves(id) {
+			e.relationships = append(e.relationships, r)
+			delete(m.relationships, rid)
+		}
+	}
+	e.interactions = m.removeParticipant(id)
+	e.reminders, e.notifications = m.removeReminders(id)
+	e.attachments = m.removeAttachments(id)
+	e.notes = m.removeNotes(id)
+	e.starredBy = m.removeFavorites(id)
+	m.removeDrafts(id)
+	m.trash[id] = e
 	return nil
 }
 
@@ -148,17 +376,51 @@ func (m *Memory) Count(_ context.Context) int {
 	return len(m.data)
 }
 
-// Seed adds sample contacts for development.
+// Seed adds sample companies, contacts, relationships, interactions and
+// reminders for development. Bob's birthday is always today, so the home
+// page has an upcoming date to show.
 func (m *Memory) Seed() {
+	ctx := context.Background()
+	acme, _ := m.CreateCompany(ctx, model.Company{Name: "Acme Corporation", Domain: "acme.com", Website: "https://acme.com"})
+	initech, _ := m.CreateCompany(ctx, model.Company{Name: "Initech", Domain: "initech.com"})
+
 	samples := []model.Contact{
-		{FirstName: "Alice", LastName: "Johnson", Email: "alice@example.com", Phone: "555-0101"},
-		{FirstName: "Bob", LastName: "Smith", Email: "bob@example.com", Phone: "555-0102"},
-		{FirstName: "Carol", LastName: "Williams", Email: "carol@example.com", Phone: "555-0103"},
-		{FirstName: "David", LastName: "Brown", Email: "david@example.com", Phone: "555-0104"},
-		{FirstName: "Eve", LastName: "Davis", Email: "eve@example.com", Phone: "555-0105"},
+		{FirstName: "Alice", LastName: "Johnson", Email: "alice@example.com", Phone: "555-0101", Birthday: "1985-04-12"},
+		{FirstName: "Bob", LastName: "Smith", Email: "bob@example.com", Phone: "555-0102", Birthday: time.Now().Format("--01-02")},
+		{FirstName: "Carol", LastName: "Williams", Email: "carol@example.com", Phone: "555-0103", CompanyID: acme.ID, Title: "Head of Sales", Anniversary: "2012-06-09"},
+		{FirstName: "David", LastName: "Brown", Email: "david@example.com", Phone: "555-0104", CompanyID: initech.ID, Title: "Engineer", Anniversary: "2012-06-09"},
+		{FirstName: "Eve", LastName: "Davis", Email: "eve@example.com", Phone: "555-0105", Dates: []model.ContactDate{{Label: "Joined the team", Date: "2019-09-01"}}},
 	}
-	ctx := context.Background()
-	for _, c := range samples {
-		_, _ = m.Create(ctx, c)
+	ids := make([]string, len(samples))
+	for i, c := range samples {
+		created, _ := m.Create(ctx, c)
+		ids[i] = created.ID
+	}
+
+	// Alice manages Bob and Eve; Carol and David are married.
+	for _, r := range []model.Relationship{
+		{FromID: ids[1], ToID: ids[0], Type: "manager", Bidirectional: true},
+		{FromID: ids[4], ToID: ids[0], Type: "manager", Bidirectional: true},
+		{FromID: ids[2], ToID: ids[3], Type: "spouse", Bidirectional: true},
+	} {
+		_, _ = m.AddRelationship(ctx, r)
+	}
+
+	now := time.Now().Truncate(time.Minute)
+	day := 24 * time.Hour
+	for _, i := range []model.Interaction{
+		{Type: "call", At: now.Add(-3 * day), Duration: 15 * time.Minute, ContactIDs: []string{ids[0]}, Body: "Quarterly check-in."},
+		{Type: "meeting", At: now.Add(-10 * day), Duration: 45 * time.Minute, ContactIDs: []string{ids[0], ids[1], ids[4]}, Body: "Team planning."},
+		{Type: "email", At: now.Add(-40 * day), ContactIDs: []string{ids[2]}, Body: "Sent the proposal."},
+	} {
+		_, _ = m.LogInteraction(ctx, i)
+	}
+
+	for _, r := range []model.Reminder{
+		{ContactID: ids[2], Note: "Follow up on the proposal", Due: now.Add(-2 * day)},
+		{ContactID: ids[1], Note: "Ask about the offsite", Due: now},
+		{ContactID: ids[3], Note: "Catch up", Due: now.Add(14 * day)},
+	} {
+		_, _ = m.AddReminder(ctx, r)
 	}
 }
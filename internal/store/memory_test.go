@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"testing"
 
@@ -63,6 +64,33 @@ func TestMemory_Create_DuplicateEmail(t *testing.T) {
 	}
 }
 
+func TestMemory_Create_DuplicateCanonicalEmail(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := s.Create(ctx, model.Contact{FirstName: "Another", Email: "Alice@Example.com"}); !errors.Is(err, model.ErrDuplicateEmail) {
+		t.Errorf("expected ErrDuplicateEmail, got %v", err)
+	}
+	// Sub-addresses at other domains may be separate mailboxes.
+	if _, err := s.Create(ctx, model.Contact{FirstName: "Alice", LastName: "Sales", Email: "alice+sales@example.com"}); err != nil {
+		t.Errorf("expected a sub-address at a company domain accepted, got %v", err)
+	}
+
+	c, err := s.Create(ctx, model.Contact{FirstName: "Grace", Email: "Grace.Hopper@gmail.com"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if c.Email != "Grace.Hopper@gmail.com" {
+		t.Errorf("expected original spelling kept, got %s", c.Email)
+	}
+	if _, err := s.Create(ctx, model.Contact{FirstName: "Grace", Email: "gracehopper@googlemail.com"}); !errors.Is(err, model.ErrDuplicateEmail) {
+		t.Errorf("expected ErrDuplicateEmail for Gmail alias, got %v", err)
+	}
+	if _, err := s.Create(ctx, model.Contact{FirstName: "Grace", Email: "grace.hopper+work@gmail.com"}); !errors.Is(err, model.ErrDuplicateEmail) {
+		t.Errorf("expected ErrDuplicateEmail for Gmail sub-address, got %v", err)
+	}
+}
+
 func TestMemory_Get(t *testing.T) {
 	s := newTestStore(t)
 	ctx := context.Background()
@@ -90,7 +118,7 @@ func TestMemory_List(t *testing.T) {
 	s := newTestStore(t)
 	ctx := context.Background()
 
-	contacts, err := s.List(ctx, "")
+	contacts, err := s.List(ctx, Query{})
 	if err != nil {
 		t.Fatalf("List: %v", err)
 	}
@@ -103,11 +131,40 @@ func TestMemory_List(t *testing.T) {
 	}
 }
 
+func TestMemory_List_Collation(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		locale string
+		want   []string
+	}{
+		{"en", []string{"Ångström", "Brown", "de la Cruz", "Østergaard", "smith"}},
+		{"da", []string{"Brown", "de la Cruz", "smith", "Østergaard", "Ångström"}},
+	} {
+		t.Run(tt.locale, func(t *testing.T) {
+			s := NewMemory(WithCollation(MustCollation(CollationOptions{Locale: tt.locale})))
+			for i, last := range []string{"smith", "Østergaard", "de la Cruz", "Ångström", "Brown"} {
+				c := model.Contact{FirstName: "X", LastName: last, Email: fmt.Sprintf("x%d@example.com", i)}
+				if _, err := s.Create(ctx, c); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			contacts, err := s.List(ctx, Query{})
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if got := lastNames(contacts); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMemory_List_Search(t *testing.T) {
 	s := newTestStore(t)
 	ctx := context.Background()
 
-	contacts, err := s.List(ctx, "alice")
+	contacts, err := s.List(ctx, Query{Search: "alice"})
 	if err != nil {
 		t.Fatalf("List with search: %v", err)
 	}
@@ -116,6 +173,40 @@ func TestMemory_List_Search(t *testing.T) {
 	}
 }
 
+func TestMemory_CountMatching(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	for _, q := range []Query{{}, {Search: "alice"}, {Search: "nobody"}} {
+		contacts, _ := s.List(ctx, q)
+		n, err := s.CountMatching(ctx, q)
+		if err != nil || n != len(contacts) {
+			t.Errorf("CountMatching(%+v) = %d, %v; List found %d", q, n, err, len(contacts))
+		}
+	}
+}
+
+func TestMemory_List_SearchNames(t *testing.T) {
+	s := NewMemory()
+	ctx := context.Background()
+	_, err := s.Create(ctx, model.Contact{
+		FirstName: "Robert", LastName: "Smith", Nickname: "Bobby", Email: "robert@example.com",
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	for _, q := range []string{"bobby", "robert smith", "smith robert"} {
+		contacts, err := s.List(ctx, Query{Search: q})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(contacts) != 1 {
+			t.Errorf("search %q: expected 1 result, got %d", q, len(contacts))
+		}
+	}
+}
+
 func TestMemory_Update(t *testing.T) {
 	s := newTestStore(t)
 	ctx := context.Background()
@@ -222,7 +313,7 @@ func TestMemory_ConcurrentAccess(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			_, _ = s.List(ctx, "")
+			_, _ = s.List(ctx, Query{})
 		}()
 	}
 
@@ -232,3 +323,73 @@ func TestMemory_ConcurrentAccess(t *testing.T) {
 		t.Errorf("expected 50 contacts, got %d", got)
 	}
 }
+
+func TestMemory_List_CustomFields(t *testing.T) {
+	fields := []model.FieldDef{
+		{Key: "region", Label: "Region", Type: model.FieldSelect, Options: []string{"EMEA", "APAC", "AMER"}},
+		{Key: "seats", Label: "Seats", Type: model.FieldNumber},
+		{Key: "vip", Label: "VIP", Type: model.FieldBoolean},
+	}
+	s := NewMemory(WithFields(fields))
+	ctx := context.Background()
+
+	for _, c := range []model.Contact{
+		{FirstName: "Alice", Email: "alice@example.com", Custom: map[string]string{"region": "EMEA", "seats": "10"}},
+		{FirstName: "Bob", Email: "bob@example.com", Custom: map[string]string{"region": "APAC", "seats": "9", "vip": "true"}},
+		{FirstName: "Carol", Email: "carol@example.com", Custom: map[string]string{"seats": "100"}},
+	} {
+		if _, err := s.Create(ctx, c); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	names := func(q Query) []string {
+		t.Helper()
+		contacts, err := s.List(ctx, q)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		var got []string
+		for _, c := range contacts {
+			got = append(got, c.FirstName)
+		}
+		return got
+	}
+
+	tests := []struct {
+		q    Query
+		want []string
+	}{
+		{Query{Search: "apac"}, []string{"Bob"}},
+		{Query{Search: "true"}, nil},
+		{Query{Sort: "custom.seats"}, []string{"Bob", "Alice", "Carol"}},
+		{Query{Sort: "custom.seats", Desc: true}, []string{"Carol", "Alice", "Bob"}},
+		{Query{Sort: "custom.region"}, []string{"Bob", "Alice", "Carol"}},
+		{Query{Sort: "custom.region", Desc: true}, []string{"Alice", "Bob", "Carol"}},
+		{Query{Sort: "custom.unknown"}, []string{"Alice", "Bob", "Carol"}},
+		{Query{Sort: SortName, Desc: true}, []string{"Carol", "Bob", "Alice"}},
+	}
+	for _, tt := range tests {
+		if got := names(tt.q); !slices.Equal(got, tt.want) {
+			t.Errorf("List(%+v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		in   string
+		key  string
+		desc bool
+	}{
+		{"", "", false},
+		{"email", "email", false},
+		{"-custom.region", "custom.region", true},
+	}
+	for _, tt := range tests {
+		key, desc := ParseSort(tt.in)
+		if key != tt.key || desc != tt.desc {
+			t.Errorf("ParseSort(%q) = %q, %v; want %q, %v", tt.in, key, desc, tt.key, tt.desc)
+		}
+	}
+}
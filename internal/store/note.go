@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// ListNotes returns the contact's notes, the pinned one first and the
+// rest newest first.
+func (m *Memory) ListNotes(_ context.Context, contactID string) ([]model.Note, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []model.Note
+	for _, n := range m.notes {
+		if n.ContactID == contactID {
+			result = append(result, n)
+		}
+	}
+	slices.SortFunc(result, func(a, b model.Note) int {
+		if a.Pinned != b.Pinned {
+			if a.Pinned {
+				return -1
+			}
+			return 1
+		}
+		if lessID(b.ID, a.ID) {
+			return -1
+		}
+		return 1
+	})
+	return result, nil
+}
+
+// GetNote returns a note by ID.
+func (m *Memory) GetNote(_ context.Context, id string) (model.Note, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	n, ok := m.notes[id]
+	if !ok {
+		return model.Note{}, model.ErrNotFound
+	}
+	return n, nil
+}
+
+// AddNote records a note about an existing contact.
+func (m *Memory) AddNote(_ context.Context, n model.Note) (model.Note, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data[n.ContactID]; !ok {
+		return model.Note{}, model.ErrNotFound
+	}
+
+	m.noteCounter++
+	n.ID = fmt.Sprintf("%d", m.noteCounter)
+	n.CreatedAt = time.Now()
+	n.UpdatedAt = n.CreatedAt
+	m.putNote(n)
+	return n, nil
+}
+
+// UpdateNote changes a note's body and whether it is pinned. UpdatedAt
+// only changes with the body.
+func (m *Memory) UpdateNote(_ context.Context, n model.Note) (model.Note, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.notes[n.ID]
+	if !ok {
+		return model.Note{}, model.ErrNotFound
+	}
+	if n.Body != existing.Body {
+		existing.Body = n.Body
+		existing.UpdatedAt = time.Now()
+	}
+	existing.Pinned = n.Pinned
+	m.putNote(existing)
+	return existing, nil
+}
+
+// putNote stores n, unpinning the contact's other notes if n is pinned.
+func (m *Memory) putNote(n model.Note) {
+	if n.Pinned {
+		for id, other := range m.notes {
+			if other.ContactID == n.ContactID && other.Pinned {
+				other.Pinned = false
+				m.notes[id] = other
+			}
+		}
+	}
+	m.notes[n.ID] = n
+}
+
+// DeleteNote removes a note by ID.
+func (m *Memory) DeleteNote(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.notes[id]; !ok {
+		return model.ErrNotFound
+	}
+	delete(m.notes, id)
+	return nil
+}
+
+// removeNotes deletes a deleted contact's notes, returning them.
+func (m *Memory) removeNotes(contactID string) []model.Note {
+	var removed []model.Note
+	for id, n := range m.notes {
+		if n.ContactID == contactID {
+			removed = append(removed, n)
+			delete(m.notes, id)
+		}
+	}
+	return removed
+}
+
+// notesMatching returns the body of each contact's newest note containing
+// the lowercase search term.
+func (m *Memory) notesMatching(search string) map[string]string {
+	newest := make(map[string]model.Note)
+	for _, n := range m.notes {
+		if !strings.Contains(strings.ToLower(n.Body), search) {
+			continue
+		}
+		if prev, ok := newest[n.ContactID]; !ok || lessID(prev.ID, n.ID) {
+			newest[n.ContactID] = n
+		}
+	}
+	bodies := make(map[string]string, len(newest))
+	for id, n := range newest {
+		bodies[id] = n.Body
+	}
+	return bodies
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestMemory_Notes(t *testing.T) {
+	s := newTestStore(t) // Alice=1, Bob=2, Carol=3
+	ctx := context.Background()
+
+	first, _ := s.AddNote(ctx, model.Note{ContactID: "1", Body: "Prefers email."})
+	second, _ := s.AddNote(ctx, model.Note{ContactID: "1", Body: "Kids: Sam and Jo."})
+	third, _ := s.AddNote(ctx, model.Note{ContactID: "1", Body: "Vegetarian."})
+	if _, err := s.AddNote(ctx, model.Note{ContactID: "999", Body: "x"}); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown contact, got %v", err)
+	}
+
+	ids := func() []string {
+		list, _ := s.ListNotes(ctx, "1")
+		var ids []string
+		for _, n := range list {
+			ids = append(ids, n.ID)
+		}
+		return ids
+	}
+	if got := ids(); len(got) != 3 || got[0] != third.ID || got[2] != first.ID {
+		t.Errorf("expected newest first, got %v", got)
+	}
+
+	// Pinning moves a note to the top and unpins the previous one.
+	first.Pinned = true
+	s.UpdateNote(ctx, first)
+	second.Pinned = true
+	second.Body = "Kids: Sam, Jo and Alex."
+	s.UpdateNote(ctx, second)
+	if got := ids(); got[0] != second.ID || got[1] != third.ID {
+		t.Errorf("expected the pinned note first, got %v", got)
+	}
+	if n, _ := s.GetNote(ctx, first.ID); n.Pinned {
+		t.Error("expected the earlier note unpinned")
+	}
+	if n, _ := s.GetNote(ctx, second.ID); n.Body != "Kids: Sam, Jo and Alex." || !n.UpdatedAt.After(n.CreatedAt) {
+		t.Errorf("expected the edited body, got %+v", n)
+	}
+
+	if err := s.DeleteNote(ctx, third.ID); err != nil {
+		t.Fatalf("DeleteNote: %v", err)
+	}
+	if _, err := s.UpdateNote(ctx, third); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound updating a deleted note, got %v", err)
+	}
+
+	s.Delete(ctx, "1")
+	if _, err := s.GetNote(ctx, first.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Error("expected notes deleted with their contact")
+	}
+}
+
+func TestMemory_ListSearchesNotes(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	s.AddNote(ctx, model.Note{ContactID: "2", Body: "Collects vintage *synthesizers*."})
+	s.AddNote(ctx, model.Note{ContactID: "3", Body: "Met Alice at the synthesizer meetup."})
+
+	got, _ := s.List(ctx, Query{Search: "Synthesizer"})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 contacts, got %d", len(got))
+	}
+	for _, c := range got {
+		if c.NoteMatch == "" {
+			t.Errorf("expected a note excerpt for %s", c.FirstName)
+		}
+	}
+
+	// Contacts matching on their own fields carry no excerpt.
+	got, _ = s.List(ctx, Query{Search: "alice"})
+	if len(got) != 2 || got[0].FirstName != "Alice" || got[0].NoteMatch != "" || got[1].NoteMatch == "" {
+		t.Errorf("expected Alice by name and Carol by note, got %+v", got)
+	}
+	if c, _ := s.Get(ctx, "3"); c.NoteMatch != "" {
+		t.Error("expected no excerpt outside List")
+	}
+}
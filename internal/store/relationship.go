@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// ListRelationships returns the relationships visible from contactID in
+// the order they were added.
+func (m *Memory) ListRelationships(_ context.Context, contactID string) ([]model.Relationship, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []model.Relationship
+	for _, r := range m.relationships {
+		if _, _, ok := r.From(contactID); ok {
+			result = append(result, r)
+		}
+	}
+	slices.SortFunc(result, func(a, b model.Relationship) int {
+		if lessID(a.ID, b.ID) {
+			return -1
+		}
+		return 1
+	})
+	return result, nil
+}
+
+// AddRelationship records a relationship between two existing contacts.
+// Recording the same type between the same contacts twice, or a
+// bidirectional relationship that already exists from the other side,
+// returns model.ErrDuplicateRelationship.
+func (m *Memory) AddRelationship(_ context.Context, r model.Relationship) (model.Relationship, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data[r.FromID]; !ok {
+		return model.Relationship{}, model.ErrNotFound
+	}
+	if _, ok := m.data[r.ToID]; !ok {
+		return model.Relationship{}, model.ErrNotFound
+	}
+	for _, existing := range m.relationships {
+		if other, typ, ok := existing.From(r.FromID); ok && other == r.ToID && typ == r.Type {
+			return model.Relationship{}, model.ErrDuplicateRelationship
+		}
+	}
+
+	m.relCounter++
+	r.ID = fmt.Sprintf("%d", m.relCounter)
+	r.CreatedAt = time.Now()
+	m.relationships[r.ID] = r
+	return r, nil
+}
+
+// DeleteRelationship removes a relationship by ID.
+func (m *Memory) DeleteRelationship(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.relationships[id]; !ok {
+		return model.ErrNotFound
+	}
+	delete(m.relationships, id)
+	return nil
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestMemory_Relationships(t *testing.T) {
+	s := newTestStore(t) // Alice=1, Bob=2, Carol=3
+	ctx := context.Background()
+
+	r, err := s.AddRelationship(ctx, model.Relationship{FromID: "2", ToID: "1", Type: "manager", Bidirectional: true})
+	if err != nil {
+		t.Fatalf("AddRelationship: %v", err)
+	}
+	if _, err := s.AddRelationship(ctx, model.Relationship{FromID: "3", ToID: "1", Type: "friend"}); err != nil {
+		t.Fatalf("AddRelationship: %v", err)
+	}
+
+	dupes := []model.Relationship{
+		{FromID: "2", ToID: "1", Type: "manager"},
+		{FromID: "1", ToID: "2", Type: "report"},
+	}
+	for _, d := range dupes {
+		if _, err := s.AddRelationship(ctx, d); !errors.Is(err, model.ErrDuplicateRelationship) {
+			t.Errorf("AddRelationship(%+v) = %v, want ErrDuplicateRelationship", d, err)
+		}
+	}
+	if _, err := s.AddRelationship(ctx, model.Relationship{FromID: "2", ToID: "999", Type: "friend"}); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown contact, got %v", err)
+	}
+
+	counts := map[string]int{"1": 1, "2": 1, "3": 1}
+	for id, want := range counts {
+		rels, _ := s.ListRelationships(ctx, id)
+		if len(rels) != want {
+			t.Errorf("ListRelationships(%s) = %d, want %d", id, len(rels), want)
+		}
+	}
+
+	if err := s.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, id := range []string{"2", "3"} {
+		if rels, _ := s.ListRelationships(ctx, id); len(rels) != 0 {
+			t.Errorf("expected relationships of %s cleaned up, got %v", id, rels)
+		}
+	}
+	if err := s.DeleteRelationship(ctx, r.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
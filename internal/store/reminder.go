@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// ListReminders returns open reminders ordered by due time, limited to
+// contactID's if it is set.
+func (m *Memory) ListReminders(_ context.Context, contactID string) ([]model.Reminder, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []model.Reminder
+	for _, r := range m.reminders {
+		if !r.Completed() && (contactID == "" || r.ContactID == contactID) {
+			result = append(result, r)
+		}
+	}
+	slices.SortFunc(result, func(a, b model.Reminder) int {
+		if cmp := a.Due.Compare(b.Due); cmp != 0 {
+			return cmp
+		}
+		if lessID(a.ID, b.ID) {
+			return -1
+		}
+		return 1
+	})
+	return result, nil
+}
+
+// GetReminder returns a reminder by ID.
+func (m *Memory) GetReminder(_ context.Context, id string) (model.Reminder, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	r, ok := m.reminders[id]
+	if !ok {
+		return model.Reminder{}, model.ErrNotFound
+	}
+	return r, nil
+}
+
+// AddReminder adds a reminder for an existing contact.
+func (m *Memory) AddReminder(_ context.Context, r model.Reminder) (model.Reminder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data[r.ContactID]; !ok {
+		return model.Reminder{}, model.ErrNotFound
+	}
+
+	m.reminderCounter++
+	r.ID = fmt.Sprintf("%d", m.reminderCounter)
+	r.CreatedAt = time.Now()
+	m.reminders[r.ID] = r
+	return r, nil
+}
+
+// UpdateReminder modifies an existing reminder, e.g. to snooze or
+// complete it. The contact can't be changed.
+func (m *Memory) UpdateReminder(_ context.Context, r model.Reminder) (model.Reminder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.reminders[r.ID]
+	if !ok {
+		return model.Reminder{}, model.ErrNotFound
+	}
+	r.ContactID = existing.ContactID
+	r.CreatedAt = existing.CreatedAt
+	m.reminders[r.ID] = r
+	return r, nil
+}
+
+// DeleteReminder removes a reminder by ID.
+func (m *Memory) DeleteReminder(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.reminders[id]; !ok {
+		return model.ErrNotFound
+	}
+	delete(m.reminders, id)
+	return nil
+}
+
+// DeliverDueReminders adds a notification for each open reminder due at
+// or before now and not yet delivered, oldest first, and marks it
+// delivered.
+func (m *Memory) DeliverDueReminders(_ context.Context, now time.Time) ([]model.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []model.Reminder
+	for _, r := range m.reminders {
+		if !r.Completed() && r.NotifiedAt.IsZero() && !r.Due.After(now) {
+			due = append(due, r)
+		}
+	}
+	slices.SortFunc(due, func(a, b model.Reminder) int { return a.Due.Compare(b.Due) })
+
+	var delivered []model.Notification
+	for _, r := range due {
+		m.notificationCounter++
+		n := model.Notification{
+			ID:         fmt.Sprintf("%d", m.notificationCounter),
+			ContactID:  r.ContactID,
+			ReminderID: r.ID,
+			Message:    r.Note,
+			CreatedAt:  now,
+		}
+		m.notifications[n.ID] = n
+		r.NotifiedAt = now
+		m.reminders[r.ID] = r
+		delivered = append(delivered, n)
+	}
+	return delivered, nil
+}
+
+// ListNotifications returns notifications, newest first.
+func (m *Memory) ListNotifications(_ context.Context) ([]model.Notification, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]model.Notification, 0, len(m.notifications))
+	for _, n := range m.notifications {
+		result = append(result, n)
+	}
+	slices.SortFunc(result, func(a, b model.Notification) int {
+		if lessID(b.ID, a.ID) {
+			return -1
+		}
+		return 1
+	})
+	return result, nil
+}
+
+// UnreadNotifications returns the number of unread notifications.
+func (m *Memory) UnreadNotifications(_ context.Context) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	unread := 0
+	for _, n := range m.notifications {
+		if !n.Read {
+			unread++
+		}
+	}
+	return unread, nil
+}
+
+// MarkNotificationsRead marks every notification read.
+func (m *Memory) MarkNotificationsRead(_ context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, n := range m.notifications {
+		n.Read = true
+		m.notifications[id] = n
+	}
+	return nil
+}
+
+// removeReminders deletes a deleted contact's reminders and
+// notifications, returning them.
+func (m *Memory) removeReminders(contactID string) ([]model.Reminder, []model.Notification) {
+	var reminders []model.Reminder
+	for id, r := range m.reminders {
+		if r.ContactID == contactID {
+			reminders = append(reminders, r)
+			delete(m.reminders, id)
+		}
+	}
+	var notifications []model.Notification
+	for id, n := range m.notifications {
+		if n.ContactID == contactID {
+			notifications = append(notifications, n)
+			delete(m.notifications, id)
+		}
+	}
+	return reminders, notifications
+}
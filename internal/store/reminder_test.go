@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestMemory_Reminders(t *testing.T) {
+	s := newTestStore(t) // Alice=1, Bob=2, Carol=3
+	ctx := context.Background()
+	now := time.Now()
+
+	later, _ := s.AddReminder(ctx, model.Reminder{ContactID: "1", Due: now.Add(time.Hour)})
+	soon, _ := s.AddReminder(ctx, model.Reminder{ContactID: "2", Due: now.Add(-time.Hour), Note: "Call"})
+	if _, err := s.AddReminder(ctx, model.Reminder{ContactID: "999", Due: now}); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown contact, got %v", err)
+	}
+
+	list, _ := s.ListReminders(ctx, "")
+	if len(list) != 2 || list[0].ID != soon.ID {
+		t.Errorf("expected reminders by due time, got %+v", list)
+	}
+	if list, _ := s.ListReminders(ctx, "1"); len(list) != 1 || list[0].ID != later.ID {
+		t.Errorf("expected Alice's reminder only, got %+v", list)
+	}
+
+	delivered, _ := s.DeliverDueReminders(ctx, now)
+	if len(delivered) != 1 || delivered[0].ReminderID != soon.ID || delivered[0].Message != "Call" {
+		t.Fatalf("DeliverDueReminders = %+v", delivered)
+	}
+	if again, _ := s.DeliverDueReminders(ctx, now); len(again) != 0 {
+		t.Errorf("reminder delivered twice: %+v", again)
+	}
+
+	// Snoozing makes the reminder deliverable again once it falls due.
+	r, _ := s.GetReminder(ctx, soon.ID)
+	r.Snooze(now.Add(30 * time.Minute))
+	if _, err := s.UpdateReminder(ctx, r); err != nil {
+		t.Fatalf("UpdateReminder: %v", err)
+	}
+	if again, _ := s.DeliverDueReminders(ctx, now.Add(2*time.Hour)); len(again) != 2 {
+		t.Errorf("expected snoozed and later reminders delivered, got %+v", again)
+	}
+
+	later.CompletedAt = now
+	_, _ = s.UpdateReminder(ctx, later)
+	if list, _ := s.ListReminders(ctx, "1"); len(list) != 0 {
+		t.Errorf("completed reminder still listed: %+v", list)
+	}
+
+	if unread, _ := s.UnreadNotifications(ctx); unread != 3 {
+		t.Errorf("unread = %d, want 3", unread)
+	}
+	notes, _ := s.ListNotifications(ctx)
+	if len(notes) != 3 || notes[0].ID != "3" {
+		t.Errorf("expected newest notification first, got %+v", notes)
+	}
+	_ = s.MarkNotificationsRead(ctx)
+	if unread, _ := s.UnreadNotifications(ctx); unread != 0 {
+		t.Errorf("unread after marking read = %d", unread)
+	}
+
+	if err := s.Delete(ctx, "2"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.GetReminder(ctx, soon.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected Bob's reminder deleted, got %v", err)
+	}
+	if notes, _ := s.ListNotifications(ctx); len(notes) != 1 {
+		t.Errorf("expected Bob's notifications deleted, got %+v", notes)
+	}
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// ListSavedSearches returns owner's saved searches in name order.
+func (m *Memory) ListSavedSearches(_ context.Context, owner string) ([]model.SavedSearch, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []model.SavedSearch
+	for _, s := range m.searches {
+		if s.Owner == owner {
+			result = append(result, s)
+		}
+	}
+	slices.SortStableFunc(result, func(a, b model.SavedSearch) int {
+		if cmp := m.collation.Compare(a.Name, b.Name); cmp != 0 {
+			return cmp
+		}
+		if lessID(a.ID, b.ID) {
+			return -1
+		}
+		return 1
+	})
+	return result, nil
+}
+
+// GetSavedSearch returns a saved search by ID.
+func (m *Memory) GetSavedSearch(_ context.Context, id string) (model.SavedSearch, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.searches[id]
+	if !ok {
+		return model.SavedSearch{}, model.ErrNotFound
+	}
+	return s, nil
+}
+
+// SaveSearch records a saved search. Each owner's names are unique
+// regardless of case.
+func (m *Memory) SaveSearch(_ context.Context, s model.SavedSearch) (model.SavedSearch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s.Name = strings.TrimSpace(s.Name)
+	for _, other := range m.searches {
+		if other.Owner == s.Owner && strings.EqualFold(other.Name, s.Name) {
+			return model.SavedSearch{}, model.ErrDuplicateSearch
+		}
+	}
+
+	m.searchCounter++
+	s.ID = fmt.Sprintf("%d", m.searchCounter)
+	s.CreatedAt = time.Now()
+	m.searches[s.ID] = s
+	return s, nil
+}
+
+// DeleteSavedSearch removes one of owner's saved searches by ID.
+func (m *Memory) DeleteSavedSearch(_ context.Context, owner, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok := m.searches[id]; !ok || s.Owner != owner {
+		return model.ErrNotFound
+	}
+	delete(m.searches, id)
+	return nil
+}
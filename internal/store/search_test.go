@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestMemory_SavedSearches(t *testing.T) {
+	s := NewMemory()
+	ctx := context.Background()
+
+	lapsed, err := s.SaveSearch(ctx, model.SavedSearch{Name: " lapsed ", Query: "contacted=over-90d"})
+	if err != nil || lapsed.ID == "" || lapsed.Name != "lapsed" {
+		t.Fatalf("SaveSearch: %+v, %v", lapsed, err)
+	}
+	s.SaveSearch(ctx, model.SavedSearch{Name: "Acme", Query: "q=acme"})
+	if _, err := s.SaveSearch(ctx, model.SavedSearch{Name: "LAPSED"}); !errors.Is(err, model.ErrDuplicateSearch) {
+		t.Errorf("expected ErrDuplicateSearch, got %v", err)
+	}
+
+	list, _ := s.ListSavedSearches(ctx, "")
+	if len(list) != 2 || list[0].Name != "Acme" || list[1].Name != "lapsed" {
+		t.Errorf("expected name order, got %+v", list)
+	}
+
+	got, err := s.GetSavedSearch(ctx, lapsed.ID)
+	if err != nil || got.Query != "contacted=over-90d" {
+		t.Errorf("GetSavedSearch: %+v, %v", got, err)
+	}
+	if err := s.DeleteSavedSearch(ctx, "", lapsed.ID); err != nil {
+		t.Fatalf("DeleteSavedSearch: %v", err)
+	}
+	if _, err := s.GetSavedSearch(ctx, lapsed.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := s.DeleteSavedSearch(ctx, "", lapsed.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestMemory_SavedSearchesPerOwner(t *testing.T) {
+	s := NewMemory()
+	ctx := context.Background()
+
+	mine, _ := s.SaveSearch(ctx, model.SavedSearch{Owner: "a", Name: "Lapsed", Query: "contacted=over-90d"})
+	theirs, err := s.SaveSearch(ctx, model.SavedSearch{Owner: "b", Name: "lapsed", Query: "q=acme"})
+	if err != nil {
+		t.Fatalf("expected names to be unique per owner only, got %v", err)
+	}
+
+	if list, _ := s.ListSavedSearches(ctx, "a"); len(list) != 1 || list[0].ID != mine.ID {
+		t.Errorf("expected only a's search, got %+v", list)
+	}
+	if _, err := s.GetSavedSearch(ctx, theirs.ID); err != nil {
+		t.Errorf("expected anyone to open a search by ID, got %v", err)
+	}
+	if err := s.DeleteSavedSearch(ctx, "a", theirs.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting another owner's search, got %v", err)
+	}
+}
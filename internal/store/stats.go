@@ -0,0 +1,99 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// CreatedPerWeek counts the contacts created in each of the weeks weeks
+// up to and including the one containing now, oldest first. Weeks start
+// on Monday in now's location.
+func (m *Memory) CreatedPerWeek(_ context.Context, now time.Time, weeks int) ([]model.WeekCount, error) {
+	if weeks <= 0 {
+		return nil, nil
+	}
+	y, mo, d := now.Date()
+	today := time.Date(y, mo, d, 0, 0, 0, 0, now.Location())
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+
+	counts := make([]model.WeekCount, weeks)
+	for i := range counts {
+		counts[i].Start = monday.AddDate(0, 0, -7*(weeks-1-i))
+	}
+	end := monday.AddDate(0, 0, 7)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, c := range m.data {
+		if c.CreatedAt.Before(counts[0].Start) || !c.CreatedAt.Before(end) {
+			continue
+		}
+		i, found := slices.BinarySearchFunc(counts, c.CreatedAt, func(w model.WeekCount, t time.Time) int {
+			return w.Start.Compare(t)
+		})
+		if !found {
+			i--
+		}
+		counts[i].Count++
+	}
+	return counts, nil
+}
+
+// TopEmailDomains returns up to limit email domains with the most
+// contacts, most first and ties in domain order. Domains are compared in
+// lowercase punycode form.
+func (m *Memory) TopEmailDomains(_ context.Context, limit int) ([]model.DomainCount, error) {
+	m.mu.RLock()
+	byDomain := make(map[string]int)
+	for _, c := range m.data {
+		i := strings.LastIndexByte(c.Email, '@')
+		if i < 0 {
+			continue
+		}
+		domain := model.CompanyDomain(c.Email[i+1:])
+		if domain == "" {
+			domain = strings.ToLower(c.Email[i+1:])
+		}
+		byDomain[domain]++
+	}
+	m.mu.RUnlock()
+
+	counts := make([]model.DomainCount, 0, len(byDomain))
+	for domain, n := range byDomain {
+		counts = append(counts, model.DomainCount{Domain: domain, Count: n})
+	}
+	slices.SortFunc(counts, func(a, b model.DomainCount) int {
+		return cmp.Or(b.Count-a.Count, strings.Compare(a.Domain, b.Domain))
+	})
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts, nil
+}
+
+// RecentlyChanged returns up to limit contacts, most recently created or
+// updated first.
+func (m *Memory) RecentlyChanged(_ context.Context, limit int) ([]model.Contact, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := make([]model.Contact, 0, len(m.data))
+	for _, c := range m.data {
+		list = append(list, c)
+	}
+	slices.SortFunc(list, func(a, b model.Contact) int {
+		return cmp.Or(b.UpdatedAt.Compare(a.UpdatedAt), b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	for i := range list {
+		list[i] = m.withDerived(list[i])
+	}
+	return list, nil
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestMemory_CreatedPerWeek(t *testing.T) {
+	s := newTestStore(t) // Alice=1, Bob=2, Carol=3
+	ctx := context.Background()
+
+	// Wednesday, March 11, 2026; the week starts on Monday the 9th.
+	now := time.Date(2026, 3, 11, 15, 0, 0, 0, time.UTC)
+	created := map[string]time.Time{
+		"1": time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+		"2": time.Date(2026, 3, 8, 23, 59, 0, 0, time.UTC),
+		"3": time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC),
+	}
+	for id, at := range created {
+		c := s.data[id]
+		c.CreatedAt = at
+		s.data[id] = c
+	}
+
+	weeks, err := s.CreatedPerWeek(ctx, now, 3)
+	if err != nil {
+		t.Fatalf("CreatedPerWeek: %v", err)
+	}
+	var starts []string
+	var counts []int
+	for _, w := range weeks {
+		starts = append(starts, w.Start.Format(model.DateLayout))
+		counts = append(counts, w.Count)
+	}
+	if want := []string{"2026-02-23", "2026-03-02", "2026-03-09"}; !reflect.DeepEqual(starts, want) {
+		t.Errorf("starts = %v, want %v", starts, want)
+	}
+	if want := []int{0, 1, 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+
+	// Weeks follow the location: Alice was created on Sunday in New York.
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone data")
+	}
+	weeks, _ = s.CreatedPerWeek(ctx, now.In(ny), 2)
+	if weeks[0].Count != 2 || weeks[1].Count != 0 {
+		t.Errorf("expected Alice and Bob in the previous week in New York, got %+v", weeks)
+	}
+}
+
+func TestMemory_TopEmailDomains(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	s.Create(ctx, model.Contact{FirstName: "Dan", Email: "dan@Initech.test"})
+	s.Create(ctx, model.Contact{FirstName: "Erin", Email: "erin@initech.test"})
+	s.Create(ctx, model.Contact{FirstName: "Finn", Email: "finn@acme.test"})
+
+	got, err := s.TopEmailDomains(ctx, 2)
+	if err != nil {
+		t.Fatalf("TopEmailDomains: %v", err)
+	}
+	want := []model.DomainCount{{Domain: "example.com", Count: 3}, {Domain: "initech.test", Count: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestMemory_RecentlyChanged(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	alice, _ := s.Get(ctx, "1")
+	alice.Phone = "555-0100"
+	s.Update(ctx, alice)
+
+	list, err := s.RecentlyChanged(ctx, 2)
+	if err != nil {
+		t.Fatalf("RecentlyChanged: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != "1" {
+		t.Errorf("expected Alice first, got %+v", list)
+	}
+}
@@ -2,16 +2,269 @@ package store
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/devaloi/htmxapp/internal/model"
 )
 
 // ContactStore defines the interface for contact persistence.
 type ContactStore interface {
-	List(ctx context.Context, search string) ([]model.Contact, error)
+	List(ctx context.Context, q Query) ([]model.Contact, error)
+
+	// CountMatching returns how many contacts List would return for q,
+	// without sorting them.
+	CountMatching(ctx context.Context, q Query) (int, error)
 	Get(ctx context.Context, id string) (model.Contact, error)
 	Create(ctx context.Context, c model.Contact) (model.Contact, error)
 	Update(ctx context.Context, c model.Contact) (model.Contact, error)
+
+	// Delete moves the contact to the trash; see TrashStore.
 	Delete(ctx context.Context, id string) error
 	Count(ctx context.Context) int
 }
+
+// CompanyStore defines the interface for company persistence.
+type CompanyStore interface {
+	ListCompanies(ctx context.Context, search string) ([]model.Company, error)
+	GetCompany(ctx context.Context, id string) (model.Company, error)
+	CreateCompany(ctx context.Context, c model.Company) (model.Company, error)
+	UpdateCompany(ctx context.Context, c model.Company) (model.Company, error)
+	DeleteCompany(ctx context.Context, id string) error
+
+	// CompanyByDomain returns the company owning domain or, failing that,
+	// its closest parent domain, so "eu.acme.com" finds "acme.com".
+	CompanyByDomain(ctx context.Context, domain string) (model.Company, error)
+}
+
+// RelationshipStore defines the interface for relationships between
+// contacts. Deleting a contact deletes its relationships.
+type RelationshipStore interface {
+	// ListRelationships returns the relationships visible from contactID:
+	// those it recorded and bidirectional ones pointing at it.
+	ListRelationships(ctx context.Context, contactID string) ([]model.Relationship, error)
+	AddRelationship(ctx context.Context, r model.Relationship) (model.Relationship, error)
+	DeleteRelationship(ctx context.Context, id string) error
+}
+
+// InteractionStore defines the interface for the interaction log.
+// Deleting a contact removes it from its interactions, and interactions
+// left without participants are deleted.
+type InteractionStore interface {
+	// ListInteractions returns the contact's interactions, newest first.
+	ListInteractions(ctx context.Context, contactID string) ([]model.Interaction, error)
+	LogInteraction(ctx context.Context, i model.Interaction) (model.Interaction, error)
+	DeleteInteraction(ctx context.Context, id string) error
+}
+
+// ReminderStore defines the interface for follow-up reminders. Deleting a
+// contact deletes its reminders.
+type ReminderStore interface {
+	// ListReminders returns open reminders ordered by due time, limited to
+	// contactID's if it is set.
+	ListReminders(ctx context.Context, contactID string) ([]model.Reminder, error)
+	GetReminder(ctx context.Context, id string) (model.Reminder, error)
+	AddReminder(ctx context.Context, r model.Reminder) (model.Reminder, error)
+	UpdateReminder(ctx context.Context, r model.Reminder) (model.Reminder, error)
+	DeleteReminder(ctx context.Context, id string) error
+
+	// DeliverDueReminders adds a notification for each open reminder due
+	// at or before now and not yet delivered, and marks it delivered in the
+	// same step, so a durable store delivers each reminder once even
+	// across restarts.
+	DeliverDueReminders(ctx context.Context, now time.Time) ([]model.Notification, error)
+}
+
+// NotificationStore defines the interface for in-app notifications.
+// Deleting a contact deletes its notifications.
+type NotificationStore interface {
+	// ListNotifications returns notifications, newest first.
+	ListNotifications(ctx context.Context) ([]model.Notification, error)
+	UnreadNotifications(ctx context.Context) (int, error)
+	MarkNotificationsRead(ctx context.Context) error
+}
+
+// AttachmentStore defines the interface for files attached to contacts.
+// It records attachments only; their content lives in a blob store keyed
+// by checksum. Deleting a contact deletes its attachments.
+type AttachmentStore interface {
+	// ListAttachments returns the contact's attachments, newest first.
+	ListAttachments(ctx context.Context, contactID string) ([]model.Attachment, error)
+	GetAttachment(ctx context.Context, id string) (model.Attachment, error)
+
+	// AddAttachment records an attachment, failing with
+	// model.ErrQuotaExceeded if the contact's attachments would then take
+	// more than quota bytes. The check and the insert are atomic, so
+	// concurrent uploads can't exceed the quota together.
+	AddAttachment(ctx context.Context, a model.Attachment, quota int64) (model.Attachment, error)
+	DeleteAttachment(ctx context.Context, id string) error
+
+	// AttachmentBlobInUse reports whether any attachment has the checksum,
+	// including those of contacts in the trash, so a blob shared by
+	// identical files is kept until the last one goes.
+	AttachmentBlobInUse(ctx context.Context, checksum string) (bool, error)
+}
+
+// NoteStore defines the interface for notes about contacts. Pinning a
+// note unpins the contact's other notes; deleting a contact deletes its
+// notes.
+type NoteStore interface {
+	// ListNotes returns the contact's notes, the pinned one first and the
+	// rest newest first.
+	ListNotes(ctx context.Context, contactID string) ([]model.Note, error)
+	GetNote(ctx context.Context, id string) (model.Note, error)
+	AddNote(ctx context.Context, n model.Note) (model.Note, error)
+	UpdateNote(ctx context.Context, n model.Note) (model.Note, error)
+	DeleteNote(ctx context.Context, id string) error
+}
+
+// SavedSearchStore defines the interface for saved searches: named
+// contacts list queries, each belonging to the user who saved it. Any
+// user can open one by ID, so its URL can be shared.
+type SavedSearchStore interface {
+	// ListSavedSearches returns owner's saved searches in name order.
+	ListSavedSearches(ctx context.Context, owner string) ([]model.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id string) (model.SavedSearch, error)
+
+	// SaveSearch records a new saved search for s.Owner, failing with
+	// model.ErrDuplicateSearch if the owner already has one by that name,
+	// ignoring case.
+	SaveSearch(ctx context.Context, s model.SavedSearch) (model.SavedSearch, error)
+
+	// DeleteSavedSearch removes one of owner's saved searches, failing
+	// with model.ErrNotFound for anyone else's.
+	DeleteSavedSearch(ctx context.Context, owner, id string) error
+}
+
+// FavoriteStore defines the interface for starred and recently viewed
+// contacts, kept separately for each user. List reports a user's stars in
+// Contact.Starred and filters by them, both for Query.User. Deleting a
+// contact removes it from every user's stars and views.
+type FavoriteStore interface {
+	StarContact(ctx context.Context, user, id string, starred bool) error
+	IsStarred(ctx context.Context, user, id string) (bool, error)
+
+	// RecordView notes that user opened the contact's page at the time.
+	RecordView(ctx context.Context, user, id string, at time.Time) error
+
+	// RecentlyViewed returns up to limit contacts user viewed, most
+	// recently viewed first.
+	RecentlyViewed(ctx context.Context, user string, limit int) ([]model.ContactView, error)
+}
+
+// DraftStore defines the interface for contact form drafts, kept
+// separately for each user: one per contact and one for a new contact.
+// Deleting a contact deletes every user's draft of it.
+type DraftStore interface {
+	// SaveDraft replaces d.Owner's draft for d.ContactID, setting SavedAt.
+	// It fails with model.ErrNotFound if the contact doesn't exist.
+	SaveDraft(ctx context.Context, d model.Draft) (model.Draft, error)
+
+	// GetDraft returns owner's draft for contactID, "" for a new contact.
+	GetDraft(ctx context.Context, owner, contactID string) (model.Draft, error)
+
+	// DeleteDraft discards owner's draft for contactID, if there is one.
+	DeleteDraft(ctx context.Context, owner, contactID string) error
+}
+
+// StatsStore defines the interface for the aggregates the dashboard
+// shows.
+type StatsStore interface {
+	// CreatedPerWeek counts the contacts created in each of the weeks
+	// weeks up to and including the one containing now, oldest first.
+	// Weeks start on Monday in now's location.
+	CreatedPerWeek(ctx context.Context, now time.Time, weeks int) ([]model.WeekCount, error)
+
+	// TopEmailDomains returns up to limit email domains with the most
+	// contacts, most first.
+	TopEmailDomains(ctx context.Context, limit int) ([]model.DomainCount, error)
+
+	// RecentlyChanged returns up to limit contacts, most recently created
+	// or updated first.
+	RecentlyChanged(ctx context.Context, limit int) ([]model.Contact, error)
+}
+
+// TrashStore defines the interface for deleted contacts. Deleting a
+// contact takes its relationships, reminders, notifications, attachments
+// and notes with it to the trash, and restoring it brings them back.
+type TrashStore interface {
+	// ListTrash returns the contacts in the trash, most recently deleted
+	// first.
+	ListTrash(ctx context.Context) ([]model.TrashedContact, error)
+
+	// RestoreContact takes a contact out of the trash, failing with
+	// model.ErrDuplicateEmail if another contact has taken its email.
+	RestoreContact(ctx context.Context, id string) (model.Contact, error)
+
+	// PurgeContact deletes a contact in the trash for good.
+	PurgeContact(ctx context.Context, id string) (model.TrashedContact, error)
+}
+
+// Store combines the stores the application needs.
+type Store interface {
+	ContactStore
+	CompanyStore
+	RelationshipStore
+	InteractionStore
+	ReminderStore
+	NotificationStore
+	AttachmentStore
+	NoteStore
+	SavedSearchStore
+	FavoriteStore
+	DraftStore
+	StatsStore
+	TrashStore
+}
+
+// Sort keys accepted by Query.Sort. Custom fields sort by SortCustom plus
+// the field key, e.g. "custom.region".
+const (
+	SortName          = "name"
+	SortEmail         = "email"
+	SortLastContacted = "last_contacted"
+	SortCustom        = "custom."
+)
+
+// Query selects and orders the contacts List returns.
+type Query struct {
+	// Search matches names, email, phone, company name, title,
+	// searchable custom fields and notes. Contacts found only through a
+	// note have an excerpt of it in NoteMatch.
+	Search string
+
+	// CompanyID limits the results to the company's contacts.
+	CompanyID string
+
+	// User is whose stars Starred and Contact.Starred refer to. Starred
+	// limits the results to the contacts they starred.
+	User    string
+	Starred bool
+
+	// ContactedSince limits the results to contacts with an interaction
+	// at or after the time. NotContactedSince limits them to contacts
+	// without one, including those never contacted, and NeverContacted to
+	// contacts with no interactions at all.
+	ContactedSince    time.Time
+	NotContactedSince time.Time
+	NeverContacted    bool
+
+	// Filter selects contacts by conditions on their fields. Conditions
+	// that are incomplete or don't validate are ignored.
+	Filter Filter
+
+	// Sort is one of the sort keys; unknown keys sort by name. Contacts
+	// without a value for the key sort last in either direction.
+	Sort string
+	Desc bool
+}
+
+// ParseSort splits a sort parameter such as "-custom.region" into its key
+// and direction; a leading "-" sorts descending.
+func ParseSort(s string) (key string, desc bool) {
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		return rest, true
+	}
+	return s, false
+}
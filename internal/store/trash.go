@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// trashEntry is a deleted contact with the records deleting it removed,
+// so restoring it can put them back.
+type trashEntry struct {
+	contact   model.Contact
+	deletedAt time.Time
+
+	relationships []model.Relationship
+	interactions  []model.Interaction // as they were, the contact included
+	reminders     []model.Reminder
+	notifications []model.Notification
+	attachments   []model.Attachment
+	notes         []model.Note
+	starredBy     []string // user IDs
+}
+
+func (e trashEntry) trashed() model.TrashedContact {
+	return model.TrashedContact{
+		Contact:     e.contact,
+		DeletedAt:   e.deletedAt,
+		Attachments: slices.Clone(e.attachments),
+	}
+}
+
+// ListTrash returns the contacts in the trash, most recently deleted
+// first.
+func (m *Memory) ListTrash(_ context.Context) ([]model.TrashedContact, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]model.TrashedContact, 0, len(m.trash))
+	for _, e := range m.trash {
+		t := e.trashed()
+		t.Contact.Company = m.companies[t.Contact.CompanyID].Name
+		result = append(result, t)
+	}
+	slices.SortFunc(result, func(a, b model.TrashedContact) int {
+		if cmp := b.DeletedAt.Compare(a.DeletedAt); cmp != 0 {
+			return cmp
+		}
+		if lessID(b.Contact.ID, a.Contact.ID) {
+			return -1
+		}
+		return 1
+	})
+	return result, nil
+}
+
+// RestoreContact takes a contact out of the trash with its records. A
+// relationship with a contact still in the trash comes back with that
+// one, and an interaction the contact was dropped from gets it back
+// unless the interaction was deleted meanwhile. A company deleted
+// meanwhile is unlinked.
+func (m *Memory) RestoreContact(_ context.Context, id string) (model.Contact, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.trash[id]
+	if !ok {
+		return model.Contact{}, model.ErrNotFound
+	}
+	c := e.contact
+	email := model.CanonicalEmail(c.Email)
+	if _, taken := m.emails[email]; taken {
+		return model.Contact{}, model.ErrDuplicateEmail
+	}
+	if _, ok := m.companies[c.CompanyID]; !ok {
+		c.CompanyID = ""
+	}
+
+	delete(m.trash, id)
+	m.data[id] = c
+	m.emails[email] = id
+	for _, r := range e.relationships {
+		other := r.FromID
+		if other == id {
+			other = r.ToID
+		}
+		if o, ok := m.trash[other]; ok {
+			// It comes back with the other contact.
+			o.relationships = append(o.relationships, r)
+			m.trash[other] = o
+		} else if _, ok := m.data[other]; ok || other == id {
+			m.relationships[r.ID] = r
+		}
+	}
+	touched := []string{id}
+	for _, i := range e.interactions {
+		if cur, ok := m.interactions[i.ID]; ok {
+			if !cur.Involves(id) {
+				cur.ContactIDs = append(slices.Clone(cur.ContactIDs), id)
+				m.interactions[i.ID] = cur
+			}
+			touched = append(touched, cur.ContactIDs...)
+			continue
+		}
+		// The interaction is gone. If it only lost contacts to the trash,
+		// it was deleted for being left empty and comes back with the
+		// contacts that are out of the trash; otherwise a user deleted it.
+		if !m.onlyTrashed(i.ContactIDs, id) {
+			continue
+		}
+		i.ContactIDs = slices.DeleteFunc(slices.Clone(i.ContactIDs), func(cid string) bool {
+			_, ok := m.data[cid]
+			return !ok
+		})
+		m.interactions[i.ID] = i
+		touched = append(touched, i.ContactIDs...)
+	}
+	m.updateLastContacted(touched...)
+	for _, r := range e.reminders {
+		m.reminders[r.ID] = r
+	}
+	for _, n := range e.notifications {
+		m.notifications[n.ID] = n
+	}
+	for _, a := range e.attachments {
+		m.attachments[a.ID] = a
+	}
+	for _, n := range e.notes {
+		m.notes[n.ID] = n
+	}
+	for _, user := range e.starredBy {
+		if m.starred[user] == nil {
+			m.starred[user] = make(map[string]bool)
+		}
+		m.starred[user][id] = true
+	}
+	return m.withDerived(c), nil
+}
+
+// onlyTrashed reports whether every contact in ids other than except is
+// in the trash.
+func (m *Memory) onlyTrashed(ids []string, except string) bool {
+	for _, id := range ids {
+		if _, ok := m.trash[id]; id != except && !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// PurgeContact deletes a contact in the trash for good, returning it so
+// the caller can delete its photo and attachment blobs.
+func (m *Memory) PurgeContact(_ context.Context, id string) (model.TrashedContact, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.trash[id]
+	if !ok {
+		return model.TrashedContact{}, model.ErrNotFound
+	}
+	delete(m.trash, id)
+	return e.trashed(), nil
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestMemory_TrashRestore(t *testing.T) {
+	s := newTestStore(t) // Alice=1, Bob=2, Carol=3
+	ctx := context.Background()
+
+	s.AddRelationship(ctx, model.Relationship{FromID: "1", ToID: "2", Type: "friend"})
+	s.AddRelationship(ctx, model.Relationship{FromID: "1", ToID: "3", Type: "friend"})
+	call, _ := s.LogInteraction(ctx, model.Interaction{Type: "call", At: time.Now(), ContactIDs: []string{"1", "2"}})
+	s.AddNote(ctx, model.Note{ContactID: "1", Body: "Prefers email."})
+	s.AddAttachment(ctx, model.Attachment{ContactID: "1", Name: "nda.pdf", Size: 10, Checksum: "aa"}, 100)
+	s.StarContact(ctx, "u", "1", true)
+
+	if err := s.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.Delete(ctx, "1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+	if _, err := s.Get(ctx, "1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected Alice gone, got %v", err)
+	}
+	if i, _ := s.ListInteractions(ctx, "2"); len(i) != 1 || i[0].Involves("1") {
+		t.Errorf("expected Bob's call without Alice, got %+v", i)
+	}
+	if inUse, _ := s.AttachmentBlobInUse(ctx, "aa"); !inUse {
+		t.Error("expected the trash to keep Alice's attachment blob in use")
+	}
+	trash, _ := s.ListTrash(ctx)
+	if len(trash) != 1 || trash[0].Contact.ID != "1" || len(trash[0].Attachments) != 1 {
+		t.Fatalf("expected Alice in the trash with her attachment, got %+v", trash)
+	}
+
+	// Carol is deleted too, so her relationship with Alice comes back
+	// only when both are restored.
+	s.Delete(ctx, "3")
+	c, err := s.RestoreContact(ctx, "1")
+	if err != nil {
+		t.Fatalf("RestoreContact: %v", err)
+	}
+	if c.LastContacted.IsZero() {
+		t.Error("expected the call to count as contact again")
+	}
+	if rels, _ := s.ListRelationships(ctx, "1"); len(rels) != 1 || rels[0].ToID != "2" {
+		t.Errorf("expected only the relationship with Bob back, got %+v", rels)
+	}
+	if i, _ := s.ListInteractions(ctx, "1"); len(i) != 1 || i[0].ID != call.ID || !i[0].Involves("2") {
+		t.Errorf("expected Alice back on the call with Bob, got %+v", i)
+	}
+	if n, _ := s.ListNotes(ctx, "1"); len(n) != 1 {
+		t.Errorf("expected Alice's note back, got %+v", n)
+	}
+	if a, _ := s.ListAttachments(ctx, "1"); len(a) != 1 {
+		t.Errorf("expected Alice's attachment back, got %+v", a)
+	}
+	if starred, _ := s.IsStarred(ctx, "u", "1"); !starred {
+		t.Error("expected Alice starred again")
+	}
+
+	s.RestoreContact(ctx, "3")
+	if rels, _ := s.ListRelationships(ctx, "1"); len(rels) != 2 {
+		t.Errorf("expected both relationships back, got %+v", rels)
+	}
+	if _, err := s.RestoreContact(ctx, "3"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound restoring twice, got %v", err)
+	}
+}
+
+func TestMemory_TrashRestoreInteraction(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	// A call left with no one when both its contacts are deleted comes
+	// back with the first one restored.
+	call, _ := s.LogInteraction(ctx, model.Interaction{Type: "call", At: time.Now(), ContactIDs: []string{"1", "2"}})
+	s.Delete(ctx, "1")
+	s.Delete(ctx, "2")
+	s.RestoreContact(ctx, "2")
+	if i, _ := s.ListInteractions(ctx, "2"); len(i) != 1 || i[0].ID != call.ID || i[0].Involves("1") {
+		t.Fatalf("expected the call back with Bob only, got %+v", i)
+	}
+	s.RestoreContact(ctx, "1")
+	if i, _ := s.ListInteractions(ctx, "1"); len(i) != 1 || !i[0].Involves("2") {
+		t.Errorf("expected Alice back on the call with Bob, got %+v", i)
+	}
+
+	// One a user deleted stays deleted.
+	s.Delete(ctx, "1")
+	s.DeleteInteraction(ctx, call.ID)
+	s.RestoreContact(ctx, "1")
+	if i, _ := s.ListInteractions(ctx, "1"); len(i) != 0 {
+		t.Errorf("expected the deleted call to stay deleted, got %+v", i)
+	}
+}
+
+func TestMemory_TrashRestoreConflicts(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	acme, _ := s.CreateCompany(ctx, model.Company{Name: "Acme"})
+	c, _ := s.Get(ctx, "1")
+	c.CompanyID = acme.ID
+	s.Update(ctx, c)
+	s.Delete(ctx, "1")
+	s.DeleteCompany(ctx, acme.ID)
+
+	// Another contact takes Alice's email while she is in the trash.
+	other, _ := s.Create(ctx, model.Contact{FirstName: "Alicia", Email: "Alice@example.com"})
+	if _, err := s.RestoreContact(ctx, "1"); !errors.Is(err, model.ErrDuplicateEmail) {
+		t.Fatalf("expected ErrDuplicateEmail, got %v", err)
+	}
+	if trash, _ := s.ListTrash(ctx); len(trash) != 1 {
+		t.Errorf("expected Alice to stay in the trash, got %+v", trash)
+	}
+
+	s.Delete(ctx, other.ID)
+	s.PurgeContact(ctx, other.ID)
+	c, err := s.RestoreContact(ctx, "1")
+	if err != nil {
+		t.Fatalf("RestoreContact: %v", err)
+	}
+	if c.CompanyID != "" {
+		t.Errorf("expected the deleted company unlinked, got %q", c.CompanyID)
+	}
+}
+
+func TestMemory_PurgeContact(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	s.AddAttachment(ctx, model.Attachment{ContactID: "1", Name: "nda.pdf", Size: 10, Checksum: "aa"}, 100)
+	if _, err := s.PurgeContact(ctx, "1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound purging a live contact, got %v", err)
+	}
+
+	s.Delete(ctx, "1")
+	s.Delete(ctx, "2")
+	if trash, _ := s.ListTrash(ctx); len(trash) != 2 || trash[0].Contact.ID != "2" {
+		t.Errorf("expected the trash newest first, got %+v", trash)
+	}
+
+	got, err := s.PurgeContact(ctx, "1")
+	if err != nil {
+		t.Fatalf("PurgeContact: %v", err)
+	}
+	if got.Contact.ID != "1" || len(got.Attachments) != 1 {
+		t.Errorf("expected Alice with her attachment, got %+v", got)
+	}
+	if inUse, _ := s.AttachmentBlobInUse(ctx, "aa"); inUse {
+		t.Error("expected the blob unused once Alice is purged")
+	}
+	if _, err := s.RestoreContact(ctx, "1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound restoring a purged contact, got %v", err)
+	}
+}
@@ -0,0 +1,348 @@
+package tmpl
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/devaloi/htmxapp/internal/i18n"
+	"github.com/devaloi/htmxapp/internal/markdown"
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+// now is replaced in tests to make relative times deterministic.
+var now = time.Now
+
+// language is an entry in the layout's language switcher.
+type language struct {
+	Code    string
+	Name    string
+	Current bool
+}
+
+// funcs returns the helpers available to every template, with translation
+// bound to p. Helpers that build URLs or markup return typed values only
+// after validating their input, so html/template's escaping is never
+// bypassed for user data.
+func (r *Renderer) funcs(p *i18n.Printer) template.FuncMap {
+	liveReload, nameOrder, fields := r.liveReload, r.nameOrder, r.fields
+	var listFields []model.FieldDef
+	for _, f := range fields {
+		if f.List {
+			listFields = append(listFields, f)
+		}
+	}
+	languages := make([]language, 0, len(r.bundle.Locales()))
+	for _, code := range r.bundle.Locales() {
+		languages = append(languages, language{
+			Code:    code,
+			Name:    r.bundle.Catalog(code).Name(),
+			Current: code == p.Locale(),
+		})
+	}
+
+	return template.FuncMap{
+		"liveReload":   func() bool { return liveReload },
+		"T":            p.T,
+		"locale":       p.Locale,
+		"languages":    func() []language { return languages },
+		"timeAgo":      func(t time.Time) string { return timeAgo(p, t) },
+		"displayName":  func(c model.Contact) string { return c.DisplayName(nameOrder) },
+		"formatTime":   func(t time.Time, tz string) string { return formatTime(p, t, tz) },
+		"formatDate":   func(t time.Time, tz string) string { return formatDate(p, t, tz) },
+		"formatDay":    func(t time.Time, tz string) string { return formatDay(p, t, tz) },
+		"partialDate":  func(d model.PartialDate) string { return partialDate(p, d) },
+		"pluralize":    pluralize,
+		"fileSize":     fileSize,
+		"initials":     initials,
+		"avatarColor":  avatarColor,
+		"telURL":       telURL,
+		"mailtoURL":    mailtoURL,
+		"emailDomain":  emailDomain,
+		"customFields": func() []model.FieldDef { return fields },
+		"listFields":   func() []model.FieldDef { return listFields },
+		"tableColumns": func() int { return 5 + len(listFields) },
+		"nextSort":     nextSort,
+		"sortDir":      sortDir,
+		"setQuery":     setQuery,
+		"seq":          seq,
+		"highlight":    highlight,
+		"markdown":     markdown.Render,
+	}
+}
+
+// timeAgo describes t relative to the current time, e.g. "3 hours ago"
+// or "in 2 days".
+func timeAgo(p *i18n.Printer, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := now().Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var n int
+	var unit string
+	switch {
+	case d < time.Minute:
+		return p.T("time.just_now")
+	case d < time.Hour:
+		n, unit = int(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		n, unit = int(d/time.Hour), "hour"
+	case d < 30*24*time.Hour:
+		n, unit = int(d/(24*time.Hour)), "day"
+	case d < 360*24*time.Hour:
+		n, unit = int(d/(30*24*time.Hour)), "month"
+	default:
+		// 360 to 364 days would be "12 months"; call it a year.
+		n, unit = max(1, int(d/(365*24*time.Hour))), "year"
+	}
+
+	if future {
+		return p.T("time.in_"+unit+"s", "count", n)
+	}
+	return p.T("time."+unit+"s_ago", "count", n)
+}
+
+// formatTime formats t as a date and time in p's locale and the named
+// IANA timezone, falling back to UTC when tz is empty or unknown.
+func formatTime(p *i18n.Printer, t time.Time, tz string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return p.DateTime(t.In(Location(tz)))
+}
+
+// formatDate formats t as a date in p's locale and the named IANA
+// timezone.
+func formatDate(p *i18n.Printer, t time.Time, tz string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return p.Date(t.In(Location(tz)))
+}
+
+// formatDay formats t as a day and month, without the year, in p's locale
+// and the named IANA timezone.
+func formatDay(p *i18n.Printer, t time.Time, tz string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return p.MonthDay(t.In(Location(tz)))
+}
+
+// partialDate formats d in p's locale as e.g. "Apr 12, 1985", or "Apr 12"
+// when the year is unknown.
+func partialDate(p *i18n.Printer, d model.PartialDate) string {
+	if !d.HasYear() {
+		return p.MonthDay(d.In(2000, time.UTC))
+	}
+	return p.Date(d.In(d.Year, time.UTC))
+}
+
+// fileSize formats a byte count as e.g. "512 B", "1.5 KB" or "25 MB",
+// in multiples of 1024.
+func fileSize(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	v := float64(n) / 1024
+	unit := 0
+	for v >= 1024 && unit < 3 {
+		v /= 1024
+		unit++
+	}
+	s := fmt.Sprintf("%.1f", v)
+	s = strings.TrimSuffix(s, ".0")
+	return s + " " + [...]string{"KB", "MB", "GB", "TB"}[unit]
+}
+
+var locations sync.Map // tz name -> *time.Location
+
+// Location loads the named IANA timezone, falling back to UTC if tz is
+// empty or unknown.
+func Location(tz string) *time.Location {
+	if tz == "" {
+		return time.UTC
+	}
+	if loc, ok := locations.Load(tz); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+	locations.Store(tz, loc)
+	return loc
+}
+
+// pluralize returns n followed by the singular or plural noun.
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// initials returns up to two uppercase initials from a name, taken from
+// its first and last words.
+func initials(name string) string {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return ""
+	}
+	first := []rune(words[0])[0]
+	if len(words) == 1 {
+		return string(unicode.ToUpper(first))
+	}
+	last := []rune(words[len(words)-1])[0]
+	return string(unicode.ToUpper(first)) + string(unicode.ToUpper(last))
+}
+
+// avatarColor derives a stable background color from a name, so the same
+// person always gets the same avatar. The name only feeds the hash, so the
+// returned CSS never contains user input.
+func avatarColor(name string) template.CSS {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.ToLower(strings.TrimSpace(name))))
+	return template.CSS(fmt.Sprintf("hsl(%d, 55%%, 45%%)", h.Sum32()%360))
+}
+
+// telURL builds a tel: link from a phone number, keeping only digits and a
+// leading plus. It returns "" when no digits remain.
+func telURL(phone string) template.URL {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		}
+	}
+	digits := strings.TrimPrefix(b.String(), "+")
+	if digits == "" {
+		return ""
+	}
+	return template.URL("tel:" + b.String())
+}
+
+// mailtoURL builds a mailto: link to the bare address in email, dropping
+// any display name. Anything model.ParseEmail rejects yields "". The
+// characters RFC 6068 reserves in an address ("%", "?" and "#") are
+// percent-escaped so they stay part of it.
+func mailtoURL(email string) template.URL {
+	addr, err := model.ParseEmail(email)
+	if err != nil {
+		return ""
+	}
+	u := url.URL{Scheme: "mailto", Opaque: mailtoEscape(addr)}
+	return template.URL(u.String())
+}
+
+var mailtoEscape = strings.NewReplacer("%", "%25", "?", "%3F", "#", "%23").Replace
+
+// emailDomain returns the domain of email as the user wrote it, or "".
+func emailDomain(email string) string {
+	addr, err := model.ParseEmail(email)
+	if err != nil {
+		return ""
+	}
+	return addr[strings.LastIndexByte(addr, '@')+1:]
+}
+
+// nextSort returns the sort parameter a column header links to: the
+// column's key, or its descending form if the list is already sorted by it
+// ascending.
+func nextSort(current, key string) string {
+	if current == key {
+		return "-" + key
+	}
+	return key
+}
+
+// sortDir returns the aria-sort value for the column key under the current
+// sort parameter, or "" if the list isn't sorted by it.
+func sortDir(current, key string) string {
+	switch current {
+	case key:
+		return "ascending"
+	case "-" + key:
+		return "descending"
+	}
+	return ""
+}
+
+// seq returns the integers 1 through n, for ranging over a fixed number of
+// options.
+func seq(n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i + 1
+	}
+	return s
+}
+
+// setQuery returns "?" plus the encoded query with each key/value pair
+// applied, for sort and pagination links that keep the current filters.
+// An empty value removes the key.
+func setQuery(query url.Values, pairs ...any) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("setQuery: odd number of arguments")
+	}
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = append([]string(nil), v...)
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return "", fmt.Errorf("setQuery: key %v is not a string", pairs[i])
+		}
+		value := fmt.Sprint(pairs[i+1])
+		if value == "" {
+			q.Del(key)
+		} else {
+			q.Set(key, value)
+		}
+	}
+	if len(q) == 0 {
+		return "?", nil
+	}
+	return "?" + q.Encode(), nil
+}
+
+// highlight HTML-escapes text and wraps every case-insensitive occurrence
+// of term in <mark>.
+func highlight(text, term string) template.HTML {
+	needle := []rune(strings.TrimSpace(term))
+	if len(needle) == 0 {
+		return template.HTML(template.HTMLEscapeString(text))
+	}
+
+	runes := []rune(text)
+	var b strings.Builder
+	start := 0
+	for i := 0; i+len(needle) <= len(runes); {
+		if strings.EqualFold(string(runes[i:i+len(needle)]), string(needle)) {
+			b.WriteString(template.HTMLEscapeString(string(runes[start:i])))
+			b.WriteString("<mark>")
+			b.WriteString(template.HTMLEscapeString(string(runes[i : i+len(needle)])))
+			b.WriteString("</mark>")
+			i += len(needle)
+			start = i
+			continue
+		}
+		i++
+	}
+	b.WriteString(template.HTMLEscapeString(string(runes[start:])))
+	return template.HTML(b.String())
+}
@@ -0,0 +1,314 @@
+package tmpl
+
+import (
+	"bytes"
+	"html/template"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devaloi/htmxapp/internal/i18n"
+	"github.com/devaloi/htmxapp/internal/model"
+)
+
+func TestTimeAgo(t *testing.T) {
+	bundle, err := i18n.Default()
+	if err != nil {
+		t.Fatalf("i18n.Default: %v", err)
+	}
+	en, es := bundle.Printer("en"), bundle.Printer("es")
+
+	fixed := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = time.Now })
+
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Time{}, ""},
+		{fixed.Add(-20 * time.Second), "just now"},
+		{fixed.Add(-1 * time.Minute), "1 minute ago"},
+		{fixed.Add(-45 * time.Minute), "45 minutes ago"},
+		{fixed.Add(-3 * time.Hour), "3 hours ago"},
+		{fixed.Add(-24 * time.Hour), "1 day ago"},
+		{fixed.Add(-65 * 24 * time.Hour), "2 months ago"},
+		{fixed.Add(-362 * 24 * time.Hour), "1 year ago"},
+		{fixed.Add(-800 * 24 * time.Hour), "2 years ago"},
+		{fixed.Add(49 * time.Hour), "in 2 days"},
+	}
+	for _, tt := range tests {
+		if got := timeAgo(en, tt.t); got != tt.want {
+			t.Errorf("timeAgo(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+
+	if got := timeAgo(es, fixed.Add(-3*time.Hour)); got != "hace 3 horas" {
+		t.Errorf("expected Spanish relative time, got %q", got)
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	bundle, err := i18n.Default()
+	if err != nil {
+		t.Fatalf("i18n.Default: %v", err)
+	}
+	en, es := bundle.Printer("en"), bundle.Printer("es")
+	ts := time.Date(2026, 1, 2, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		tz       string
+		wantTime string
+		wantDate string
+	}{
+		{"", "Jan 2, 2026 11:30 PM UTC", "Jan 2, 2026"},
+		{"Not/AZone", "Jan 2, 2026 11:30 PM UTC", "Jan 2, 2026"},
+		{"Asia/Tokyo", "Jan 3, 2026 8:30 AM JST", "Jan 3, 2026"},
+		{"America/New_York", "Jan 2, 2026 6:30 PM EST", "Jan 2, 2026"},
+	}
+	for _, tt := range tests {
+		if got := formatTime(en, ts, tt.tz); got != tt.wantTime {
+			t.Errorf("formatTime(%q) = %q, want %q", tt.tz, got, tt.wantTime)
+		}
+		if got := formatDate(en, ts, tt.tz); got != tt.wantDate {
+			t.Errorf("formatDate(%q) = %q, want %q", tt.tz, got, tt.wantDate)
+		}
+	}
+	if got := formatTime(en, time.Time{}, "UTC"); got != "" {
+		t.Errorf("expected empty string for zero time, got %q", got)
+	}
+	if got := formatTime(es, ts, "Asia/Tokyo"); got != "3 ene 2026, 8:30 JST" {
+		t.Errorf("expected a Spanish date and 24-hour time, got %q", got)
+	}
+	if got := formatDate(es, ts, ""); got != "2 ene 2026" {
+		t.Errorf("expected a Spanish date, got %q", got)
+	}
+	if got := formatDay(en, ts, "Asia/Tokyo"); got != "Jan 3" {
+		t.Errorf("expected the day in Tokyo, got %q", got)
+	}
+	if got := formatDay(es, ts, ""); got != "2 ene" {
+		t.Errorf("expected a Spanish day, got %q", got)
+	}
+}
+
+func TestLocation_CachesOnlyValidZones(t *testing.T) {
+	if loc := Location("Not/AZone"); loc != time.UTC {
+		t.Errorf("expected UTC for an unknown zone, got %v", loc)
+	}
+	if _, ok := locations.Load("Not/AZone"); ok {
+		t.Error("an unknown zone was cached")
+	}
+	if loc := Location("Asia/Tokyo"); loc.String() != "Asia/Tokyo" {
+		t.Errorf("expected Asia/Tokyo, got %v", loc)
+	}
+	if _, ok := locations.Load("Asia/Tokyo"); !ok {
+		t.Error("a known zone wasn't cached")
+	}
+}
+
+func TestPartialDate(t *testing.T) {
+	bundle, err := i18n.Default()
+	if err != nil {
+		t.Fatalf("i18n.Default: %v", err)
+	}
+	en, es := bundle.Printer("en"), bundle.Printer("es")
+	tests := []struct {
+		d    model.PartialDate
+		want string
+	}{
+		{model.PartialDate{Year: 1985, Month: time.April, Day: 12}, "Apr 12, 1985"},
+		{model.PartialDate{Month: time.April, Day: 12}, "Apr 12"},
+		{model.PartialDate{Month: time.February, Day: 29}, "Feb 29"},
+	}
+	for _, tt := range tests {
+		if got := partialDate(en, tt.d); got != tt.want {
+			t.Errorf("partialDate(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+	if got := partialDate(es, model.PartialDate{Month: time.April, Day: 12}); got != "12 abr" {
+		t.Errorf("expected a Spanish day and month, got %q", got)
+	}
+}
+
+func TestFileSize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KB"},
+		{25 << 20, "25 MB"},
+		{3 << 30, "3 GB"},
+	}
+	for _, tt := range tests {
+		if got := fileSize(tt.n); got != tt.want {
+			t.Errorf("fileSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0 contacts"},
+		{1, "1 contact"},
+		{2, "2 contacts"},
+	}
+	for _, tt := range tests {
+		if got := pluralize(tt.n, "contact", "contacts"); got != tt.want {
+			t.Errorf("pluralize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestInitials(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Alice Johnson", "AJ"},
+		{"alice", "A"},
+		{"  Mary Ann   van der Berg ", "MB"},
+		{"Élodie Ångström", "ÉÅ"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := initials(tt.name); got != tt.want {
+			t.Errorf("initials(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAvatarColor(t *testing.T) {
+	a := avatarColor("Alice Johnson")
+	if a != avatarColor(" alice johnson ") {
+		t.Error("expected color to ignore case and surrounding space")
+	}
+	if !strings.HasPrefix(string(a), "hsl(") {
+		t.Errorf("unexpected color %q", a)
+	}
+}
+
+func TestTelURL(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  template.URL
+	}{
+		{"555-0101", "tel:5550101"},
+		{"+1 (555) 010-1234", "tel:+15550101234"},
+		{"1+2", "tel:12"},
+		{"javascript:alert(1)", "tel:1"},
+		{"n/a", ""},
+		{"+", ""},
+	}
+	for _, tt := range tests {
+		if got := telURL(tt.phone); got != tt.want {
+			t.Errorf("telURL(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestMailtoURL(t *testing.T) {
+	tests := []struct {
+		email string
+		want  template.URL
+	}{
+		{"alice@example.com", "mailto:alice@example.com"},
+		{"Alice <alice@example.com>", "mailto:alice@example.com"},
+		{"what?#100%@example.com", "mailto:what%3F%23100%25@example.com"},
+		{"not-an-email", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := mailtoURL(tt.email); got != tt.want {
+			t.Errorf("mailtoURL(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestSetQuery(t *testing.T) {
+	q := url.Values{"q": {"ann"}, "page": {"3"}}
+
+	got, err := setQuery(q, "sort", "name", "page", 1)
+	if err != nil {
+		t.Fatalf("setQuery: %v", err)
+	}
+	if got != "?page=1&q=ann&sort=name" {
+		t.Errorf("unexpected query %q", got)
+	}
+	if q.Get("page") != "3" {
+		t.Error("setQuery must not modify its input")
+	}
+
+	if got, _ := setQuery(q, "q", "", "page", ""); got != "?" {
+		t.Errorf("expected empty query, got %q", got)
+	}
+	if _, err := setQuery(q, "sort"); err == nil {
+		t.Error("expected error for odd arguments")
+	}
+	if _, err := setQuery(q, 1, "x"); err == nil {
+		t.Error("expected error for non-string key")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text, term string
+		want       template.HTML
+	}{
+		{"Alice Johnson", "", "Alice Johnson"},
+		{"Alice Johnson", "ali", "<mark>Ali</mark>ce Johnson"},
+		{"Anna Hanna", "ANN", "<mark>Ann</mark>a H<mark>ann</mark>a"},
+		{"Ångström", "ång", "<mark>Ång</mark>ström"},
+		{"<b>Bob</b>", "bob", "&lt;b&gt;<mark>Bob</mark>&lt;/b&gt;"},
+		{"Tom & Jerry", "&", "Tom <mark>&amp;</mark> Jerry"},
+	}
+	for _, tt := range tests {
+		if got := highlight(tt.text, tt.term); got != tt.want {
+			t.Errorf("highlight(%q, %q) = %q, want %q", tt.text, tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayNameOrder(t *testing.T) {
+	r, err := NewWithOptions(Options{NameOrder: model.NameOrderFamilyFirst})
+	if err != nil {
+		t.Fatalf("NewWithOptions: %v", err)
+	}
+
+	c := model.Contact{ID: "1", FirstName: "Taro", LastName: "Yamada", Nickname: "Tar-chan"}
+	var buf bytes.Buffer
+	if err := r.RenderComponent(&buf, "contact-row", c); err != nil {
+		t.Fatalf("RenderComponent: %v", err)
+	}
+	if body := buf.String(); !strings.Contains(body, "Yamada Taro") || !strings.Contains(body, "Tar-chan") {
+		t.Errorf("expected family-first name and nickname in row:\n%s", body)
+	}
+}
+
+func TestFuncsInTemplates(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	data := model.Contact{ID: "1", FirstName: "Alice", LastName: "Smith", Email: "alice@test.com", Phone: "555-0001"}
+
+	var buf bytes.Buffer
+	if err := r.RenderComponent(&buf, "contact-row", data); err != nil {
+		t.Fatalf("RenderComponent: %v", err)
+	}
+	body := buf.String()
+	for _, want := range []string{`href="mailto:alice@test.com"`, `href="tel:5550001"`, ">AS<", "fill: hsl("} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in row:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "ZgotmplZ") {
+		t.Errorf("helper output was rejected by html/template:\n%s", body)
+	}
+}
@@ -1,94 +1,426 @@
 package tmpl
 
 import (
+	"bytes"
 	"embed"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"io/fs"
+	"net/http"
+	"strconv"
+	"sync"
+	"text/template/parse"
+
+	"github.com/devaloi/htmxapp/internal/i18n"
+	"github.com/devaloi/htmxapp/internal/model"
 )
 
 //go:embed templates
 var templateFS embed.FS
 
-// Renderer loads and renders HTML templates.
+// Renderer loads and renders HTML templates. Templates are parsed once per
+// supported locale, with the translation helpers bound to that locale, so
+// components and partials translate without needing the locale in their
+// data. Use In to pick the locale a response renders in.
 type Renderer struct {
-	pages    map[string]*template.Template
-	partials map[string]*template.Template
+	fsys       fs.FS
+	liveReload bool
+	nameOrder  model.NameOrder
+	fields     []model.FieldDef
+	bundle     *i18n.Bundle
+	locale     string
+	state      *state
+}
+
+// state is shared by a Renderer and every locale view derived from it, so
+// Reload is visible to all of them.
+type state struct {
+	mu   sync.RWMutex
+	sets map[string]*templateSet // by locale
+}
+
+type templateSet struct {
+	pages      map[string]*template.Template
+	partials   map[string]*template.Template
+	components *template.Template
+}
+
+// Options configures a Renderer.
+type Options struct {
+	// FS holds layout.html and the components, pages and partials
+	// directories. It defaults to the templates embedded in the binary;
+	// development mode points it at the source tree so Reload picks up edits.
+	FS fs.FS
+
+	// LiveReload makes the layout subscribe to the development reload
+	// event stream so open browsers refresh after a change.
+	LiveReload bool
+
+	// Bundle holds the message catalogs. It defaults to the catalogs
+	// embedded in the i18n package.
+	Bundle *i18n.Bundle
+
+	// NameOrder arranges name parts for the displayName helper.
+	NameOrder model.NameOrder
+
+	// Fields are the custom field definitions the customFields and
+	// listFields helpers return.
+	Fields []model.FieldDef
 }
 
 // New parses all templates from the embedded filesystem.
 func New() (*Renderer, error) {
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions parses all templates according to opts. Components are
+// parsed first and shared by every page and partial; any {{template}}
+// call that names an undefined template fails here rather than mid-render.
+func NewWithOptions(opts Options) (*Renderer, error) {
+	fsys := opts.FS
+	if fsys == nil {
+		sub, err := fs.Sub(templateFS, "templates")
+		if err != nil {
+			return nil, fmt.Errorf("opening embedded templates: %w", err)
+		}
+		fsys = sub
+	}
+	bundle := opts.Bundle
+	if bundle == nil {
+		var err error
+		if bundle, err = i18n.Default(); err != nil {
+			return nil, fmt.Errorf("loading message catalogs: %w", err)
+		}
+	}
+
 	r := &Renderer{
+		fsys:       fsys,
+		liveReload: opts.LiveReload,
+		nameOrder:  opts.NameOrder,
+		fields:     opts.Fields,
+		bundle:     bundle,
+		locale:     i18n.DefaultLocale,
+		state:      &state{},
+	}
+	sets, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.state.sets = sets
+	return r, nil
+}
+
+// In returns a view of the renderer that renders in locale. Unsupported
+// locales render in the default locale.
+func (r *Renderer) In(locale string) *Renderer {
+	if r.bundle.Catalog(locale) == nil {
+		locale = i18n.DefaultLocale
+	}
+	view := *r
+	view.locale = locale
+	return &view
+}
+
+// Locale returns the locale the renderer renders in.
+func (r *Renderer) Locale() string {
+	return r.locale
+}
+
+// Bundle returns the message catalogs, for locale negotiation.
+func (r *Renderer) Bundle() *i18n.Bundle {
+	return r.bundle
+}
+
+// Reload re-parses every template from the renderer's filesystem. If
+// parsing fails the previously loaded templates stay in use.
+func (r *Renderer) Reload() error {
+	sets, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	r.state.mu.Lock()
+	r.state.sets = sets
+	r.state.mu.Unlock()
+	return nil
+}
+
+func (r *Renderer) set() *templateSet {
+	r.state.mu.RLock()
+	defer r.state.mu.RUnlock()
+	return r.state.sets[r.locale]
+}
+
+// load parses a template set for every supported locale.
+func (r *Renderer) load() (map[string]*templateSet, error) {
+	sets := make(map[string]*templateSet)
+	for _, locale := range r.bundle.Locales() {
+		set, err := r.loadSet(r.funcs(r.bundle.Printer(locale)))
+		if err != nil {
+			if locale == i18n.DefaultLocale {
+				return nil, err
+			}
+			return nil, fmt.Errorf("locale %s: %w", locale, err)
+		}
+		sets[locale] = set
+	}
+	return sets, nil
+}
+
+func (r *Renderer) loadSet(funcs template.FuncMap) (*templateSet, error) {
+	set := &templateSet{
 		pages:    make(map[string]*template.Template),
 		partials: make(map[string]*template.Template),
 	}
 
-	layoutContent, err := fs.ReadFile(templateFS, "templates/layout.html")
+	components, err := r.parseComponents(funcs)
+	if err != nil {
+		return nil, err
+	}
+	set.components = components
+
+	layoutContent, err := fs.ReadFile(r.fsys, "layout.html")
 	if err != nil {
 		return nil, fmt.Errorf("reading layout: %w", err)
 	}
 
 	// Parse page templates (each extends layout)
-	pageFiles, err := fs.Glob(templateFS, "templates/pages/*.html")
+	pageFiles, err := fs.Glob(r.fsys, "pages/*.html")
 	if err != nil {
 		return nil, fmt.Errorf("globbing pages: %w", err)
 	}
 	for _, path := range pageFiles {
 		name := extractName(path)
-		t, parseErr := template.New("layout").Parse(string(layoutContent))
-		if parseErr != nil {
+		t, cloneErr := components.Clone()
+		if cloneErr != nil {
+			return nil, fmt.Errorf("cloning components for %s: %w", name, cloneErr)
+		}
+		if _, parseErr := t.New("layout").Parse(string(layoutContent)); parseErr != nil {
 			return nil, fmt.Errorf("parsing layout for %s: %w", name, parseErr)
 		}
-		pageContent, readErr := fs.ReadFile(templateFS, path)
+		pageContent, readErr := fs.ReadFile(r.fsys, path)
 		if readErr != nil {
 			return nil, fmt.Errorf("reading %s: %w", path, readErr)
 		}
-		if _, parseErr = t.Parse(string(pageContent)); parseErr != nil {
+		if _, parseErr := t.New(name).Parse(string(pageContent)); parseErr != nil {
 			return nil, fmt.Errorf("parsing %s: %w", name, parseErr)
 		}
-		r.pages[name] = t
+		if refErr := checkReferences(t); refErr != nil {
+			return nil, fmt.Errorf("page %s: %w", name, refErr)
+		}
+		set.pages[name] = t
 	}
 
-	// Parse partial templates (standalone, no layout)
-	partialFiles, err := fs.Glob(templateFS, "templates/partials/*.html")
+	// Parse partial templates (no layout, but with components)
+	partialFiles, err := fs.Glob(r.fsys, "partials/*.html")
 	if err != nil {
 		return nil, fmt.Errorf("globbing partials: %w", err)
 	}
 	for _, path := range partialFiles {
 		name := extractName(path)
-		content, readErr := fs.ReadFile(templateFS, path)
+		if components.Lookup(name) != nil {
+			return nil, fmt.Errorf("partial %s: name is already used by a component", name)
+		}
+		content, readErr := fs.ReadFile(r.fsys, path)
 		if readErr != nil {
 			return nil, fmt.Errorf("reading %s: %w", path, readErr)
 		}
-		t, parseErr := template.New(name).Parse(string(content))
-		if parseErr != nil {
+		t, cloneErr := components.Clone()
+		if cloneErr != nil {
+			return nil, fmt.Errorf("cloning components for %s: %w", name, cloneErr)
+		}
+		if _, parseErr := t.New(name).Parse(string(content)); parseErr != nil {
 			return nil, fmt.Errorf("parsing %s: %w", name, parseErr)
 		}
-		r.partials[name] = t
+		if refErr := checkReferences(t); refErr != nil {
+			return nil, fmt.Errorf("partial %s: %w", name, refErr)
+		}
+		set.partials[name] = t.Lookup(name)
 	}
 
-	return r, nil
+	return set, nil
+}
+
+// parseComponents parses every file in components/ into a single template
+// set. Each file must define a template named after itself, which is how
+// pages, partials and RenderComponent refer to it.
+func (r *Renderer) parseComponents(funcs template.FuncMap) (*template.Template, error) {
+	set := template.New("components").Funcs(funcs)
+
+	files, err := fs.Glob(r.fsys, "components/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("globbing components: %w", err)
+	}
+	for _, path := range files {
+		name := extractName(path)
+		content, readErr := fs.ReadFile(r.fsys, path)
+		if readErr != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, readErr)
+		}
+		if _, parseErr := set.New(path).Parse(string(content)); parseErr != nil {
+			return nil, fmt.Errorf("parsing component %s: %w", name, parseErr)
+		}
+		if set.Lookup(name) == nil {
+			return nil, fmt.Errorf("component %s: %s does not define %q", name, path, name)
+		}
+	}
+	if err := checkReferences(set); err != nil {
+		return nil, fmt.Errorf("components: %w", err)
+	}
+	return set, nil
+}
+
+// checkReferences walks every template in set and reports the first
+// {{template "name"}} call whose target is not defined in the set.
+func checkReferences(set *template.Template) error {
+	for _, t := range set.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		var missing string
+		walkTemplateCalls(t.Tree.Root, func(name string) {
+			if missing == "" {
+				if ref := set.Lookup(name); ref == nil || ref.Tree == nil {
+					missing = name
+				}
+			}
+		})
+		if missing != "" {
+			return fmt.Errorf("template %q references undefined template %q", t.Name(), missing)
+		}
+	}
+	return nil
+}
+
+func walkTemplateCalls(node parse.Node, visit func(name string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkTemplateCalls(child, visit)
+		}
+	case *parse.TemplateNode:
+		visit(n.Name)
+	case *parse.IfNode:
+		walkTemplateCalls(n.List, visit)
+		walkTemplateCalls(n.ElseList, visit)
+	case *parse.RangeNode:
+		walkTemplateCalls(n.List, visit)
+		walkTemplateCalls(n.ElseList, visit)
+	case *parse.WithNode:
+		walkTemplateCalls(n.List, visit)
+		walkTemplateCalls(n.ElseList, visit)
+	}
 }
 
 // RenderPage renders a full page template with the layout.
 func (r *Renderer) RenderPage(w io.Writer, name string, data any) error {
-	t, ok := r.pages[name]
+	t, ok := r.set().pages[name]
 	if !ok {
 		return fmt.Errorf("page template %q not found", name)
 	}
 	return t.ExecuteTemplate(w, "layout", data)
 }
 
+// RenderContent renders only a page's content block, preceded by its
+// <title> so htmx can update the document title when swapping it in.
+func (r *Renderer) RenderContent(w io.Writer, name string, data any) error {
+	t, ok := r.set().pages[name]
+	if !ok {
+		return fmt.Errorf("page template %q not found", name)
+	}
+	return t.ExecuteTemplate(w, "fragment", data)
+}
+
 // RenderPartial renders a partial template without layout.
 func (r *Renderer) RenderPartial(w io.Writer, name string, data any) error {
-	t, ok := r.partials[name]
+	t, ok := r.set().partials[name]
 	if !ok {
 		return fmt.Errorf("partial template %q not found", name)
 	}
 	return t.Execute(w, data)
 }
 
+// RenderComponent renders a single component by name, for htmx responses
+// that swap in one piece of a page.
+func (r *Renderer) RenderComponent(w io.Writer, name string, data any) error {
+	components := r.set().components
+	if components.Lookup(name) == nil {
+		return fmt.Errorf("component template %q not found", name)
+	}
+	return components.ExecuteTemplate(w, name, data)
+}
+
+// WritePage renders a full page and sends it with the given status.
+func (r *Renderer) WritePage(w http.ResponseWriter, status int, name string, data any) error {
+	return write(w, status, func(buf io.Writer) error {
+		return r.RenderPage(buf, name, data)
+	})
+}
+
+// WriteContent renders a page's content block and sends it with the given
+// status.
+func (r *Renderer) WriteContent(w http.ResponseWriter, status int, name string, data any) error {
+	return write(w, status, func(buf io.Writer) error {
+		return r.RenderContent(buf, name, data)
+	})
+}
+
+// WritePartial renders a partial and sends it with the given status.
+func (r *Renderer) WritePartial(w http.ResponseWriter, status int, name string, data any) error {
+	return write(w, status, func(buf io.Writer) error {
+		return r.RenderPartial(buf, name, data)
+	})
+}
+
+// WriteComponent renders a component and sends it with the given status.
+func (r *Renderer) WriteComponent(w http.ResponseWriter, status int, name string, data any) error {
+	return write(w, status, func(buf io.Writer) error {
+		return r.RenderComponent(buf, name, data)
+	})
+}
+
+// ErrCommitted reports a failure to send a response after its status and
+// headers were already written, when it is too late to send an error page.
+var ErrCommitted = errors.New("response already committed")
+
+// maxPooledBuffer keeps one unusually large page from pinning its buffer
+// in the pool for the life of the process.
+const maxPooledBuffer = 64 << 10
+
+var bufPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// write renders into a pooled buffer and only commits headers, status and
+// body once rendering has succeeded. On error nothing has been written to
+// w, so the caller can still send an error response.
+func write(w http.ResponseWriter, status int, render func(io.Writer) error) error {
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			bufPool.Put(buf)
+		}
+	}()
+
+	if err := render(buf); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(status)
+	if _, err := buf.WriteTo(w); err != nil {
+		return fmt.Errorf("%w: %w", ErrCommitted, err)
+	}
+	return nil
+}
+
 func extractName(path string) string {
 	// "templates/pages/home.html" -> "home"
 	base := ""
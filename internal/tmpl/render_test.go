@@ -2,8 +2,17 @@ package tmpl
 
 import (
 	"bytes"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
 	"strings"
 	"testing"
+	"testing/fstest"
+
+	"github.com/devaloi/htmxapp/internal/i18n"
+	"github.com/devaloi/htmxapp/internal/model"
 )
 
 func TestNew(t *testing.T) {
@@ -12,16 +21,16 @@ func TestNew(t *testing.T) {
 		t.Fatalf("New: %v", err)
 	}
 
-	expectedPages := []string{"home", "contacts", "contact-form"}
+	expectedPages := []string{"home", "contacts", "contact", "contact-form", "companies", "company", "company-form"}
 	for _, name := range expectedPages {
-		if _, ok := r.pages[name]; !ok {
+		if _, ok := r.set().pages[name]; !ok {
 			t.Errorf("missing page template: %s", name)
 		}
 	}
 
 	expectedPartials := []string{"contact-rows"}
 	for _, name := range expectedPartials {
-		if _, ok := r.partials[name]; !ok {
+		if _, ok := r.set().partials[name]; !ok {
 			t.Errorf("missing partial template: %s", name)
 		}
 	}
@@ -61,21 +70,36 @@ func TestRenderPage_NotFound(t *testing.T) {
 	}
 }
 
-func TestRenderPartial(t *testing.T) {
+func TestRenderContent(t *testing.T) {
 	r, err := New()
 	if err != nil {
 		t.Fatalf("New: %v", err)
 	}
 
-	type contact struct {
-		ID        string
-		FirstName string
-		LastName  string
-		Email     string
-		Phone     string
+	var buf bytes.Buffer
+	if err := r.RenderContent(&buf, "home", nil); err != nil {
+		t.Fatalf("RenderContent: %v", err)
+	}
+
+	body := buf.String()
+	if strings.Contains(body, "<!DOCTYPE html>") {
+		t.Error("expected no layout in content fragment")
+	}
+	if !strings.Contains(body, "<title>htmxapp — Home</title>") {
+		t.Error("expected page title in content fragment")
+	}
+	if !strings.Contains(body, `class="hero"`) {
+		t.Error("expected page content in fragment")
+	}
+}
+
+func TestRenderPartial(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
 	}
 
-	data := []contact{
+	data := []model.Contact{
 		{ID: "1", FirstName: "Alice", LastName: "Smith", Email: "alice@test.com", Phone: "555-0001"},
 	}
 
@@ -91,6 +115,239 @@ func TestRenderPartial(t *testing.T) {
 	}
 }
 
+func TestRenderComponent(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	data := model.Contact{ID: "7", FirstName: "Grace", LastName: "Hopper", Email: "grace@test.com", Phone: "555-0007"}
+
+	var buf bytes.Buffer
+	if err := r.RenderComponent(&buf, "contact-row", data); err != nil {
+		t.Fatalf("RenderComponent: %v", err)
+	}
+	if body := buf.String(); !strings.Contains(body, `id="contact-7"`) || !strings.Contains(body, "Grace") {
+		t.Errorf("unexpected component output: %s", body)
+	}
+
+	if err := r.RenderComponent(&buf, "nonexistent", nil); err == nil {
+		t.Error("expected error for missing component")
+	}
+}
+
+func TestCustomFields(t *testing.T) {
+	fields := []model.FieldDef{
+		{Key: "region", Label: "Region", Labels: map[string]string{"es": "Región"}, Type: model.FieldSelect, Options: []string{"EMEA", "APAC"}, List: true},
+		{Key: "site", Label: "Website", Type: model.FieldURL},
+		{Key: "vip", Label: "VIP", Type: model.FieldBoolean},
+	}
+	r, err := NewWithOptions(Options{Fields: fields})
+	if err != nil {
+		t.Fatalf("NewWithOptions: %v", err)
+	}
+	c := model.Contact{
+		ID: "7", FirstName: "Grace", Email: "grace@test.com",
+		Custom: map[string]string{"region": "APAC", "site": "https://grace.test", "vip": "true"},
+	}
+
+	var buf bytes.Buffer
+	if err := r.RenderComponent(&buf, "contact-row", c); err != nil {
+		t.Fatalf("RenderComponent: %v", err)
+	}
+	if body := buf.String(); !strings.Contains(body, "<td>APAC</td>") || strings.Contains(body, "grace.test") {
+		t.Errorf("expected only list fields as columns:\n%s", body)
+	}
+
+	buf.Reset()
+	data := struct {
+		Contact   model.Contact
+		Errors    map[string]string
+		TZ        string
+		Companies []model.Company
+		Suggested bool
+		Photos    bool
+		Draft     model.Draft
+		Restored  bool
+	}{c, map[string]string{"custom.site": model.CodeInvalidURL}, "", nil, false, false, model.Draft{}, false}
+	if err := r.In("es").RenderPage(&buf, "contact-form", data); err != nil {
+		t.Fatalf("RenderPage: %v", err)
+	}
+	body := buf.String()
+	for _, want := range []string{
+		`<option value="APAC" selected>`,
+		`name="custom.site" value="https://grace.test"`,
+		`name="custom.vip" value="true" checked`,
+		"Región",
+		"Website debe ser una URL http o https",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in form:\n%s", want, body)
+		}
+	}
+}
+
+func TestComponentsSharedWithPages(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for name, set := range r.set().pages {
+		if set.Lookup("contact-row") == nil {
+			t.Errorf("page %s cannot see contact-row component", name)
+		}
+	}
+	for name, set := range r.set().partials {
+		if set.Lookup("contact-row") == nil {
+			t.Errorf("partial %s cannot see contact-row component", name)
+		}
+	}
+}
+
+func TestCheckReferences(t *testing.T) {
+	ok := template.Must(template.New("a").Parse(`{{define "b"}}b{{end}}{{if .}}{{template "b"}}{{end}}`))
+	if err := checkReferences(ok); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	missing := template.Must(template.New("a").Parse(`{{range .}}{{else}}{{template "nope" .}}{{end}}`))
+	err := checkReferences(missing)
+	if err == nil || !strings.Contains(err.Error(), `"nope"`) {
+		t.Errorf("expected missing reference error, got %v", err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layout.html":              {Data: []byte(`<html>{{if liveReload}}<script src="reload"></script>{{end}}{{template "content" .}}</html>{{define "fragment"}}{{template "content" .}}{{end}}`)},
+		"components/greeting.html": {Data: []byte(`{{define "greeting"}}hello{{end}}`)},
+		"pages/home.html":          {Data: []byte(`{{define "content"}}{{template "greeting"}} v1{{end}}`)},
+	}
+	r, err := NewWithOptions(Options{FS: fsys, LiveReload: true})
+	if err != nil {
+		t.Fatalf("NewWithOptions: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := r.RenderPage(&buf, "home", nil); err != nil {
+		t.Fatalf("RenderPage: %v", err)
+	}
+	if got := buf.String(); got != `<html><script src="reload"></script>hello v1</html>` {
+		t.Errorf("unexpected output %q", got)
+	}
+
+	fsys["pages/home.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}{{template "greeting"}} v2{{end}}`)}
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	buf.Reset()
+	if err := r.RenderContent(&buf, "home", nil); err != nil {
+		t.Fatalf("RenderContent: %v", err)
+	}
+	if got := buf.String(); got != "hello v2" {
+		t.Errorf("expected reloaded content, got %q", got)
+	}
+
+	// A broken edit keeps the last good templates in place.
+	fsys["pages/home.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}{{template "missing"}}{{end}}`)}
+	if err := r.Reload(); err == nil {
+		t.Fatal("expected reload error for missing template reference")
+	}
+	buf.Reset()
+	if err := r.RenderContent(&buf, "home", nil); err != nil || buf.String() != "hello v2" {
+		t.Errorf("expected previous templates after failed reload, got %q, %v", buf.String(), err)
+	}
+}
+
+func TestTemplateMessagesExist(t *testing.T) {
+	bundle, err := i18n.Default()
+	if err != nil {
+		t.Fatalf("i18n.Default: %v", err)
+	}
+	en := bundle.Printer(i18n.DefaultLocale)
+
+	key := regexp.MustCompile(`\bT "([^"]+)"`)
+	err = fs.WalkDir(templateFS, "templates", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(templateFS, path)
+		if err != nil {
+			return err
+		}
+		for _, m := range key.FindAllStringSubmatch(string(content), -1) {
+			if en.T(m[1]) == m[1] {
+				t.Errorf("%s uses undefined message %q", path, m[1])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIn(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := r.In("es").RenderPage(&buf, "home", nil); err != nil {
+		t.Fatalf("RenderPage: %v", err)
+	}
+	body := buf.String()
+	if !strings.Contains(body, `<html lang="es">`) || !strings.Contains(body, "Ver contactos") {
+		t.Errorf("expected Spanish page, got:\n%s", body)
+	}
+
+	if got := r.In("xx").Locale(); got != i18n.DefaultLocale {
+		t.Errorf("expected unsupported locale to fall back to %s, got %s", i18n.DefaultLocale, got)
+	}
+}
+
+func TestWritePage(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	if err := r.WritePage(rec, http.StatusUnprocessableEntity, "home", nil); err != nil {
+		t.Fatalf("WritePage: %v", err)
+	}
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("unexpected Content-Type %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "htmxapp") {
+		t.Error("expected page body")
+	}
+}
+
+func TestWritePartial_ErrorWritesNothing(t *testing.T) {
+	r, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// A non-slice value makes {{range .}} fail part way through execution.
+	rec := httptest.NewRecorder()
+	if err := r.WritePartial(rec, http.StatusOK, "contact-rows", 42); err == nil {
+		t.Fatal("expected render error")
+	}
+	if rec.Flushed || rec.Body.Len() != 0 {
+		t.Errorf("expected nothing written, got %q", rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "" {
+		t.Errorf("expected no Content-Type on failure, got %q", ct)
+	}
+}
+
 func TestExtractName(t *testing.T) {
 	tests := []struct {
 		path string